@@ -0,0 +1,203 @@
+// Package agent reads PAL agent files (.claude/agents/*.md): the YAML
+// header, the eight numbered sections and the capabilities declared in
+// Section 5 (requirements 1.1.4 and 2.0.3 to 2.0.6).
+//
+// Section 5 lists each capability with name, location and use_when, either
+// as YAML-style fields, inside a code fence or not,
+//
+//	## 5. My Capabilities
+//
+//	- name: note-taking
+//	  location: .claude/skills/note-taking/SKILL.md
+//	  use_when: User wants to capture or process a note
+//
+// or as a table with Name, Location and Use When columns. Both forms are
+// read.
+package agent
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+// Agent is one parsed agent file.
+type Agent struct {
+	vault.Note
+	Sections     []Section
+	Capabilities []Capability
+}
+
+// Section is one level-two heading of the agent body.
+type Section struct {
+	// Number is the heading's leading number ("## 5. My Capabilities"),
+	// or 0 when it has none.
+	Number int
+	Title  string
+	// Line is the 1-based file line of the heading.
+	Line int
+}
+
+// Capability is one entry of Section 5.
+type Capability struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	UseWhen  string `json:"use_when"`
+	// Line is the 1-based file line where the entry starts.
+	Line int `json:"line"`
+}
+
+// CapabilitiesSection is the number of the section that lists capabilities.
+const CapabilitiesSection = 5
+
+// Name returns the agent's name: the frontmatter name, or the file stem.
+func (a *Agent) Name() string {
+	if n := a.Field("name"); n != "" {
+		return n
+	}
+	return a.Stem()
+}
+
+// Section returns the section with the given number, if present.
+func (a *Agent) Section(n int) (Section, bool) {
+	for _, s := range a.Sections {
+		if s.Number == n {
+			return s, true
+		}
+	}
+	return Section{}, false
+}
+
+var numbered = regexp.MustCompile(`^(\d+)[.):]?\s+(.*)$`)
+
+// Parse reads the agent file at the vault-relative path rel. As with
+// vault.ParseNote, a frontmatter error comes back with a usable Agent.
+func Parse(rel string, data []byte) (*Agent, error) {
+	n, err := vault.ParseNote(rel, data)
+	if n == nil {
+		return nil, err
+	}
+	a := &Agent{Note: *n}
+	lines := markdown.Lines(n.Body)
+	offset := n.BodyLine - 1
+	heads := markdown.Headings(n.Body)
+	for _, h := range heads {
+		if h.Level != 2 {
+			continue
+		}
+		s := Section{Title: h.Text, Line: h.Line + offset}
+		if m := numbered.FindStringSubmatch(h.Text); m != nil {
+			s.Number, _ = strconv.Atoi(m[1])
+			s.Title = m[2]
+		}
+		a.Sections = append(a.Sections, s)
+	}
+
+	start, end := -1, len(lines)
+	for i, s := range a.Sections {
+		if s.Number == CapabilitiesSection || (s.Number == 0 && strings.Contains(strings.ToLower(s.Title), "capabilities")) {
+			start = s.Line - offset // first line after the heading, 0-based
+			if i+1 < len(a.Sections) {
+				end = a.Sections[i+1].Line - offset - 1
+			}
+			break
+		}
+	}
+	if start >= 0 {
+		a.Capabilities = capabilities(lines[start:end], start+offset)
+	}
+	return a, err
+}
+
+var field = regexp.MustCompile(`^\s*(?:[-*]\s+)?(?:\*\*)?(name|location|use_when|use when)(?:\*\*)?\s*:\s*(?:\*\*)?\s*(.*)$`)
+
+// capabilities reads the field lists and tables of Section 5. offset is
+// the number of file lines before lines[0].
+func capabilities(lines []string, offset int) []Capability {
+	var out []Capability
+	var cur *Capability
+	for i, line := range lines {
+		m := field.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		v := clean(m[2])
+		switch strings.ToLower(m[1]) {
+		case "name":
+			out = append(out, Capability{Name: v, Line: i + 1 + offset})
+			cur = &out[len(out)-1]
+		case "location":
+			if cur != nil {
+				cur.Location = v
+			}
+		default:
+			if cur != nil {
+				cur.UseWhen = v
+			}
+		}
+	}
+	for _, t := range markdown.Tables(lines, offset) {
+		name := -1
+		for _, col := range []string{"Name", "Capability", "Skill", "Workflow"} {
+			if name = t.Column(col); name >= 0 {
+				break
+			}
+		}
+		if name < 0 {
+			continue
+		}
+		for _, r := range t.Rows {
+			if name >= len(r.Cells) || clean(r.Cells[name]) == "" {
+				continue
+			}
+			c := Capability{Name: clean(r.Cells[name]), Location: clean(t.Get(r, "Location")), Line: r.Line + 1}
+			if c.UseWhen = clean(t.Get(r, "Use When")); c.UseWhen == "" {
+				c.UseWhen = clean(t.Get(r, "use_when"))
+			}
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+var mdLink = regexp.MustCompile(`^\[[^\]]*\]\(([^)\s]+)[^)]*\)$`)
+
+// clean strips quotes, backticks and link syntax from a field value, so
+// "[SKILL.md](.claude/skills/x/SKILL.md)" becomes the path.
+func clean(v string) string {
+	v = strings.TrimSpace(v)
+	v = strings.Trim(v, "`\"'*")
+	if m := mdLink.FindStringSubmatch(v); m != nil {
+		v = m[1]
+	}
+	return strings.TrimSpace(v)
+}
+
+// Key folds a capability or item name for comparison, so "Note Taking",
+// "note_taking" and "note-taking.md" match.
+func Key(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "/")
+	s = strings.TrimSuffix(s, ".md")
+	return strings.Trim(separators.ReplaceAllString(s, "-"), "-")
+}
+
+var separators = regexp.MustCompile(`[-_\s]+`)
+
+// Target returns the name of the skill, workflow or file a capability's
+// location points to: the skill folder for ".../skills/x/SKILL.md", the
+// file stem otherwise.
+func (c Capability) Target() string {
+	loc := strings.TrimSuffix(c.Location, "/")
+	if loc == "" {
+		return ""
+	}
+	if strings.EqualFold(path.Base(loc), "SKILL.md") {
+		return path.Base(path.Dir(loc))
+	}
+	return strings.TrimSuffix(path.Base(loc), path.Ext(loc))
+}
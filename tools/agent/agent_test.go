@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+)
+
+const file = `---
+name: pal-master
+description: Routes work
+---
+
+# PAL Master
+
+## 1. Identity
+
+## 2) Rules
+
+## 5. My Capabilities
+
+- **name**: note-taking
+  **location**: [SKILL.md](.claude/skills/note-taking/SKILL.md)
+  **use_when**: "User wants to capture a note"
+
+` + "```yaml" + `
+- name: pull_tasks
+  location: ` + "`.claude/skills/pm/workflows/pull_tasks.md`" + `
+  use when: Aggregating tasks
+` + "```" + `
+
+| Skill | Location | Use When |
+|---|---|---|
+| System Cleaner | .claude/skills/system-cleaner/ | Auditing |
+| | ignored | |
+
+## Notes
+`
+
+func TestParse(t *testing.T) {
+	a, err := Parse(".claude/agents/pal-master.md", []byte(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Name() != "pal-master" {
+		t.Errorf("Name = %q", a.Name())
+	}
+	sections := []Section{
+		{Number: 1, Title: "Identity", Line: 8},
+		{Number: 2, Title: "Rules", Line: 10},
+		{Number: 5, Title: "My Capabilities", Line: 12},
+		{Title: "Notes", Line: 29},
+	}
+	if !reflect.DeepEqual(a.Sections, sections) {
+		t.Errorf("Sections = %+v, want %+v", a.Sections, sections)
+	}
+	if s, ok := a.Section(5); !ok || s.Title != "My Capabilities" {
+		t.Errorf("Section(5) = %+v, %v", s, ok)
+	}
+	if _, ok := a.Section(3); ok {
+		t.Error("Section(3) found a missing section")
+	}
+
+	tests := []struct {
+		c      Capability
+		target string
+	}{
+		{Capability{Name: "note-taking", Location: ".claude/skills/note-taking/SKILL.md", UseWhen: "User wants to capture a note", Line: 14}, "note-taking"},
+		{Capability{Name: "pull_tasks", Location: ".claude/skills/pm/workflows/pull_tasks.md", UseWhen: "Aggregating tasks", Line: 19}, "pull_tasks"},
+		{Capability{Name: "System Cleaner", Location: ".claude/skills/system-cleaner/", UseWhen: "Auditing", Line: 26}, "system-cleaner"},
+	}
+	if len(a.Capabilities) != len(tests) {
+		t.Fatalf("Capabilities = %+v", a.Capabilities)
+	}
+	for i, tt := range tests {
+		if c := a.Capabilities[i]; c != tt.c || c.Target() != tt.target {
+			t.Errorf("capability %d = %+v (target %q), want %+v (target %q)", i, c, c.Target(), tt.c, tt.target)
+		}
+	}
+}
+
+func TestParseWithoutNumbers(t *testing.T) {
+	a, err := Parse(".claude/agents/helper.md", []byte("---\nname: [bad\n---\n## Capabilities\n\n- name: x\n"))
+	if err == nil || a == nil {
+		t.Fatalf("Parse = %v, %v; want an agent and a frontmatter error", a, err)
+	}
+	if a.Name() != "helper" || len(a.Capabilities) != 1 || a.Capabilities[0].Name != "x" {
+		t.Errorf("agent = %q with %+v", a.Name(), a.Capabilities)
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := map[string]string{
+		"Note Taking":       "note-taking",
+		"note_taking":       "note-taking",
+		"/note-taking.md":   "note-taking",
+		" -- Spaced _ Out ": "spaced-out",
+	}
+	for in, want := range tests {
+		if got := Key(in); got != want {
+			t.Errorf("Key(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Package audit runs the system-cleaner checks that need no judgement
+// (requirements 1.8.1 to 1.8.10): each audit walks a loaded vault and
+// returns findings labelled the way the system-cleaner workflows label
+// them, so the report reads the same whether a skill or pal produced it.
+//
+// Findings come back sorted by path, line and kind, so two runs over the
+// same vault give identical output.
+package audit
+
+import "sort"
+
+// Finding is one problem reported by an audit.
+type Finding struct {
+	// Audit names the audit that reported it, e.g. "domains".
+	Audit string `json:"audit"`
+	// Kind is the workflow's label, e.g. "MISSING STRUCTURE".
+	Kind string `json:"kind"`
+	// Direction says which side of a comparison the finding was seen on,
+	// e.g. "disk-only"; empty for findings that are not comparisons.
+	Direction string `json:"direction,omitempty"`
+	// Path is vault-relative; Line is 1-based, or 0 for the whole file.
+	Path    string `json:"path"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+	// Fix recommends how to resolve the finding, where one rule decides
+	// it; empty otherwise.
+	Fix string `json:"fix,omitempty"`
+}
+
+func sortFindings(fs []Finding) {
+	sort.SliceStable(fs, func(i, j int) bool {
+		a, b := fs[i], fs[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Kind < b.Kind
+	})
+}
@@ -0,0 +1,184 @@
+package audit
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/agent"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/scheme"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/wikilink"
+)
+
+// Labels reported by Domains.
+const (
+	MissingStructure = "MISSING STRUCTURE"
+	NestingTooDeep   = "NESTING TOO DEEP"
+	StaleEntry       = "STALE ENTRY"
+	UnlistedProject  = "UNLISTED PROJECT"
+)
+
+// MaxDepth is how many folder levels a domain may nest below its root
+// (requirement 1.8.6): 01_PROJECTS/feature/specs is fine, one more is not.
+const MaxDepth = 3
+
+// Domains checks each of domains (requirements 1.8.4 to 1.8.6):
+//
+//   - INDEX.md, CONNECTIONS.yaml and the six numbered folders exist;
+//   - no folder is nested more than MaxDepth levels below the domain root;
+//   - the INDEX.md Active Work table and 01_PROJECTS/ list the same
+//     projects. Rows with no project on disk are stale entries, projects
+//     with no row are unlisted. Domains without the table are not
+//     compared.
+func Domains(v *vault.Vault, domains []*vault.Domain) ([]Finding, error) {
+	var out []Finding
+	add := func(kind, p string, line int, msg string) {
+		out = append(out, Finding{Audit: "domains", Kind: kind, Path: p, Line: line, Message: msg})
+	}
+	for _, d := range domains {
+		if d.Index == nil {
+			add(MissingStructure, d.Path+"/"+vault.IndexFile, 0, "domain has no "+vault.IndexFile)
+		}
+		if !d.HasConnections {
+			add(MissingStructure, d.Path+"/"+vault.ConnectionsFile, 0, "domain has no "+vault.ConnectionsFile)
+		}
+		hint := ""
+		if ver, _, err := scheme.Detect(v.Abs(d.Path)); err == nil && ver == scheme.V1 {
+			hint = " (the domain uses the v1 folder scheme; see pal domain migrate)"
+		}
+		for _, f := range vault.Folders {
+			if !d.Folders[f] {
+				add(MissingStructure, d.Folder(f)+"/", 0, "required folder "+f+"/ is missing"+hint)
+			}
+		}
+
+		deep, err := tooDeep(v, d)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range deep {
+			add(NestingTooDeep, p+"/", 0, "folder is "+strconv.Itoa(depth(d, p))+" levels below the domain root; at most "+strconv.Itoa(MaxDepth)+" are allowed")
+		}
+
+		if d.Index == nil || !d.Index.HasActiveWork {
+			continue
+		}
+		listed := map[string]bool{}
+		for _, w := range d.Index.ActiveWork {
+			found := false
+			for _, p := range d.Projects {
+				if ListsProject(w, p) {
+					listed[p.Path] = true
+					found = true
+				}
+			}
+			if !found {
+				add(StaleEntry, d.Index.Path, w.Line, "Active Work lists \""+w.Project+"\" but "+vault.ProjectsDir+"/ has no such project")
+			}
+		}
+		for _, p := range d.Projects {
+			if !listed[p.Path] {
+				add(UnlistedProject, p.Path, 0, "project is not listed in the "+vault.IndexFile+" Active Work table")
+			}
+		}
+	}
+	sortFindings(out)
+	return out, nil
+}
+
+// tooDeep returns the shallowest folders of d that sit deeper than
+// MaxDepth; their subfolders are not reported separately.
+func tooDeep(v *vault.Vault, d *vault.Domain) ([]string, error) {
+	root := v.Abs(d.Path)
+	var out []string
+	err := filepath.WalkDir(root, func(p string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !e.IsDir() || p == root {
+			return nil
+		}
+		if strings.HasPrefix(e.Name(), ".") {
+			return filepath.SkipDir
+		}
+		r, err := filepath.Rel(v.Root, p)
+		if err != nil {
+			return err
+		}
+		if r = filepath.ToSlash(r); depth(d, r) > MaxDepth {
+			out = append(out, r)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return out, err
+}
+
+func depth(d *vault.Domain, p string) int {
+	return strings.Count(strings.TrimPrefix(p, d.Path+"/"), "/") + 1
+}
+
+// mdLink matches [text](target) inside a table cell.
+var mdLink = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]+)[^)]*\)`)
+
+// ListsProject reports whether the Active Work row w refers to p, by
+// plain name or link, with or without the PROJECT_ prefix.
+func ListsProject(w vault.ActiveWork, p *vault.Project) bool {
+	for _, k := range rowKeys(w.Project) {
+		if contains(projectKeys(p), k) {
+			return true
+		}
+	}
+	return false
+}
+
+// rowKeys returns the names an Active Work cell may refer to a project by:
+// its plain text, or the targets of any wikilinks and markdown links in it.
+func rowKeys(cell string) []string {
+	var keys []string
+	for _, l := range wikilink.Find(cell) {
+		keys = append(keys, key(path.Base(l.Target)))
+		if l.Alias != "" {
+			keys = append(keys, key(l.Alias))
+		}
+	}
+	for _, m := range mdLink.FindAllStringSubmatch(cell, -1) {
+		keys = append(keys, key(m[1]), key(path.Base(strings.TrimSuffix(m[2], "/"))))
+	}
+	if len(keys) == 0 {
+		keys = append(keys, key(strings.Trim(cell, "*_` ")))
+	}
+	return keys
+}
+
+// projectKeys returns the names a project may be listed under: its file or
+// folder name and its frontmatter name, each with and without the
+// PROJECT_ or PLAN_ prefix.
+func projectKeys(p *vault.Project) []string {
+	keys := []string{key(path.Base(p.Path)), key(p.Name)}
+	for _, k := range keys[:2] {
+		for _, prefix := range []string{"project-", "plan-"} {
+			if strings.HasPrefix(k, prefix) {
+				keys = append(keys, k[len(prefix):])
+			}
+		}
+	}
+	return keys
+}
+
+// key folds a project name so "FEAT_001 Semantic-Graph.md" and
+// "feat-001-semantic-graph" compare equal.
+func key(s string) string { return agent.Key(s) }
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x != "" && x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+// load writes files, keyed by vault-relative path, into a fresh vault and
+// loads it.
+func load(t *testing.T, files map[string]string) *vault.Vault {
+	t.Helper()
+	root := t.TempDir()
+	for p, data := range files {
+		abs := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(abs, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	v, err := vault.Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// want is the part of a Finding a test table checks.
+type want struct {
+	kind, path string
+	line       int
+}
+
+func check(t *testing.T, name string, got []Finding, wants []want) {
+	t.Helper()
+	if len(got) != len(wants) {
+		t.Errorf("%s gave %d findings, want %d: %+v", name, len(got), len(wants), got)
+		return
+	}
+	for i, w := range wants {
+		if f := got[i]; f.Kind != w.kind || f.Path != w.path || f.Line != w.line {
+			t.Errorf("%s finding %d = %s %s:%d (%s), want %s %s:%d", name, i, f.Kind, f.Path, f.Line, f.Message, w.kind, w.path, w.line)
+		}
+	}
+}
+
+func TestDomains(t *testing.T) {
+	files := map[string]string{
+		"Domains/Work/INDEX.md": "---\nname: Work\n---\n\n## Active Work\n\n| Project | Status |\n|---|---|\n" +
+			"| [[PROJECT_API]] | active |\n| [Site](01_PROJECTS/website/) | active |\n| Semantic Graph | paused |\n| Gone | done |\n",
+		"Domains/Work/CONNECTIONS.yaml":                       "version: 1\nconnections: []\n",
+		"Domains/Work/01_PROJECTS/PROJECT_API.md":             "",
+		"Domains/Work/01_PROJECTS/website/specs/v1/README.md": "",
+		"Domains/Work/01_PROJECTS/PROJECT_SEMANTIC_GRAPH.md":  "",
+		"Domains/Work/01_PROJECTS/PROJECT_MOBILE.md":          "---\nname: Mobile\n---\n",
+		"Domains/Work/01_PROJECTS/website/specs/v1/a/deep.md": "",
+		"Domains/Work/01_PROJECTS/website/.git/objects/x/y/z": "",
+		"Domains/Bare/02_PAGES/a.md":                          "",
+	}
+	for _, f := range vault.Folders {
+		files["Domains/Work/"+f+"/.keep"] = ""
+	}
+	v := load(t, files)
+	got, err := Domains(v, v.Domains)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "Domains", got, []want{
+		{MissingStructure, "Domains/Bare/00_CONTEXT/", 0},
+		{MissingStructure, "Domains/Bare/01_PROJECTS/", 0},
+		{MissingStructure, "Domains/Bare/03_OUTPUT/", 0},
+		{MissingStructure, "Domains/Bare/04_SESSIONS/", 0},
+		{MissingStructure, "Domains/Bare/05_ARCHIVE/", 0},
+		{MissingStructure, "Domains/Bare/CONNECTIONS.yaml", 0},
+		{MissingStructure, "Domains/Bare/INDEX.md", 0},
+		{UnlistedProject, "Domains/Work/01_PROJECTS/PROJECT_MOBILE.md", 0},
+		{NestingTooDeep, "Domains/Work/01_PROJECTS/website/specs/v1/", 0},
+		{StaleEntry, "Domains/Work/INDEX.md", 12},
+	})
+}
+
+func TestListsProject(t *testing.T) {
+	p := &vault.Project{Note: vault.Note{Path: "Domains/Work/01_PROJECTS/PROJECT_SEMANTIC_GRAPH.md"}, Name: "Knowledge Graph"}
+	tests := map[string]bool{
+		"Semantic Graph":                                 true,
+		"**semantic-graph**":                             true,
+		"[[PROJECT_SEMANTIC_GRAPH|the graph]]":           true,
+		"[[Other|Knowledge Graph]]":                      true,
+		"[Graph](01_PROJECTS/PROJECT_SEMANTIC_GRAPH.md)": true,
+		"Knowledge Graph":                                true,
+		"Semantic":                                       false,
+		"[[PROJECT_API]]":                                false,
+	}
+	for cell, want := range tests {
+		if got := ListsProject(vault.ActiveWork{Project: cell}, p); got != want {
+			t.Errorf("ListsProject(%q) = %v, want %v", cell, got, want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package audit
+
+import (
+	"path"
+	"sort"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/rename"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/schema"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+// InvalidFrontmatter is the kind of a Frontmatter finding.
+const InvalidFrontmatter = "INVALID FRONTMATTER"
+
+// Frontmatter checks every file the vault's frontmatter schemas cover, as
+// pal frontmatter validate does, and reports each problem.
+func Frontmatter(v *vault.Vault) ([]Finding, error) {
+	reg, err := schema.Load(v.Root, v.Layout)
+	if err != nil {
+		return nil, err
+	}
+	files, _, err := rename.Walk(v.Root)
+	if err != nil {
+		return nil, err
+	}
+	// Walk skips dot files; the current-session file is one.
+	files = append(files, path.Join(v.Layout.Path(layout.Sessions), ".current-session"))
+	sort.Strings(files)
+	var out []Finding
+	for _, f := range files {
+		issues, err := reg.Check(v.Root, f)
+		if err != nil {
+			return nil, err
+		}
+		for _, is := range issues {
+			out = append(out, Finding{Audit: "frontmatter", Kind: InvalidFrontmatter, Path: is.Path,
+				Message: is.Problem.String() + " (schema " + is.Schema + ")"})
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,36 @@
+package audit
+
+import "testing"
+
+func TestFrontmatter(t *testing.T) {
+	v := load(t, map[string]string{
+		"Domains/Work/01_PROJECTS/PROJECT_API.md": "---\nname: API\nstatus: active\ncreated: 2026-10-01\n---\n",
+		"Domains/Work/01_PROJECTS/PROJECT_WEB.md": "---\nname: Web\ncreated: 01/10/2026\n---\n",
+		"Domains/Work/01_PROJECTS/README.md":      "no frontmatter\n",
+		".claude/sessions/.current-session":       "agent: pal-master\ndomain: ~\nloaded_paths: []\n",
+	})
+	got, err := Frontmatter(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path, msg string
+	}{
+		{".claude/sessions/.current-session", "loaded_at: required field is missing (schema current-session)"},
+		{"Domains/Work/01_PROJECTS/PROJECT_WEB.md", "status: required field is missing (schema project)"},
+		{"Domains/Work/01_PROJECTS/PROJECT_WEB.md", `created: "01/10/2026" is not a date (YYYY-MM-DD) (schema project)`},
+	}
+	if len(got) != len(tests) {
+		t.Fatalf("Frontmatter gave %d findings, want %d:\n%s", len(got), len(tests), messages(got))
+	}
+	for i, tt := range tests {
+		if f := got[i]; f.Kind != InvalidFrontmatter || f.Path != tt.path || f.Message != tt.msg {
+			t.Errorf("finding %d = %s %s %q, want %s %q", i, f.Kind, f.Path, f.Message, tt.path, tt.msg)
+		}
+	}
+
+	empty := load(t, map[string]string{".claude/sessions/.current-session": "\n"})
+	if got, err := Frontmatter(empty); err != nil || len(got) != 0 {
+		t.Errorf("dismissed session: %v, %v", got, err)
+	}
+}
@@ -0,0 +1,187 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+// Status is the overall health of a vault (requirement 1.8.11).
+type Status string
+
+const (
+	Healthy        Status = "HEALTHY"
+	MinorIssues    Status = "MINOR ISSUES"
+	NeedsAttention Status = "NEEDS ATTENTION"
+)
+
+// StatusOf maps an issue count to a status: none is healthy, up to five
+// are minor, more need attention.
+func StatusOf(issues int) Status {
+	switch {
+	case issues == 0:
+		return Healthy
+	case issues <= 5:
+		return MinorIssues
+	}
+	return NeedsAttention
+}
+
+// Result is the outcome of one audit within a health report.
+type Result struct {
+	Audit    string    `json:"audit"`
+	Status   Status    `json:"status"`
+	Findings []Finding `json:"findings"`
+}
+
+// Report is a health report: every audit, in the order the health_report
+// workflow runs them.
+type Report struct {
+	Time    time.Time `json:"time"`
+	Status  Status    `json:"status"`
+	Issues  int       `json:"issues"`
+	Results []Result  `json:"audits"`
+}
+
+// Check is an audit that Health runs after its own, such as the agent
+// and skill validators of package validate, which imports this one.
+type Check struct {
+	Audit string
+	Run   func(v *vault.Vault) ([]Finding, error)
+}
+
+// Health runs the references, domains, naming, orphans, links and
+// frontmatter audits, in that order, then checks. staleDays is the
+// orphans session threshold.
+func Health(v *vault.Vault, now time.Time, staleDays int, checks ...Check) (*Report, error) {
+	r := &Report{Time: now}
+	all := []Check{
+		{"references", References},
+		{"domains", func(v *vault.Vault) ([]Finding, error) { return Domains(v, v.Domains) }},
+		{"naming", Naming},
+		{"orphans", func(v *vault.Vault) ([]Finding, error) {
+			fs, _, err := Orphans(v, now, staleDays)
+			return fs, err
+		}},
+		{"links", func(v *vault.Vault) ([]Finding, error) { return Links(v, nil) }},
+		{"frontmatter", Frontmatter},
+	}
+	for _, c := range append(all, checks...) {
+		fs, err := c.Run(v)
+		if err != nil {
+			return nil, fmt.Errorf("audit %s: %w", c.Audit, err)
+		}
+		if fs == nil {
+			fs = []Finding{}
+		}
+		r.Results = append(r.Results, Result{Audit: c.Audit, Status: StatusOf(len(fs)), Findings: fs})
+		r.Issues += len(fs)
+	}
+	r.Status = StatusOf(r.Issues)
+	return r, nil
+}
+
+// HistoryFile is the health history's file name in the layout's state
+// folder.
+const HistoryFile = "health-history.jsonl"
+
+// Run is one line of the health history.
+type Run struct {
+	Time   time.Time      `json:"time"`
+	Status Status         `json:"status"`
+	Issues int            `json:"issues"`
+	Audits map[string]int `json:"audits"`
+}
+
+// Run summarises r for the history.
+func (r *Report) Run() Run {
+	run := Run{Time: r.Time.UTC().Truncate(time.Second), Status: r.Status, Issues: r.Issues, Audits: map[string]int{}}
+	for _, res := range r.Results {
+		run.Audits[res.Audit] = len(res.Findings)
+	}
+	return run
+}
+
+// LoadHistory reads the history at path, oldest run first. A missing file
+// is an empty history.
+func LoadHistory(path string) ([]Run, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []Run
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var run Run
+		if err := json.Unmarshal(sc.Bytes(), &run); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		out = append(out, run)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out, sc.Err()
+}
+
+// AppendHistory adds run to the history at path, creating it as needed.
+// The file is JSON Lines, so appending never rewrites earlier runs.
+func AppendHistory(path string, run Run) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Weekly keeps the last run of each ISO week, oldest first, so a long
+// history reads as a week-over-week trend.
+func Weekly(runs []Run) []Run {
+	var out []Run
+	for _, r := range runs {
+		y, w := r.Time.ISOWeek()
+		if n := len(out); n > 0 {
+			py, pw := out[n-1].Time.ISOWeek()
+			if py == y && pw == w {
+				out[n-1] = r
+				continue
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// Before returns the latest run at least d older than t, if any.
+func Before(runs []Run, t time.Time, d time.Duration) (Run, bool) {
+	for i := len(runs) - 1; i >= 0; i-- {
+		if !runs[i].Time.After(t.Add(-d)) {
+			return runs[i], true
+		}
+	}
+	return Run{}, false
+}
@@ -0,0 +1,197 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+func TestStatusOf(t *testing.T) {
+	tests := map[int]Status{0: Healthy, 1: MinorIssues, 5: MinorIssues, 6: NeedsAttention}
+	for issues, want := range tests {
+		if got := StatusOf(issues); got != want {
+			t.Errorf("StatusOf(%d) = %s, want %s", issues, got, want)
+		}
+	}
+}
+
+func TestHealth(t *testing.T) {
+	v := load(t, map[string]string{"Domains/Work/02_PAGES/Bad Name.md": "[x](missing.md)\n"})
+	now := time.Date(2026, 10, 18, 9, 30, 15, 500, time.UTC)
+	extra := Check{"skills", func(*vault.Vault) ([]Finding, error) {
+		return []Finding{{Audit: "skills", Kind: "INVALID SKILL", Path: ".claude/skills/x/SKILL.md", Message: "bad"}}, nil
+	}}
+	r, err := Health(v, now, DefaultStaleDays, extra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var audits []string
+	issues := 0
+	for _, res := range r.Results {
+		audits = append(audits, res.Audit)
+		issues += len(res.Findings)
+		if res.Findings == nil || res.Status != StatusOf(len(res.Findings)) {
+			t.Errorf("%s: status %s with findings %v", res.Audit, res.Status, res.Findings)
+		}
+	}
+	if want := []string{"references", "domains", "naming", "orphans", "links", "frontmatter", "skills"}; !reflect.DeepEqual(audits, want) {
+		t.Errorf("audits = %v, want %v", audits, want)
+	}
+	if r.Issues != issues || r.Status != StatusOf(issues) || issues < 4 {
+		t.Errorf("report: %d issues, status %s; results hold %d", r.Issues, r.Status, issues)
+	}
+
+	run := r.Run()
+	if !run.Time.Equal(now.Truncate(time.Second)) || run.Issues != r.Issues || run.Audits["skills"] != 1 || len(run.Audits) != 7 {
+		t.Errorf("Run = %+v", run)
+	}
+
+	failing := Check{"broken", func(*vault.Vault) ([]Finding, error) { return nil, errors.New("boom") }}
+	if _, err := Health(v, now, DefaultStaleDays, failing); err == nil || err.Error() != "audit broken: boom" {
+		t.Errorf("Health with a failing check: %v", err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", HistoryFile)
+	if runs, err := LoadHistory(path); err != nil || runs != nil {
+		t.Fatalf("LoadHistory of a missing file = %v, %v", runs, err)
+	}
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 12, 0, 0, 0, time.UTC) }
+	// Mon 5, Wed 7 and Sun 11 share an ISO week; Mon 12 starts the next.
+	for _, d := range []int{7, 5, 11, 12} {
+		if err := AppendHistory(path, Run{Time: day(d), Issues: d, Audits: map[string]int{"links": d}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runs, err := LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int
+	for _, r := range runs {
+		got = append(got, r.Issues)
+	}
+	if !reflect.DeepEqual(got, []int{5, 7, 11, 12}) {
+		t.Errorf("history order = %v", got)
+	}
+	if w := Weekly(runs); len(w) != 2 || w[0].Issues != 11 || w[1].Issues != 12 {
+		t.Errorf("Weekly = %+v", w)
+	}
+
+	tests := []struct {
+		t    time.Time
+		d    time.Duration
+		want int // issues of the run found; 0 for none
+	}{
+		{t: day(12), d: 7 * 24 * time.Hour, want: 5},
+		{t: day(12), d: 24 * time.Hour, want: 11},
+		{t: day(12), d: 0, want: 12},
+		{t: day(5), d: time.Hour},
+	}
+	for _, tt := range tests {
+		r, ok := Before(runs, tt.t, tt.d)
+		if ok != (tt.want != 0) || r.Issues != tt.want {
+			t.Errorf("Before(%s, %s) = %d, %v; want %d", tt.t.Format("Jan 2"), tt.d, r.Issues, ok, tt.want)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("{\"issues\": 1}\nnot json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadHistory(path); err == nil || !strings.Contains(err.Error(), HistoryFile+":2:") {
+		t.Errorf("LoadHistory of a corrupt file: %v", err)
+	}
+}
+
+func TestSARIF(t *testing.T) {
+	r := &Report{Results: []Result{
+		{Audit: "links", Findings: []Finding{
+			{Audit: "links", Kind: DeadLink, Path: "a.md", Line: 3, Message: "no such note", Fix: "[[b]]"},
+			{Audit: "links", Kind: DeadLink, Path: "c.md", Message: "no such note"},
+		}},
+		{Audit: "references", Findings: []Finding{
+			{Audit: "references", Kind: DeadReference, Direction: IndexOnly, Path: "R.md", Line: 2, Message: "gone"},
+		}},
+	}}
+	data, err := json.Marshal(r.SARIF())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct{ ID string } `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Message   struct{ Text string }
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           *struct{ StartLine int }
+					}
+				}
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("SARIF = %s", data)
+	}
+	run := log.Runs[0]
+	if rules := run.Tool.Driver.Rules; len(rules) != 2 || rules[0].ID != "links/dead-link" || rules[1].ID != "references/dead-reference" {
+		t.Errorf("rules = %+v", rules)
+	}
+	tests := []struct {
+		rule, msg, uri string
+		line           int
+	}{
+		{"links/dead-link", "no such note; fix: [[b]]", "a.md", 3},
+		{"links/dead-link", "no such note", "c.md", 0},
+		{"references/dead-reference", "gone (index-only)", "R.md", 2},
+	}
+	if len(run.Results) != len(tests) {
+		t.Fatalf("results = %+v", run.Results)
+	}
+	for i, tt := range tests {
+		res := run.Results[i]
+		loc := res.Locations[0].PhysicalLocation
+		line := 0
+		if loc.Region != nil {
+			line = loc.Region.StartLine
+		}
+		if res.RuleID != tt.rule || res.Message.Text != tt.msg || loc.ArtifactLocation.URI != tt.uri || line != tt.line {
+			t.Errorf("result %d = %s %q at %s:%d, want %s %q at %s:%d", i, res.RuleID, res.Message.Text, loc.ArtifactLocation.URI, line, tt.rule, tt.msg, tt.uri, tt.line)
+		}
+	}
+}
+
+func TestNaming(t *testing.T) {
+	v := load(t, map[string]string{
+		"Domains/Work/02_PAGES/Bad Name.md":        "",
+		"Domains/Work/02_PAGES/good_name.md":       "",
+		"Domains/Work/04_SESSIONS/2026-10-01_a.md": "",
+		".claude/agents/Pal Master.md":             "",
+	})
+	got, err := Naming(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "Naming", got, []want{
+		{NamingViolation, ".claude/agents/Pal Master.md", 0},
+		{NamingViolation, "Domains/Work/02_PAGES/Bad Name.md", 0},
+	})
+}
@@ -0,0 +1,270 @@
+package audit
+
+import (
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/fuzzy"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/rename"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/requirements"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/wikilink"
+)
+
+// Labels reported by Links.
+const (
+	DeadLink   = "DEAD LINK"
+	DeadAnchor = "DEAD ANCHOR"
+	DeadSource = "DEAD SOURCE"
+)
+
+// Links reports the links in files that point nowhere: wikilinks, with
+// their headings and block references, inline markdown links, with their
+// #fragments, and the Source: lines of 06_REQUIREMENTS documents. files
+// are vault-relative; nil checks every markdown file, .claude included.
+//
+// Wikilinks resolve the way Obsidian does, against the files Obsidian
+// sees; markdown links resolve against the disk, relative to the linking
+// file or else to the vault root. Each finding suggests the closest
+// existing path or heading when there is one.
+func Links(v *vault.Vault, files []string) ([]Finding, error) {
+	all, dirs, err := rename.Walk(v.Root)
+	if err != nil {
+		return nil, err
+	}
+	exists := map[string]bool{}
+	for _, f := range append(all, dirs...) {
+		exists[f] = true
+	}
+	visible, err := v.AllFiles()
+	if err != nil {
+		return nil, err
+	}
+	r := wikilink.NewResolver(visible)
+	if files == nil {
+		for _, f := range all {
+			if strings.EqualFold(path.Ext(f), ".md") {
+				files = append(files, f)
+			}
+		}
+	}
+
+	texts := map[string]string{}
+	read := func(p string) (string, error) {
+		if t, ok := texts[p]; ok {
+			return t, nil
+		}
+		data, err := os.ReadFile(v.Abs(p))
+		if err != nil {
+			return "", err
+		}
+		texts[p] = string(data)
+		return texts[p], nil
+	}
+
+	var out []Finding
+	for _, f := range files {
+		text, err := read(f)
+		if err != nil {
+			return nil, err
+		}
+		lines := markdown.Lines(text)
+
+		for _, l := range wikilink.Find(text) {
+			if placeholder(l.Target) {
+				continue
+			}
+			res, ok := r.Lookup(f, l)
+			if !ok {
+				msg := l.Raw + ": no file matches \"" + l.Target + "\""
+				if s, ok := closestPath(l.Target, visible, true); ok {
+					msg += "; did you mean " + s + "?"
+				}
+				out = append(out, Finding{Audit: "links", Kind: DeadLink, Path: f, Line: l.Line, Message: msg})
+				continue
+			}
+			if l.Heading == "" && l.Block == "" {
+				continue
+			}
+			target, err := read(res.Path)
+			if err != nil {
+				return nil, err
+			}
+			if msg, ok := deadAnchor(target, res.Path, l.Heading, l.Block); ok {
+				out = append(out, Finding{Audit: "links", Kind: DeadAnchor, Path: f, Line: l.Line, Message: l.Raw + ": " + msg})
+			}
+		}
+
+		for _, l := range markdown.Links(text) {
+			if placeholder(l.Target) {
+				continue
+			}
+			kind := DeadLink
+			if strings.HasPrefix(strings.TrimSpace(lines[l.Line-1]), "Source:") && isRequirements(f) {
+				kind = DeadSource
+			}
+			target := f
+			if l.Target != "" {
+				t, _ := rename.ResolveLink(f, l.Target, exists)
+				if t == "" {
+					msg := l.Raw + ": no such file " + written(f, l.Target)
+					if s, ok := closestPath(written(f, l.Target), all, false); ok {
+						msg += "; did you mean " + s + "?"
+					}
+					out = append(out, Finding{Audit: "links", Kind: kind, Path: f, Line: l.Line, Message: msg})
+					continue
+				}
+				target = t
+			}
+			if l.Fragment == "" || !strings.EqualFold(path.Ext(target), ".md") {
+				continue
+			}
+			text, err := read(target)
+			if err != nil {
+				return nil, err
+			}
+			if msg, ok := deadFragment(text, target, l.Fragment); ok {
+				out = append(out, Finding{Audit: "links", Kind: DeadAnchor, Path: f, Line: l.Line, Message: l.Raw + ": " + msg})
+			}
+		}
+	}
+	sortFindings(out)
+	return out, nil
+}
+
+// placeholder reports whether target is template text such as
+// "{{title}}" or "<path>" rather than a link.
+func placeholder(target string) bool {
+	return strings.Contains(target, "{{") || strings.HasPrefix(target, "<") || strings.Contains(target, "...")
+}
+
+func isRequirements(p string) bool {
+	return strings.Contains("/"+p, "/"+requirements.Folder+"/")
+}
+
+// written returns the vault-relative path a markdown link target names,
+// for messages: relative targets are taken from the linking file's folder
+// unless they start at .claude, the way the docs write them.
+func written(from, target string) string {
+	if strings.HasPrefix(target, "/") || strings.HasPrefix(target, ".claude/") {
+		return path.Clean(strings.TrimPrefix(target, "/"))
+	}
+	return path.Join(path.Dir(from), target)
+}
+
+// deadAnchor checks a wikilink's heading or block in the text of target.
+func deadAnchor(text, target, heading, block string) (string, bool) {
+	if heading != "" && !wikilink.HasHeading(text, heading) {
+		msg := "no heading \"" + heading + "\" in " + target
+		if s, ok := closestHeading(text, heading); ok {
+			msg += "; did you mean #" + s + "?"
+		}
+		return msg, true
+	}
+	if block != "" && !wikilink.HasBlock(text, block) {
+		var ids []string
+		for id := range wikilink.BlockIDs(text) {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		msg := "no block ^" + block + " in " + target
+		if s, d := fuzzy.Closest(block, ids); fuzzy.Near(block, d) {
+			msg += "; did you mean ^" + s + "?"
+		}
+		return msg, true
+	}
+	return "", false
+}
+
+// deadFragment checks a markdown link's #fragment, written either as the
+// heading text or as its GitHub-style slug ("#my-heading").
+func deadFragment(text, target, frag string) (string, bool) {
+	if strings.HasPrefix(frag, "^") {
+		return deadAnchor(text, target, "", frag[1:])
+	}
+	for _, h := range markdown.Headings(text) {
+		if slug(h.Text) == slug(frag) {
+			return "", false
+		}
+	}
+	msg := "no heading #" + frag + " in " + target
+	if s, ok := closestHeading(text, frag); ok {
+		msg += "; did you mean #" + slug(s) + "?"
+	}
+	return msg, true
+}
+
+// slug turns heading text into a GitHub-style anchor: lower case, spaces
+// to hyphens, punctuation dropped.
+func slug(s string) string {
+	if u, err := url.PathUnescape(s); err == nil {
+		s = u
+	}
+	var b strings.Builder
+	for _, c := range strings.ToLower(strings.TrimSpace(s)) {
+		switch {
+		case c == ' ' || c == '-':
+			b.WriteByte('-')
+		case c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c > 127:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+func closestHeading(text, heading string) (string, bool) {
+	var names []string
+	for _, h := range markdown.Headings(text) {
+		names = append(names, h.Text)
+	}
+	last := heading[strings.LastIndexByte(heading, '#')+1:]
+	s, d := fuzzy.Closest(last, names)
+	return s, fuzzy.Near(last, d)
+}
+
+// closestPath suggests the existing path nearest to p: the one file with
+// the same name elsewhere, the nearest of several within typo distance,
+// or else the nearest path within typo distance. For wikilinks (byName)
+// p is a link target without extension, compared with file paths minus
+// .md.
+func closestPath(p string, paths []string, byName bool) (string, bool) {
+	p = strings.TrimSuffix(p, "/")
+	if u, err := url.PathUnescape(p); err == nil {
+		p = u
+	}
+	shown := func(c string) string {
+		if byName {
+			return strings.TrimSuffix(c, ".md")
+		}
+		return c
+	}
+	base := strings.ToLower(path.Base(p))
+	var same []string
+	for _, c := range paths {
+		if strings.ToLower(path.Base(shown(c))) == base {
+			same = append(same, shown(c))
+		}
+	}
+	if len(same) == 1 {
+		return same[0], true
+	}
+	if len(same) > 1 {
+		s, d := fuzzy.Closest(p, same)
+		return s, fuzzy.Near(p, d)
+	}
+	cands := make([]string, len(paths))
+	for i, c := range paths {
+		cands[i] = shown(c)
+	}
+	if byName && !strings.Contains(p, "/") {
+		for i, c := range cands {
+			cands[i] = path.Base(c)
+		}
+	}
+	s, d := fuzzy.Closest(p, cands)
+	return s, fuzzy.Near(p, d)
+}
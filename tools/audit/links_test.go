@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLinks(t *testing.T) {
+	v := load(t, map[string]string{
+		"Domains/Work/02_PAGES/guide.md": "# Guide\n\n## Setup Steps\n\nRun it. ^step-1\n",
+		"Domains/Work/02_PAGES/notes.md": "[[guide]] [[guide#Setup Steps]] [[guide#Setpu Steps]] [[guide#^step-1]] [[guide#^step-2]]\n" +
+			"[[giude]] [[{{title}}]] [[Gone Forever]]\n" +
+			"[g](guide.md) [g](guide.md#setup-steps) [g](guide.md#Setup%20Steps) [g](guide.md#setup) [self](#nowhere)\n" +
+			"[g](guid.md) [g](.claude/skills/x/SKILL.md) [t]({{link}}) [web](https://x.dev/missing)\n" +
+			"`[[in code]]`\n",
+		"Domains/Work/06_REQUIREMENTS/01_SKILLS.md": "Source: [x.md](.claude/skills/x/missing.md)\nSee [x](.claude/skills/x/SKILL.md)\n",
+		".claude/skills/x/SKILL.md":                 "# X\n",
+		".obsidian/hidden.md":                       "[[nowhere]]\n",
+	})
+	got, err := Links(v, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		kind, path string
+		line       int
+		msg        string
+	}{
+		{DeadAnchor, "Domains/Work/02_PAGES/notes.md", 1,
+			`[[guide#Setpu Steps]]: no heading "Setpu Steps" in Domains/Work/02_PAGES/guide.md; did you mean #Setup Steps?`},
+		{DeadAnchor, "Domains/Work/02_PAGES/notes.md", 1,
+			"[[guide#^step-2]]: no block ^step-2 in Domains/Work/02_PAGES/guide.md; did you mean ^step-1?"},
+		{DeadLink, "Domains/Work/02_PAGES/notes.md", 2,
+			`[[giude]]: no file matches "giude"; did you mean guide?`},
+		{DeadLink, "Domains/Work/02_PAGES/notes.md", 2,
+			`[[Gone Forever]]: no file matches "Gone Forever"`},
+		{DeadAnchor, "Domains/Work/02_PAGES/notes.md", 3,
+			"[g](guide.md#setup): no heading #setup in Domains/Work/02_PAGES/guide.md"},
+		{DeadAnchor, "Domains/Work/02_PAGES/notes.md", 3,
+			"[self](#nowhere): no heading #nowhere in Domains/Work/02_PAGES/notes.md"},
+		{DeadLink, "Domains/Work/02_PAGES/notes.md", 4,
+			"[g](guid.md): no such file Domains/Work/02_PAGES/guid.md; did you mean Domains/Work/02_PAGES/guide.md?"},
+		{DeadSource, "Domains/Work/06_REQUIREMENTS/01_SKILLS.md", 1,
+			"[x.md](.claude/skills/x/missing.md): no such file .claude/skills/x/missing.md; did you mean .claude/skills/x/SKILL.md?"},
+	}
+	if len(got) != len(tests) {
+		t.Fatalf("Links gave %d findings, want %d:\n%s", len(got), len(tests), messages(got))
+	}
+	for i, tt := range tests {
+		f := got[i]
+		if f.Kind != tt.kind || f.Path != tt.path || f.Line != tt.line || f.Message != tt.msg {
+			t.Errorf("finding %d = %s %s:%d %q\nwant %s %s:%d %q", i, f.Kind, f.Path, f.Line, f.Message, tt.kind, tt.path, tt.line, tt.msg)
+		}
+	}
+
+	only, err := Links(v, []string{"Domains/Work/06_REQUIREMENTS/01_SKILLS.md"})
+	if err != nil || len(only) != 1 || only[0].Kind != DeadSource {
+		t.Errorf("Links of one file = %v, %v", only, err)
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Setup Steps":         "setup-steps",
+		"Setup%20Steps":       "setup-steps",
+		"1.5 What's New?":     "15-whats-new",
+		"snake_case - Dashed": "snake_case---dashed",
+		"Café":                "café",
+	}
+	for in, want := range tests {
+		if got := slug(in); got != want {
+			t.Errorf("slug(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func messages(fs []Finding) string {
+	var b strings.Builder
+	for _, f := range fs {
+		fmt.Fprintf(&b, "%s %s:%d %s\n", f.Kind, f.Path, f.Line, f.Message)
+	}
+	return b.String()
+}
@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/naming"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+// NamingViolation labels a name that breaks its naming category.
+const NamingViolation = "NAMING VIOLATION"
+
+// Naming reports the naming-convention violations under the domains and
+// .claude folders as findings (requirement 1.8.7). `pal audit naming`
+// works on the naming.Violation values directly, for -fix.
+func Naming(v *vault.Vault) ([]Finding, error) {
+	domains, _ := v.Find(layout.Domains)
+	claude, _ := v.Find(layout.Claude)
+	entries, err := naming.Scan(v.Root, domains, claude)
+	if err != nil {
+		return nil, err
+	}
+	var out []Finding
+	for _, en := range entries {
+		x, ok := naming.Check(en)
+		if !ok {
+			continue
+		}
+		msg := string(x.Category) + " \"" + x.Current + "\" should match " + x.Expected
+		if x.Suggested != "" {
+			msg += "; suggest " + x.Suggested
+		}
+		out = append(out, Finding{Audit: "naming", Kind: NamingViolation, Path: x.Path, Message: msg})
+	}
+	sortFindings(out)
+	return out, nil
+}
@@ -0,0 +1,164 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/agent"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/naming"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+// Labels reported by Orphans.
+const (
+	OrphanSkill  = "ORPHAN SKILL"
+	StaleSession = "STALE SESSION"
+)
+
+// DefaultStaleDays is how old a session may get before it is stale
+// (requirement 1.8.10).
+const DefaultStaleDays = 30
+
+// Session is one session file and where archiving would move it.
+type Session struct {
+	Path string
+	// Date is the session's date, or the file's modification time when
+	// neither its name nor its frontmatter carries one.
+	Date time.Time
+	// Archive is the vault-relative path archiving moves it to: the
+	// domain's 05_ARCHIVE/ for domain sessions, an archive/ folder beside
+	// them for .claude/sessions.
+	Archive string
+}
+
+// Sessions lists the session files of .claude/sessions and of every
+// domain's 04_SESSIONS/. Running logs such as UPDATES.md are not sessions.
+func Sessions(v *vault.Vault) ([]Session, error) {
+	var out []Session
+	add := func(n *vault.Note, archive string) error {
+		if c, ok := naming.Classify(naming.Entry{Path: n.Path}); !ok || c != naming.SessionFile {
+			return nil
+		}
+		s := Session{Path: n.Path, Date: vault.SessionDate(n), Archive: archive}
+		if s.Date.IsZero() {
+			info, err := os.Stat(v.Abs(n.Path))
+			if err != nil {
+				return err
+			}
+			s.Date = info.ModTime()
+		}
+		out = append(out, s)
+		return nil
+	}
+	if dir, ok := v.Find(layout.Sessions); ok {
+		files, err := markdownFiles(v, dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			if path.Dir(f) != dir {
+				continue // already archived
+			}
+			n, err := vault.ReadNote(v.Root, f)
+			if n == nil {
+				return nil, err
+			}
+			if err := add(n, dir+"/archive/"+path.Base(f)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, d := range v.Domains {
+		for _, s := range d.Sessions {
+			rest := strings.TrimPrefix(s.Path, d.Folder(vault.SessionsDir)+"/")
+			if err := add(&s.Note, d.Folder(vault.ArchiveDir)+"/"+rest); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+// Orphans reports skills that no agent's Section 5 claims (requirement
+// 1.8.9) and sessions more than days old at now (requirement 1.8.10). It
+// also returns the stale sessions, for archiving.
+//
+// A skill is claimed when a capability names it or its location points
+// into the skill's folder, so an agent listing only one of its workflows
+// still claims it.
+func Orphans(v *vault.Vault, now time.Time, days int) ([]Finding, []Session, error) {
+	var out []Finding
+	items, err := Items(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	claimed := map[string]bool{}
+	skills := v.Layout.Path(layout.Skills) + "/"
+	for _, it := range items {
+		if it.Kind != AgentItem {
+			continue
+		}
+		data, err := os.ReadFile(v.Abs(it.Path))
+		if err != nil {
+			return nil, nil, err
+		}
+		a, _ := agent.Parse(it.Path, data)
+		if a == nil {
+			continue
+		}
+		for _, c := range a.Capabilities {
+			claimed[agent.Key(c.Name)] = true
+			loc := strings.TrimPrefix(strings.TrimPrefix(c.Location, "./"), "/")
+			if strings.HasPrefix(strings.ToLower(loc), strings.ToLower(skills)) {
+				name, _, _ := strings.Cut(loc[len(skills):], "/")
+				claimed[agent.Key(name)] = true
+			}
+		}
+	}
+	for _, it := range items {
+		if it.Kind == SkillItem && !claimed[agent.Key(it.Name)] {
+			out = append(out, Finding{Audit: "orphans", Kind: OrphanSkill, Path: it.Path,
+				Message: "skill \"" + it.Name + "\" is not in any agent's Section 5 capabilities"})
+		}
+	}
+
+	sessions, err := Sessions(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	var stale []Session
+	cutoff := now.AddDate(0, 0, -days)
+	for _, s := range sessions {
+		if !s.Date.Before(cutoff) {
+			continue
+		}
+		age := int(now.Sub(s.Date).Hours() / 24)
+		out = append(out, Finding{Audit: "orphans", Kind: StaleSession, Path: s.Path,
+			Message: fmt.Sprintf("session dated %s is %d days old (limit %d); archive to %s", s.Date.Format("2006-01-02"), age, days, s.Archive)})
+		stale = append(stale, s)
+	}
+	sortFindings(out)
+	return out, stale, nil
+}
+
+// Deprecate adds the deprecation header to the body of archived content:
+// a quote below the title, the way deprecated sections of the
+// requirements are marked.
+func Deprecate(body []byte, on time.Time, from, reason string) []byte {
+	header := fmt.Sprintf("> **Archived %s:** %s. Moved from `%s`.\n\n", on.Format("2006-01-02"), reason, from)
+	text := string(body)
+	lead := len(text) - len(strings.TrimLeft(text, "\n"))
+	if rest := text[lead:]; strings.HasPrefix(rest, "# ") {
+		end := strings.IndexByte(rest, '\n')
+		if end < 0 {
+			return []byte(text + "\n\n" + header)
+		}
+		title := rest[:end+1]
+		return []byte(text[:lead] + title + "\n" + header + strings.TrimLeft(rest[end+1:], "\n"))
+	}
+	return []byte(text[:lead] + header + text[lead:])
+}
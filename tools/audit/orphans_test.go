@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOrphans(t *testing.T) {
+	v := load(t, map[string]string{
+		".claude/agents/pal-master.md": "## 5. My Capabilities\n\n" +
+			"- name: Note Taking\n  location: .claude/skills/other/SKILL.md\n" +
+			"- name: pull_tasks\n  location: ./.claude/skills/PM/workflows/pull_tasks.md\n",
+		".claude/skills/note-taking/SKILL.md":         "",
+		".claude/skills/pm/SKILL.md":                  "",
+		".claude/skills/unclaimed/SKILL.md":           "",
+		".claude/sessions/2026-08-01_old.md":          "",
+		".claude/sessions/2026-10-01_recent.md":       "",
+		".claude/sessions/archive/2026-01-01_done.md": "",
+		"Domains/Work/04_SESSIONS/2026-09-01_call.md": "",
+		"Domains/Work/04_SESSIONS/retro.md":           "---\ndate: 2026-07-15\n---\n",
+		"Domains/Work/04_SESSIONS/UPDATES.md":         "---\ndate: 2020-01-01\n---\n",
+	})
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	got, stale, err := Orphans(v, now, DefaultStaleDays)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "Orphans", got, []want{
+		{StaleSession, ".claude/sessions/2026-08-01_old.md", 0},
+		{OrphanSkill, ".claude/skills/unclaimed/SKILL.md", 0},
+		{StaleSession, "Domains/Work/04_SESSIONS/2026-09-01_call.md", 0},
+		{StaleSession, "Domains/Work/04_SESSIONS/retro.md", 0},
+	})
+	if len(got) > 0 && got[0].Message != "session dated 2026-08-01 is 78 days old (limit 30); archive to .claude/sessions/archive/2026-08-01_old.md" {
+		t.Errorf("message = %q", got[0].Message)
+	}
+
+	archives := map[string]string{
+		".claude/sessions/2026-08-01_old.md":          ".claude/sessions/archive/2026-08-01_old.md",
+		"Domains/Work/04_SESSIONS/2026-09-01_call.md": "Domains/Work/05_ARCHIVE/2026-09-01_call.md",
+		"Domains/Work/04_SESSIONS/retro.md":           "Domains/Work/05_ARCHIVE/retro.md",
+	}
+	if len(stale) != len(archives) {
+		t.Fatalf("stale = %+v", stale)
+	}
+	for _, s := range stale {
+		if s.Archive != archives[s.Path] {
+			t.Errorf("%s archives to %s, want %s", s.Path, s.Archive, archives[s.Path])
+		}
+	}
+}
+
+func TestDeprecate(t *testing.T) {
+	on := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	header := "> **Archived 2026-10-18:** stale session. Moved from `a/b.md`.\n\n"
+	tests := []struct {
+		in, want string
+	}{
+		{in: "# Call\n\nNotes\n", want: "# Call\n\n" + header + "Notes\n"},
+		{in: "\n# Call\n", want: "\n# Call\n\n" + header},
+		{in: "# Call", want: "# Call\n\n" + header},
+		{in: "Notes\n", want: header + "Notes\n"},
+	}
+	for _, tt := range tests {
+		if got := string(Deprecate([]byte(tt.in), on, "a/b.md", "stale session")); got != tt.want {
+			t.Errorf("Deprecate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
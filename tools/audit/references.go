@@ -0,0 +1,527 @@
+package audit
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/agent"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/wikilink"
+)
+
+// Labels reported by References.
+const (
+	Unregistered       = "UNREGISTERED"
+	DeadReference      = "DEAD REFERENCE"
+	CapabilityMismatch = "CAPABILITY MISMATCH"
+)
+
+// Directions of a references finding.
+const (
+	DiskOnly  = "disk-only"  // on disk, missing from the tables
+	IndexOnly = "index-only" // in a table, missing on disk or from the agent
+	AgentOnly = "agent-only" // in an agent's Section 5, missing from SYSTEM_INDEX
+)
+
+// Item kinds found under .claude/.
+const (
+	AgentItem    = "agent"
+	SkillItem    = "skill"
+	WorkflowItem = "workflow"
+	CommandItem  = "command"
+)
+
+// Item is one agent, skill, workflow or command on disk.
+type Item struct {
+	Kind string
+	Name string
+	// Path is the vault-relative file; for skills, the SKILL.md.
+	Path string
+}
+
+// Entry is one row of ROUTING_TABLE.md or SYSTEM_INDEX.md.
+type Entry struct {
+	Doc  string
+	Line int
+	// Kind is the item kind the row is about, from its table heading,
+	// column names or paths; "" when none says.
+	Kind string
+	Name string
+	// Paths are the vault-relative files the row links to.
+	Paths []string
+	// Agents are the agents the row assigns the entry to, from an Agent
+	// column or an enclosing heading naming the agent.
+	Agents []string
+}
+
+// Items lists the agents, skills, workflows and commands under the
+// layout's .claude folders, in path order.
+func Items(v *vault.Vault) ([]Item, error) {
+	var out []Item
+	if dir, ok := v.Find(layout.Agents); ok {
+		files, err := markdownFiles(v, dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			if path.Dir(f) == dir {
+				out = append(out, Item{Kind: AgentItem, Name: stem(f), Path: f})
+			}
+		}
+	}
+	if dir, ok := v.Find(layout.Skills); ok {
+		files, err := markdownFiles(v, dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			rel := strings.TrimPrefix(f, dir+"/")
+			parts := strings.Split(rel, "/")
+			switch {
+			case len(parts) == 2 && strings.EqualFold(parts[1], "SKILL.md"):
+				out = append(out, Item{Kind: SkillItem, Name: parts[0], Path: f})
+			case len(parts) == 3 && strings.EqualFold(parts[1], "workflows"):
+				out = append(out, Item{Kind: WorkflowItem, Name: stem(f), Path: f})
+			}
+		}
+	}
+	if dir, ok := v.Find(layout.Commands); ok {
+		files, err := markdownFiles(v, dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			out = append(out, Item{Kind: CommandItem, Name: stem(f), Path: f})
+		}
+	}
+	return out, nil
+}
+
+// markdownFiles lists the .md files below the vault-relative dir, sorted.
+// README.md files describe a folder and are not items.
+func markdownFiles(v *vault.Vault, dir string) ([]string, error) {
+	var out []string
+	err := filepath.WalkDir(v.Abs(dir), func(p string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(e.Name(), ".") && p != v.Abs(dir) {
+			if e.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(p), ".md") || strings.EqualFold(e.Name(), "README.md") {
+			return nil
+		}
+		r, err := filepath.Rel(v.Root, p)
+		if err != nil {
+			return err
+		}
+		out = append(out, filepath.ToSlash(r))
+		return nil
+	})
+	sort.Strings(out)
+	return out, err
+}
+
+func stem(p string) string {
+	return strings.TrimSuffix(path.Base(p), path.Ext(p))
+}
+
+// FindDoc returns the on-disk path of the routing table or system index:
+// the layout location, or else the first file of that name under .claude.
+func FindDoc(v *vault.Vault, key string) (string, bool) {
+	if p, ok := v.Find(key); ok {
+		return p, true
+	}
+	claude, ok := v.Find(layout.Claude)
+	if !ok {
+		return "", false
+	}
+	name := path.Base(v.Layout.Path(key))
+	files, err := markdownFiles(v, claude)
+	if err != nil {
+		return "", false
+	}
+	for _, f := range files {
+		if strings.EqualFold(path.Base(f), name) {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// LoadEntries finds the document of the layout key, ROUTING_TABLE.md or
+// SYSTEM_INDEX.md, and parses its entries. ok is false when the document
+// does not exist.
+func LoadEntries(v *vault.Vault, key string, agents []string) (entries []Entry, ok bool, err error) {
+	doc, ok := FindDoc(v, key)
+	if !ok {
+		return nil, false, nil
+	}
+	data, err := os.ReadFile(v.Abs(doc))
+	if err != nil {
+		return nil, false, err
+	}
+	return ParseEntries(v, doc, string(data), agents), true, nil
+}
+
+// Paths appear in a cell as markdown link targets, code spans or bare
+// words starting with .claude/.
+var (
+	cellLink = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]+)[^)]*\)`)
+	cellCode = regexp.MustCompile("`([^`]+)`")
+	barePath = regexp.MustCompile(`(?:^|\s)(\.?/?\.claude/\S+)`)
+)
+
+// ParseEntries reads every table row of the vault-relative doc. agents
+// are the known agent names, used to attribute rows under a heading such
+// as "## pal-master".
+func ParseEntries(v *vault.Vault, doc, text string, agents []string) []Entry {
+	lines := markdown.Lines(text)
+	heads := markdown.Headings(text)
+	var out []Entry
+	for _, t := range markdown.Tables(lines, 1) {
+		if len(t.Rows) == 0 {
+			continue
+		}
+		context := enclosing(heads, t.Rows[0].Line)
+		// The first column naming a kind ("| Workflow | Agent |") says what
+		// the rows are and holds their names; else the nearest heading says.
+		tableKind, nameCol := "", -1
+		for i, h := range t.Header {
+			if k := kindOf(h); k != "" {
+				tableKind, nameCol = k, i
+				break
+			}
+		}
+		for i := len(context) - 1; i >= 0 && tableKind == ""; i-- {
+			tableKind = kindOf(context[i])
+		}
+		if nameCol < 0 {
+			nameCol = max(t.Column("Name"), 0)
+		}
+		var headingAgents []string
+		for _, h := range context {
+			for _, a := range agents {
+				if agent.Key(h) == agent.Key(a) || strings.Contains(agent.Key(h), agent.Key(a)) {
+					headingAgents = []string{a}
+				}
+			}
+		}
+
+		agentCol := -1
+		for i, h := range t.Header {
+			if k := strings.ToLower(h); i != nameCol && (strings.Contains(k, "agent") || k == "used by" || k == "owner") {
+				agentCol = i
+				break
+			}
+		}
+
+		for _, r := range t.Rows {
+			e := Entry{Doc: doc, Line: r.Line, Kind: tableKind}
+			if nameCol < len(r.Cells) {
+				e.Name = cellName(r.Cells[nameCol])
+			}
+			for _, c := range r.Cells {
+				for _, p := range cellPaths(c) {
+					if rp, ok := resolveRef(v, doc, p); ok {
+						e.Paths = append(e.Paths, rp)
+					}
+				}
+			}
+			if e.Kind == "" {
+				for _, p := range e.Paths {
+					if k := pathKind(v, p); k != "" {
+						e.Kind = k
+						break
+					}
+				}
+			}
+			if agentCol >= 0 && agentCol < len(r.Cells) {
+				for _, a := range splitList(r.Cells[agentCol]) {
+					e.Agents = append(e.Agents, cellName(a))
+				}
+			} else {
+				e.Agents = headingAgents
+			}
+			if e.Name != "" || len(e.Paths) > 0 {
+				out = append(out, e)
+			}
+		}
+	}
+	return out
+}
+
+// enclosing returns the texts of the headings that contain line, outermost
+// first.
+func enclosing(heads []markdown.Heading, line int) []string {
+	var stack []markdown.Heading
+	for _, h := range heads {
+		if h.Line > line {
+			break
+		}
+		for len(stack) > 0 && stack[len(stack)-1].Level >= h.Level {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, h)
+	}
+	out := make([]string, len(stack))
+	for i, h := range stack {
+		out[i] = h.Text
+	}
+	return out
+}
+
+// kindOf recognises an item kind named in a heading or column header.
+func kindOf(s string) string {
+	s = strings.ToLower(s)
+	for _, k := range []string{WorkflowItem, CommandItem, SkillItem, AgentItem} {
+		if strings.Contains(s, k) {
+			return k
+		}
+	}
+	return ""
+}
+
+func pathKind(v *vault.Vault, p string) string {
+	switch {
+	case strings.Contains(p, "/workflows/"):
+		return WorkflowItem
+	case strings.HasPrefix(p, v.Layout.Path(layout.Skills)+"/"):
+		return SkillItem
+	case strings.HasPrefix(p, v.Layout.Path(layout.Agents)+"/"):
+		return AgentItem
+	case strings.HasPrefix(p, v.Layout.Path(layout.Commands)+"/"):
+		return CommandItem
+	}
+	return ""
+}
+
+// cellName returns the display name in a cell: the text of its first
+// link, or the cell without code and emphasis markers.
+func cellName(c string) string {
+	if ls := wikilink.Find(c); len(ls) > 0 {
+		if ls[0].Alias != "" {
+			return ls[0].Alias
+		}
+		return path.Base(ls[0].Target)
+	}
+	if m := cellLink.FindStringSubmatch(c); m != nil {
+		c = m[1]
+	}
+	return strings.TrimSpace(strings.Trim(c, "`*_ "))
+}
+
+func cellPaths(c string) []string {
+	var out []string
+	for _, m := range cellLink.FindAllStringSubmatch(c, -1) {
+		if !strings.Contains(m[2], "://") {
+			out = append(out, m[2])
+		}
+	}
+	for _, m := range cellCode.FindAllStringSubmatch(c, -1) {
+		if strings.Contains(m[1], "/") && (strings.HasSuffix(m[1], ".md") || strings.Contains(m[1], ".claude/")) {
+			out = append(out, m[1])
+		}
+	}
+	for _, m := range barePath.FindAllStringSubmatch(cellCode.ReplaceAllString(cellLink.ReplaceAllString(c, ""), ""), -1) {
+		out = append(out, m[1])
+	}
+	return out
+}
+
+// resolveRef turns a path written in doc into a vault-relative path. ok is
+// false for paths that cannot point into the vault, such as "~/notes".
+func resolveRef(v *vault.Vault, doc, p string) (string, bool) {
+	p = strings.TrimSuffix(strings.SplitN(p, "#", 2)[0], "/")
+	if p == "" || strings.HasPrefix(p, "~") {
+		return "", false
+	}
+	if strings.HasPrefix(p, "/") || strings.HasPrefix(p, ".claude/") || strings.HasPrefix(p, "./.claude/") {
+		return path.Clean(strings.TrimPrefix(p, "/")), true
+	}
+	rel := path.Join(path.Dir(doc), p)
+	if _, err := os.Stat(v.Abs(rel)); err == nil {
+		return rel, true
+	}
+	if _, err := os.Stat(v.Abs(path.Clean(p))); err == nil {
+		return path.Clean(p), true
+	}
+	return rel, true
+}
+
+var listSep = regexp.MustCompile(`\s*(?:,|;|<br>|\band\b)\s*`)
+
+func splitList(c string) []string {
+	var out []string
+	for _, s := range listSep.Split(c, -1) {
+		if s = strings.TrimSpace(s); s != "" && s != "-" && s != "—" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// References compares the agents, skills, workflows and commands on disk
+// with ROUTING_TABLE.md and SYSTEM_INDEX.md (requirements 1.8.1 to 1.8.3):
+//
+//   - UNREGISTERED: an item on disk that neither table lists; agents must
+//     be in ROUTING_TABLE.md in particular (requirement 1.1.5);
+//   - DEAD REFERENCE: a table row linking to a file that does not exist,
+//     or naming an item of a known kind that is not on disk;
+//   - CAPABILITY MISMATCH: a capability in an agent's Section 5 that
+//     SYSTEM_INDEX.md does not assign to the agent (agent-only), or the
+//     reverse (index-only). Skipped when SYSTEM_INDEX.md assigns nothing
+//     to any agent.
+func References(v *vault.Vault) ([]Finding, error) {
+	var out []Finding
+	add := func(kind, dir, p string, line int, msg string) {
+		out = append(out, Finding{Audit: "references", Kind: kind, Direction: dir, Path: p, Line: line, Message: msg})
+	}
+	items, err := Items(v)
+	if err != nil {
+		return nil, err
+	}
+	var agentNames []string
+	for _, it := range items {
+		if it.Kind == AgentItem {
+			agentNames = append(agentNames, it.Name)
+		}
+	}
+
+	docs := map[string][]Entry{}
+	var docNames []string
+	for _, key := range []string{layout.RoutingTable, layout.SystemIndex} {
+		name := path.Base(v.Layout.Path(key))
+		docNames = append(docNames, name)
+		entries, ok, err := LoadEntries(v, key, agentNames)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			add(MissingStructure, "", v.Layout.Path(key), 0, name+" not found; nothing can be registered in it")
+			continue
+		}
+		docs[key] = entries
+	}
+	routing, index := docs[layout.RoutingTable], docs[layout.SystemIndex]
+	all := append(append([]Entry{}, routing...), index...)
+
+	// UNREGISTERED
+	if len(docs) > 0 {
+		for _, it := range items {
+			inRouting, inIndex := Listed(it, routing), Listed(it, index)
+			switch {
+			case !inRouting && !inIndex:
+				add(Unregistered, DiskOnly, it.Path, 0, it.Kind+" \""+it.Name+"\" is in neither "+strings.Join(docNames, " nor "))
+			case it.Kind == AgentItem && !inRouting && docs[layout.RoutingTable] != nil:
+				add(Unregistered, DiskOnly, it.Path, 0, "agent \""+it.Name+"\" has no "+docNames[0]+" entry")
+			}
+		}
+	}
+
+	// DEAD REFERENCE
+	byKind := map[string]map[string]bool{}
+	for _, it := range items {
+		if byKind[it.Kind] == nil {
+			byKind[it.Kind] = map[string]bool{}
+		}
+		byKind[it.Kind][agent.Key(it.Name)] = true
+	}
+	for _, e := range all {
+		for _, p := range e.Paths {
+			if _, err := os.Stat(v.Abs(p)); err != nil {
+				add(DeadReference, IndexOnly, e.Doc, e.Line, "links to "+p+", which does not exist")
+			}
+		}
+		if len(e.Paths) == 0 && e.Kind != "" && e.Name != "" && !strings.ContainsAny(e.Name, " \t") && !byKind[e.Kind][agent.Key(e.Name)] {
+			add(DeadReference, IndexOnly, e.Doc, e.Line, "lists "+e.Kind+" \""+e.Name+"\", which does not exist on disk")
+		}
+	}
+
+	// CAPABILITY MISMATCH
+	assigned := map[string][]Entry{}
+	for _, e := range index {
+		for _, a := range e.Agents {
+			assigned[agent.Key(a)] = append(assigned[agent.Key(a)], e)
+		}
+	}
+	if len(assigned) == 0 {
+		sortFindings(out)
+		return out, nil
+	}
+	for _, it := range items {
+		if it.Kind != AgentItem {
+			continue
+		}
+		data, err := os.ReadFile(v.Abs(it.Path))
+		if err != nil {
+			return nil, err
+		}
+		a, _ := agent.Parse(it.Path, data)
+		if a == nil {
+			continue
+		}
+		entries := assigned[agent.Key(it.Name)]
+		indexKeys := map[string]bool{}
+		for _, e := range entries {
+			for _, k := range entryKeys(e) {
+				indexKeys[k] = true
+			}
+		}
+		agentKeys := map[string]bool{}
+		for _, c := range a.Capabilities {
+			ks := []string{agent.Key(c.Name), agent.Key(c.Target())}
+			agentKeys[ks[0]], agentKeys[ks[1]] = true, true
+			if !indexKeys[ks[0]] && !indexKeys[ks[1]] {
+				add(CapabilityMismatch, AgentOnly, it.Path, c.Line, "capability \""+c.Name+"\" is in Section 5 but "+docNames[1]+" does not assign it to "+it.Name)
+			}
+		}
+		for _, e := range entries {
+			found := false
+			for _, k := range entryKeys(e) {
+				found = found || agentKeys[k]
+			}
+			if !found {
+				add(CapabilityMismatch, IndexOnly, e.Doc, e.Line, docNames[1]+" assigns \""+e.Name+"\" to "+it.Name+", whose Section 5 does not list it")
+			}
+		}
+	}
+	sortFindings(out)
+	return out, nil
+}
+
+// Listed reports whether any entry links to the item's file (or, for a
+// skill, its folder) or names it.
+func Listed(it Item, entries []Entry) bool {
+	dir := path.Dir(it.Path)
+	for _, e := range entries {
+		for _, p := range e.Paths {
+			if p == it.Path || (it.Kind == SkillItem && p == dir) {
+				return true
+			}
+		}
+		if (e.Kind == "" || e.Kind == it.Kind) && agent.Key(e.Name) == agent.Key(it.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func entryKeys(e Entry) []string {
+	keys := []string{agent.Key(e.Name)}
+	for _, p := range e.Paths {
+		c := agent.Capability{Location: p}
+		keys = append(keys, agent.Key(c.Target()))
+	}
+	return keys
+}
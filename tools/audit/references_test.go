@@ -0,0 +1,80 @@
+package audit
+
+import "testing"
+
+func TestReferences(t *testing.T) {
+	v := load(t, map[string]string{
+		".claude/agents/pal-master.md": "---\nname: pal-master\n---\n\n## 5. My Capabilities\n\n" +
+			"- name: note-taking\n  location: .claude/skills/note-taking/SKILL.md\n" +
+			"- name: pull_tasks\n  location: .claude/skills/pm/workflows/pull_tasks.md\n",
+		".claude/agents/helper.md":                  "---\nname: helper\n---\n",
+		".claude/skills/note-taking/SKILL.md":       "",
+		".claude/skills/pm/SKILL.md":                "",
+		".claude/skills/pm/workflows/pull_tasks.md": "",
+		".claude/skills/orphan/SKILL.md":            "",
+		".claude/skills/orphan/README.md":           "",
+		".claude/commands/sync.md":                  "",
+		".claude/core/system/ROUTING_TABLE.md": "# Routing\n\n## Agents\n\n| Agent | Location |\n|---|---|\n" +
+			"| pal-master | `.claude/agents/pal-master.md` |\n| ghost | `.claude/agents/ghost.md` |\n\n" +
+			"## Commands\n\n| Command | Purpose |\n|---|---|\n| /sync | Sync tasks |\n| nope | Gone |\n| Run it all | Prose |\n",
+		".claude/core/system/SYSTEM_INDEX.md": "# Index\n\n## Skills\n\n| Skill | Location | Agent |\n|---|---|---|\n" +
+			"| note-taking | .claude/skills/note-taking/SKILL.md | pal-master |\n| pm | [SKILL.md](../../skills/pm/SKILL.md) | pal-master |\n\n" +
+			"## Workflows\n\n| Workflow | Location |\n|---|---|\n| pull_tasks | .claude/skills/pm/workflows/pull_tasks.md |\n",
+	})
+	got, err := References(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "References", got, []want{
+		{Unregistered, ".claude/agents/helper.md", 0},
+		{CapabilityMismatch, ".claude/agents/pal-master.md", 9},
+		{DeadReference, ".claude/core/system/ROUTING_TABLE.md", 8},
+		{DeadReference, ".claude/core/system/ROUTING_TABLE.md", 15},
+		{CapabilityMismatch, ".claude/core/system/SYSTEM_INDEX.md", 8},
+		{Unregistered, ".claude/skills/orphan/SKILL.md", 0},
+	})
+	for _, f := range got {
+		switch f.Kind {
+		case Unregistered:
+			if f.Direction != DiskOnly {
+				t.Errorf("%s %s is %s", f.Kind, f.Path, f.Direction)
+			}
+		case CapabilityMismatch:
+			if f.Path == ".claude/agents/pal-master.md" && f.Direction != AgentOnly {
+				t.Errorf("%s %s is %s", f.Kind, f.Path, f.Direction)
+			}
+		}
+	}
+}
+
+func TestReferencesWithoutDocs(t *testing.T) {
+	v := load(t, map[string]string{".claude/agents/pal-master.md": ""})
+	got, err := References(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "References", got, []want{
+		{MissingStructure, ".claude/core/system/ROUTING_TABLE.md", 0},
+		{MissingStructure, ".claude/core/system/SYSTEM_INDEX.md", 0},
+	})
+}
+
+func TestParseEntries(t *testing.T) {
+	v := load(t, map[string]string{".claude/skills/pm/SKILL.md": ""})
+	text := "## pal-master\n\n| Name | Path |\n|---|---|\n| [[pm\\|Project Management]] | [pm](../skills/pm/) |\n| Home | ~/notes.md |\n"
+	got := ParseEntries(v, ".claude/core/INDEX.md", text, []string{"pal-master"})
+	if len(got) != 2 {
+		t.Fatalf("ParseEntries = %+v", got)
+	}
+	e := got[0]
+	if e.Name != "Project Management" || e.Kind != SkillItem || e.Line != 5 ||
+		len(e.Paths) != 1 || e.Paths[0] != ".claude/skills/pm" || len(e.Agents) != 1 || e.Agents[0] != "pal-master" {
+		t.Errorf("entry = %+v", e)
+	}
+	if !Listed(Item{Kind: SkillItem, Name: "pm", Path: ".claude/skills/pm/SKILL.md"}, got) {
+		t.Error("skill linked by folder is not listed")
+	}
+	if e := got[1]; e.Name != "Home" || len(e.Paths) != 0 {
+		t.Errorf("home-relative entry = %+v", e)
+	}
+}
@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"sort"
+	"strings"
+)
+
+// SARIF 2.1.0, as far as code-scanning viewers need it: one run, one rule
+// per finding kind, one result per finding.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysical `json:"physicalLocation"`
+}
+
+type sarifPhysical struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// RuleID names a finding kind for SARIF: "references/dead-reference".
+func RuleID(f Finding) string {
+	return f.Audit + "/" + strings.ReplaceAll(strings.ToLower(f.Kind), " ", "-")
+}
+
+// SARIF converts r to a SARIF 2.1.0 log. Paths stay vault-relative, so
+// viewers resolve them against the vault root.
+func (r *Report) SARIF() any {
+	rules := map[string]sarifRule{}
+	results := []sarifResult{}
+	for _, res := range r.Results {
+		for _, f := range res.Findings {
+			id := RuleID(f)
+			rules[id] = sarifRule{ID: id, Name: f.Kind, ShortDescription: sarifMessage{Text: f.Audit + " audit: " + f.Kind}}
+			loc := sarifLocation{PhysicalLocation: sarifPhysical{ArtifactLocation: sarifArtifact{URI: f.Path}}}
+			if f.Line > 0 {
+				loc.PhysicalLocation.Region = &sarifRegion{StartLine: f.Line}
+			}
+			msg := f.Message
+			if f.Direction != "" {
+				msg += " (" + f.Direction + ")"
+			}
+			if f.Fix != "" {
+				msg += "; fix: " + f.Fix
+			}
+			results = append(results, sarifResult{RuleID: id, Level: "warning", Message: sarifMessage{Text: msg}, Locations: []sarifLocation{loc}})
+		}
+	}
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	driver := sarifDriver{Name: "pal", Rules: []sarifRule{}}
+	for _, id := range ids {
+		driver.Rules = append(driver.Rules, rules[id])
+	}
+	return sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+}
@@ -0,0 +1,392 @@
+// Package changeset is the plan-first way to change a vault (requirement
+// 0.1.6). A tool collects the files it means to create, rewrite, move or
+// delete in a Set, shows the plan — Objective, Steps, Files affected
+// marked NEW/MODIFY/MOVE/DELETE, and Risks — and applies it only once the
+// user has approved, after writing a journal that undoes it.
+//
+// Nothing touches the disk until Apply, so a declined or dry-run plan
+// leaves the vault exactly as it was.
+package changeset
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/frontmatter"
+)
+
+// Op is what a change does to its file, as the plan labels it.
+type Op string
+
+const (
+	OpNew    Op = "NEW"
+	OpModify Op = "MODIFY"
+	OpMove   Op = "MOVE"
+	OpDelete Op = "DELETE"
+)
+
+// Change is one file operation. Path is vault-relative: where the file
+// is once the set's moves have run. A folder's Path ends in '/'.
+type Change struct {
+	Op   Op     `json:"op"`
+	Path string `json:"path"`
+	// From is where a MOVE takes the file or folder from.
+	From string `json:"from,omitempty"`
+	// Why says what the change is for; it fills the plan's Files table.
+	Why  string `json:"why,omitempty"`
+	data []byte
+}
+
+// Set collects the changes of one operation.
+type Set struct {
+	Objective string   `json:"objective"`
+	Steps     []string `json:"steps"`
+	Changes   []Change `json:"changes"`
+	Risks     []string `json:"risks"`
+
+	root  string
+	index map[string]int // path to position in Changes
+}
+
+// New starts an empty set for the vault at root.
+func New(root, objective string) *Set {
+	return &Set{Objective: objective, root: root, index: map[string]int{}}
+}
+
+// Step appends a numbered step to the plan.
+func (s *Set) Step(format string, args ...any) {
+	s.Steps = append(s.Steps, fmt.Sprintf(format, args...))
+}
+
+// Risk appends a risk to the plan.
+func (s *Set) Risk(format string, args ...any) {
+	s.Risks = append(s.Risks, fmt.Sprintf(format, args...))
+}
+
+// Len returns the number of changes.
+func (s *Set) Len() int { return len(s.Changes) }
+
+// Create plans a new file. It fails when the file exists, ignoring case,
+// or is already part of the set.
+func (s *Set) Create(p string, data []byte, why string) error {
+	p = path.Clean(p)
+	if _, ok := s.index[p]; ok {
+		return fmt.Errorf("%s: already in the plan", p)
+	}
+	if s.taken(p) {
+		return fmt.Errorf("%s: already exists", p)
+	}
+	s.add(Change{Op: OpNew, Path: p, Why: why, data: data})
+	return nil
+}
+
+// Modify plans new content for an existing file, or for a file the set
+// already creates. Content identical to what is there is not a change.
+// The "## Notes" tail of a note is protected: content that would alter it
+// is refused with frontmatter.ErrProtected.
+func (s *Set) Modify(p string, data []byte, why string) error {
+	p = path.Clean(p)
+	if i, ok := s.index[p]; ok {
+		switch c := &s.Changes[i]; c.Op {
+		case OpDelete:
+			return fmt.Errorf("%s: already deleted in the plan", p)
+		case OpNew:
+			c.data = data
+			return nil
+		}
+	}
+	old, err := s.read(p)
+	if err != nil {
+		return err
+	}
+	if err := frontmatter.CheckProtected(old, data); err != nil {
+		return fmt.Errorf("%s: %w", p, err)
+	}
+	if i, ok := s.index[p]; ok {
+		s.Changes[i].data = data
+		return nil
+	}
+	if bytes.Equal(old, data) {
+		return nil
+	}
+	s.add(Change{Op: OpModify, Path: p, Why: why, data: data})
+	return nil
+}
+
+// Delete plans removing an existing file. Deleting a note with a
+// "## Notes" section adds a risk, since the user's notes go with it.
+func (s *Set) Delete(p, why string) error {
+	p = path.Clean(p)
+	if _, ok := s.index[p]; ok {
+		return fmt.Errorf("%s: already in the plan", p)
+	}
+	old, err := s.read(p)
+	if err != nil {
+		return err
+	}
+	if _, ok := frontmatter.ProtectedTail(old); ok {
+		s.Risk("%s has a ## Notes section, which is deleted with it", p)
+	}
+	s.add(Change{Op: OpDelete, Path: p, Why: why})
+	return nil
+}
+
+// DeleteDir plans removing a folder that the set's moves and deletes leave
+// empty. Apply removes it last, and fails if anything is left in it.
+func (s *Set) DeleteDir(p, why string) error {
+	p = path.Clean(p)
+	if _, ok := s.index[p+"/"]; ok {
+		return fmt.Errorf("%s/: already in the plan", p)
+	}
+	if info, err := os.Stat(s.abs(s.source(p))); err != nil || !info.IsDir() || s.final(s.source(p)) != p {
+		return fmt.Errorf("%s: not a folder", p)
+	}
+	s.add(Change{Op: OpDelete, Path: p + "/", Why: why})
+	return nil
+}
+
+// Move plans renaming the file or folder from to to. Moves run before
+// every other change, in the order planned, so from may be where an
+// earlier move put it. It fails when from does not exist, or when to is
+// taken, ignoring case, on disk or by another change of the set: two
+// sources must never land on one name.
+func (s *Set) Move(from, to, why string) error {
+	from, to = path.Clean(from), path.Clean(to)
+	if !s.exists(from) {
+		return fmt.Errorf("cannot move %s: not found", from)
+	}
+	if from == to {
+		return nil
+	}
+	if !strings.EqualFold(from, to) && s.taken(to) {
+		return fmt.Errorf("cannot move %s: %s already exists", from, to)
+	}
+	for _, c := range s.Changes {
+		if c.Op != OpMove && (c.Path == from || strings.HasPrefix(c.Path, from+"/")) {
+			return fmt.Errorf("cannot move %s: plan its moves before its other changes", from)
+		}
+	}
+	s.Changes = append(s.Changes, Change{Op: OpMove, Path: to, From: from, Why: why})
+	return nil
+}
+
+func (s *Set) add(c Change) {
+	s.index[c.Path] = len(s.Changes)
+	s.Changes = append(s.Changes, c)
+}
+
+func (s *Set) abs(p string) string {
+	return filepath.Join(s.root, filepath.FromSlash(p))
+}
+
+// final returns where the vault-relative p ends up after the moves.
+func (s *Set) final(p string) string {
+	for _, c := range s.Changes {
+		if c.Op == OpMove {
+			p = moved(p, c.From, c.Path)
+		}
+	}
+	return p
+}
+
+// source returns where the file that ends up at p is before the moves.
+func (s *Set) source(p string) string {
+	for i := len(s.Changes) - 1; i >= 0; i-- {
+		if c := s.Changes[i]; c.Op == OpMove {
+			p = moved(p, c.Path, c.From)
+		}
+	}
+	return p
+}
+
+func moved(p, from, to string) string {
+	switch {
+	case p == from:
+		return to
+	case strings.HasPrefix(p, from+"/"):
+		return to + p[len(from):]
+	}
+	return p
+}
+
+// exists reports whether a file or folder is at p once the moves have run.
+func (s *Set) exists(p string) bool {
+	src := s.source(p)
+	if s.final(src) != p {
+		return false // moved away
+	}
+	_, err := os.Stat(s.abs(src))
+	return err == nil
+}
+
+// taken reports whether p, ignoring case, names a file or folder once the
+// moves have run, or one the set creates.
+func (s *Set) taken(p string) bool {
+	for _, c := range s.Changes {
+		if (c.Op == OpNew || c.Op == OpMove) && strings.EqualFold(c.Path, p) {
+			return true
+		}
+	}
+	src := s.source(p)
+	dir := path.Dir(src)
+	entries, err := os.ReadDir(s.abs(dir))
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		if strings.EqualFold(e.Name(), path.Base(src)) && strings.EqualFold(s.final(path.Join(dir, e.Name())), p) {
+			return true
+		}
+	}
+	return false
+}
+
+// read returns the content of the file that ends up at p.
+func (s *Set) read(p string) ([]byte, error) {
+	if !s.exists(p) {
+		return nil, fmt.Errorf("%s: %w", p, fs.ErrNotExist)
+	}
+	return os.ReadFile(s.abs(s.source(p)))
+}
+
+// Markdown renders the plan for review, in Obsidian or on a terminal.
+func (s *Set) Markdown(created time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "---\ntype: plan\nstatus: proposed\ncreated: %s\n---\n\n", created.Format("2006-01-02"))
+	fmt.Fprintf(&b, "# Plan: %s\n\n## Objective\n\n%s\n\n## Steps\n\n", s.Objective, s.Objective)
+	steps := s.Steps
+	if len(steps) == 0 {
+		steps = []string{"Apply the changes below."}
+	}
+	for i, st := range steps {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, st)
+	}
+	b.WriteString("\n## Files Affected\n\n")
+	if len(s.Changes) == 0 {
+		b.WriteString("None.\n")
+	} else {
+		b.WriteString("| Change | File | Why |\n|--------|------|-----|\n")
+		for _, c := range s.sorted() {
+			file := "`" + c.Path + "`"
+			if c.Op == OpMove {
+				file = "`" + c.From + "` → " + file
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", c.Op, file, strings.ReplaceAll(c.Why, "|", `\|`))
+		}
+	}
+	b.WriteString("\n## Risks\n\n")
+	if len(s.Risks) == 0 {
+		b.WriteString("None identified.\n")
+	}
+	for _, r := range s.Risks {
+		fmt.Fprintf(&b, "- %s\n", r)
+	}
+	return []byte(b.String())
+}
+
+// sorted returns the changes in path order, the way the plan lists them.
+func (s *Set) sorted() []Change {
+	out := append([]Change(nil), s.Changes...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// ordered returns the changes in the order Apply makes them: the moves as
+// planned, then files in path order, then folders deepest first.
+func (s *Set) ordered() []Change {
+	var moves, files, dirs []Change
+	for _, c := range s.Changes {
+		if c.Op == OpMove {
+			moves = append(moves, c)
+		}
+	}
+	for _, c := range s.sorted() {
+		switch {
+		case c.Op == OpMove:
+		case strings.HasSuffix(c.Path, "/"):
+			dirs = append(dirs, c)
+		default:
+			files = append(files, c)
+		}
+	}
+	sort.SliceStable(dirs, func(i, j int) bool { return strings.Count(dirs[i].Path, "/") > strings.Count(dirs[j].Path, "/") })
+	return append(append(moves, files...), dirs...)
+}
+
+var nonName = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// PlanName returns the file name of the saved plan, PLAN_<OBJECTIVE>_<TIME>.md,
+// which follows the project-file naming convention.
+func (s *Set) PlanName(created time.Time) string {
+	name := strings.Trim(nonName.ReplaceAllString(strings.ToUpper(s.Objective), "_"), "_")
+	if words := strings.Split(name, "_"); len(words) > 6 {
+		name = strings.Join(words[:6], "_")
+	}
+	if name == "" {
+		name = "CHANGES"
+	}
+	return "PLAN_" + name + "_" + created.Format("20060102_150405") + ".md"
+}
+
+// Apply makes the changes. Callers save s.Journal() first so that a
+// failure halfway can be undone.
+func (s *Set) Apply() error {
+	for _, c := range s.ordered() {
+		abs := s.abs(c.Path)
+		switch c.Op {
+		case OpMove:
+			if err := move(s.root, c.From, c.Path); err != nil {
+				return err
+			}
+		case OpNew:
+			if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(abs, c.data, 0o644); err != nil {
+				return err
+			}
+		case OpModify:
+			info, err := os.Stat(abs)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(abs, c.data, info.Mode().Perm()); err != nil {
+				return err
+			}
+		case OpDelete:
+			if err := os.Remove(abs); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// move renames the vault-relative path from to to under root, creating
+// to's parent folders as needed.
+func move(root, from, to string) error {
+	src := filepath.Join(root, filepath.FromSlash(from))
+	dst := filepath.Join(root, filepath.FromSlash(to))
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	if strings.EqualFold(from, to) {
+		// Case-insensitive filesystems need a detour for case-only
+		// renames.
+		tmp := dst + ".rename"
+		if err := os.Rename(src, tmp); err != nil {
+			return err
+		}
+		src = tmp
+	}
+	return os.Rename(src, dst)
+}
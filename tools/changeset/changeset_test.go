@@ -0,0 +1,304 @@
+package changeset
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/frontmatter"
+)
+
+// vault writes files, keyed by slash path, under a new temporary root.
+func vault(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for p, data := range files {
+		abs := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(abs, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// contents returns every file under root by slash path.
+func contents(t *testing.T, root string) map[string]string {
+	t.Helper()
+	out := map[string]string{}
+	err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		rel, _ := filepath.Rel(root, p)
+		out[filepath.ToSlash(rel)] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func dirs(t *testing.T, root string) []string {
+	t.Helper()
+	var out []string
+	filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if d.IsDir() && p != root {
+			rel, _ := filepath.Rel(root, p)
+			out = append(out, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(out)
+	return out
+}
+
+// applyAndUndo applies set with its journal, checks the result, then
+// undoes it and checks the vault is back as it was.
+func applyAndUndo(t *testing.T, root string, set *Set, want map[string]string) {
+	t.Helper()
+	before, beforeDirs := contents(t, root), dirs(t, root)
+	j, err := set.Journal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if got := contents(t, root); !reflect.DeepEqual(got, want) {
+		t.Fatalf("after Apply:\ngot  %q\nwant %q", got, want)
+	}
+	if changed := j.Changed(root); len(changed) > 0 {
+		t.Fatalf("Changed right after Apply = %q", changed)
+	}
+	if err := j.Undo(root, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := contents(t, root); !reflect.DeepEqual(got, before) {
+		t.Fatalf("after Undo:\ngot  %q\nwant %q", got, before)
+	}
+	if got := dirs(t, root); !reflect.DeepEqual(got, beforeDirs) {
+		t.Fatalf("folders after Undo = %q, want %q", got, beforeDirs)
+	}
+}
+
+func TestApplyAndUndo(t *testing.T) {
+	root := vault(t, map[string]string{
+		"a.md":      "old a\n",
+		"b.md":      "old b\n",
+		"gone.md":   "bye\n",
+		"keep/x.md": "x\n",
+	})
+	set := New(root, "Test")
+	if err := set.Create("new/deep/c.md", []byte("c\n"), "new"); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Modify("a.md", []byte("new a\n"), "edit"); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Modify("b.md", []byte("old b\n"), "same"); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Delete("gone.md", "drop"); err != nil {
+		t.Fatal(err)
+	}
+	if set.Len() != 3 {
+		t.Fatalf("Len = %d, want 3: identical content is no change", set.Len())
+	}
+	applyAndUndo(t, root, set, map[string]string{
+		"a.md":          "new a\n",
+		"b.md":          "old b\n",
+		"keep/x.md":     "x\n",
+		"new/deep/c.md": "c\n",
+	})
+}
+
+func TestMoves(t *testing.T) {
+	root := vault(t, map[string]string{
+		"old/note.md":     "note\n",
+		"old/.gitkeep":    "",
+		"old/sub/deep.md": "deep\n",
+		"new/.gitkeep":    "",
+		"file.md":         "file\n",
+	})
+	set := New(root, "Test")
+	for _, m := range [][2]string{
+		{"old/sub/deep.md", "old/sub/DEEP.md"}, // deeper first
+		{"old/note.md", "new/note.md"},
+		{"old/sub", "new/sub"},
+		{"file.md", "archive/2026/file.md"},
+	} {
+		if err := set.Move(m[0], m[1], "move"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Later changes name files where they end up.
+	if err := set.Modify("new/sub/DEEP.md", []byte("deeper\n"), "edit"); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Create("new/sub/tools/.gitkeep", nil, "keep"); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Delete("old/.gitkeep", "placeholder"); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.DeleteDir("old", "emptied"); err != nil {
+		t.Fatal(err)
+	}
+	applyAndUndo(t, root, set, map[string]string{
+		"new/.gitkeep":           "",
+		"new/note.md":            "note\n",
+		"new/sub/DEEP.md":        "deeper\n",
+		"new/sub/tools/.gitkeep": "",
+		"archive/2026/file.md":   "file\n",
+	})
+}
+
+func TestMoveCollisions(t *testing.T) {
+	root := vault(t, map[string]string{
+		"goals.md":         "goals\n",
+		"project_goals.md": "project goals\n",
+		"other.md":         "other\n",
+		"Taken.md":         "taken\n",
+	})
+	set := New(root, "Test")
+	if err := set.Move("goals.md", "PROJECT_GOALS.md", ""); err == nil {
+		t.Error("Move onto an existing file in another case succeeded")
+	}
+	if err := set.Move("project_goals.md", "PROJECT_GOALS.md", "case only"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct{ from, to string }{
+		{"goals.md", "PROJECT_GOALS.md"}, // the target of another move
+		{"goals.md", "Project_Goals.md"},
+		{"other.md", "taken.md"},
+		{"missing.md", "x.md"},
+		{"project_goals.md", "x.md"}, // moved away already
+	}
+	for _, tt := range tests {
+		if err := set.Move(tt.from, tt.to, ""); err == nil {
+			t.Errorf("Move(%s, %s) succeeded", tt.from, tt.to)
+		}
+	}
+	if err := set.Move("Taken.md", "TAKEN.md", "case only"); err != nil {
+		t.Errorf("case-only Move: %v", err)
+	}
+	if err := set.Create("project_goals.md", nil, ""); err == nil {
+		t.Error("Create over a move target in another case succeeded")
+	}
+}
+
+func TestModifyProtected(t *testing.T) {
+	root := vault(t, map[string]string{"n.md": "body\n\n## Notes\n\nmine\n"})
+	set := New(root, "Test")
+	err := set.Modify("n.md", []byte("body\n\n## Notes\n\nedited\n"), "")
+	if !errors.Is(err, frontmatter.ErrProtected) {
+		t.Errorf("Modify of the Notes tail = %v, want ErrProtected", err)
+	}
+	if err := set.Delete("n.md", ""); err != nil || len(set.Risks) != 1 {
+		t.Errorf("Delete = %v with risks %q, want one risk", err, set.Risks)
+	}
+}
+
+func TestUndoRefusesChangedFiles(t *testing.T) {
+	root := vault(t, map[string]string{"a.md": "a\n", "gone.md": "gone\n"})
+	set := New(root, "Test")
+	set.Modify("a.md", []byte("applied\n"), "")
+	set.Create("c.md", []byte("c\n"), "")
+	set.Delete("gone.md", "")
+	j, err := set.Journal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(root, "a.md"), []byte("edited by hand\n"), 0o644)
+	os.WriteFile(filepath.Join(root, "gone.md"), []byte("recreated\n"), 0o644)
+
+	if got, want := j.Changed(root), []string{"a.md", "gone.md"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Changed = %q, want %q", got, want)
+	}
+	err = j.Undo(root, false)
+	if err == nil || !strings.Contains(err.Error(), "a.md") {
+		t.Fatalf("Undo = %v, want a refusal naming a.md", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "a.md")); string(data) != "edited by hand\n" {
+		t.Errorf("refused Undo still wrote a.md: %q", data)
+	}
+	if err := j.Undo(root, true); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := contents(t, root), map[string]string{"a.md": "a\n", "gone.md": "gone\n"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("forced Undo left %q, want %q", got, want)
+	}
+}
+
+func TestJournalNames(t *testing.T) {
+	root := vault(t, map[string]string{"a.md": "a\n"})
+	dir := t.TempDir()
+	seen := map[string]bool{}
+	for i := 0; i < 5; i++ {
+		set := New(root, "Test")
+		set.Modify("a.md", []byte{byte('0' + i)}, "")
+		j, err := set.Journal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[j.Name()] {
+			t.Fatalf("journal name %s repeated", j.Name())
+		}
+		seen[j.Name()] = true
+		if err := j.Save(filepath.Join(dir, j.Name())); err != nil {
+			t.Fatal(err)
+		}
+		if err := j.Save(filepath.Join(dir, j.Name())); err == nil {
+			t.Fatal("Save replaced an existing journal")
+		}
+		latest, err := LatestJournal(dir)
+		if err != nil || filepath.Base(latest) != j.Name() {
+			t.Fatalf("LatestJournal = %s, %v; want %s", latest, err, j.Name())
+		}
+	}
+}
+
+func TestUndoBinaryFiles(t *testing.T) {
+	img := string([]byte{0x89, 'P', 'N', 'G', 0xff, 0x00, 0xfe, '\n'})
+	root := vault(t, map[string]string{"logo.png": img, "deck.pdf": "%PDF\xe2\x28\xa1"})
+	set := New(root, "Test")
+	if err := set.Modify("logo.png", []byte("replaced"), "edit"); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Delete("deck.pdf", "drop"); err != nil {
+		t.Fatal(err)
+	}
+	j, err := set.Journal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Through the file, as pal undo reads it.
+	path := filepath.Join(t.TempDir(), j.Name())
+	if err := j.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if j, err = ReadJournal(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Undo(root, false); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := contents(t, root), map[string]string{"logo.png": img, "deck.pdf": "%PDF\xe2\x28\xa1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after Undo %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,221 @@
+package changeset
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Journal records what a Set is about to change so that Undo can put the
+// vault back. Save it before Apply.
+type Journal struct {
+	Created   time.Time      `json:"created"`
+	Objective string         `json:"objective"`
+	Files     []JournalEntry `json:"files"`
+}
+
+// JournalEntry is one change, in the order Apply makes it. Content is the
+// file before the change, base64 in the JSON so that binary files come
+// back intact; it is empty for NEW files, which Undo removes, and for
+// moves and folders.
+type JournalEntry struct {
+	Op      Op     `json:"op"`
+	Path    string `json:"path"`
+	From    string `json:"from,omitempty"`
+	Content []byte `json:"content,omitempty"`
+	Mode    uint32 `json:"mode,omitempty"`
+	// After is the SHA-256 of what Apply writes, so that Undo can tell
+	// whether the file changed since.
+	After string `json:"after,omitempty"`
+	// Dirs are the folders Apply creates for the change, deepest first.
+	// Undo removes them once they are empty again.
+	Dirs []string `json:"dirs,omitempty"`
+}
+
+// Journal captures the current content of every file s changes.
+func (s *Set) Journal() (*Journal, error) {
+	j := &Journal{Created: time.Now().UTC(), Objective: s.Objective}
+	made := map[string]bool{}
+	for _, c := range s.ordered() {
+		e := JournalEntry{Op: c.Op, Path: c.Path, From: c.From}
+		switch {
+		case c.Op == OpMove:
+			e.Dirs = s.newDirs(c.Path, made)
+		case c.Op == OpNew:
+			e.After = hash(c.data)
+			e.Dirs = s.newDirs(c.Path, made)
+		case strings.HasSuffix(c.Path, "/"):
+		default:
+			src := s.abs(s.source(c.Path))
+			info, err := os.Stat(src)
+			if err != nil {
+				return nil, err
+			}
+			data, err := os.ReadFile(src)
+			if err != nil {
+				return nil, err
+			}
+			e.Content, e.Mode = data, uint32(info.Mode().Perm())
+			if c.Op == OpModify {
+				e.After = hash(c.data)
+			}
+		}
+		j.Files = append(j.Files, e)
+	}
+	return j, nil
+}
+
+// newDirs returns the missing parent folders of p, deepest first, that no
+// earlier change creates.
+func (s *Set) newDirs(p string, made map[string]bool) []string {
+	var out []string
+	for dir := path.Dir(p); dir != "." && dir != "/" && !made[dir] && !s.exists(dir); dir = path.Dir(dir) {
+		out = append(out, dir)
+		made[dir] = true
+	}
+	return out
+}
+
+func hash(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// Name returns the journal's file name for its folder in the layout's
+// state, e.g. "changes-20261018-143000.123456789.json". Names sort by time
+// and differ between applies made within the same second.
+func (j *Journal) Name() string {
+	return "changes-" + j.Created.Format("20060102-150405.000000000") + ".json"
+}
+
+// ReadJournal loads a journal written with Save.
+func ReadJournal(path string) (*Journal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var j Journal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &j, nil
+}
+
+// LatestJournal returns the newest journal in dir, or "" when there is
+// none.
+func LatestJournal(dir string) (string, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "changes-*.json"))
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", nil
+	}
+	sort.Strings(names)
+	return names[len(names)-1], nil
+}
+
+// Save writes j to path as JSON, creating its folder. It never replaces
+// an existing file, which holds another journal.
+func (j *Journal) Save(path string) error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Changed returns the files under root that changed since the set was
+// applied: rewritten or removed after Apply wrote them, recreated after
+// Apply deleted them, or moved again. Undo would lose those changes.
+func (j *Journal) Changed(root string) []string {
+	var out []string
+	for i, e := range j.Files {
+		abs := filepath.Join(root, filepath.FromSlash(e.Path))
+		switch {
+		case e.Op == OpMove:
+			// Later moves may carry the file along with its folder.
+			to, from := e.Path, e.From
+			for _, l := range j.Files[i+1:] {
+				if l.Op == OpMove {
+					to, from = moved(to, l.From, l.Path), moved(from, l.From, l.Path)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(to))); err != nil {
+				out = append(out, to)
+			} else if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(from))); err == nil && !strings.EqualFold(from, to) {
+				out = append(out, from)
+			}
+		case strings.HasSuffix(e.Path, "/"):
+		case e.Op == OpDelete:
+			if _, err := os.Stat(abs); err == nil {
+				out = append(out, e.Path)
+			}
+		case e.After != "":
+			if data, err := os.ReadFile(abs); err != nil || hash(data) != e.After {
+				out = append(out, e.Path)
+			}
+		}
+	}
+	return out
+}
+
+// Undo reverts an applied set under root: it removes the files it created,
+// restores the ones it modified or deleted and moves everything back. It
+// refuses when files changed since the apply, unless force.
+func (j *Journal) Undo(root string, force bool) error {
+	if changed := j.Changed(root); len(changed) > 0 && !force {
+		return fmt.Errorf("changed since the apply, so undo would overwrite them: %s", strings.Join(changed, ", "))
+	}
+	for i := len(j.Files) - 1; i >= 0; i-- {
+		e := j.Files[i]
+		abs := filepath.Join(root, filepath.FromSlash(e.Path))
+		switch {
+		case e.Op == OpMove:
+			if err := move(root, e.Path, e.From); err != nil {
+				return fmt.Errorf("moving %s back to %s: %w", e.Path, e.From, err)
+			}
+		case strings.HasSuffix(e.Path, "/"):
+			if err := os.MkdirAll(abs, 0o755); err != nil {
+				return err
+			}
+		case e.Op == OpNew:
+			if err := os.Remove(abs); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		default:
+			mode := fs.FileMode(e.Mode)
+			if mode == 0 {
+				mode = 0o644
+			}
+			if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(abs, e.Content, mode); err != nil {
+				return err
+			}
+		}
+		for _, d := range e.Dirs {
+			os.Remove(filepath.Join(root, filepath.FromSlash(d))) // fails, as it should, unless empty
+		}
+	}
+	return nil
+}
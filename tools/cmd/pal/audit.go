@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/audit"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/changeset"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/frontmatter"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/rename"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+func runAuditDomains(e *env, args []string) error {
+	fs := newFlags(e, "audit domains")
+	asJSON := fs.Bool("json", false, "print the findings as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	domains, err := pickDomains(v, fs.Args())
+	if err != nil {
+		return err
+	}
+	findings, err := audit.Domains(v, domains)
+	if err != nil {
+		return err
+	}
+	return printFindings(e, findings, *asJSON)
+}
+
+func runAuditLinks(e *env, args []string) error {
+	fs := newFlags(e, "audit links")
+	asJSON := fs.Bool("json", false, "print the findings as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	files, err := vaultPaths(v, fs.Args())
+	if err != nil {
+		return err
+	}
+	findings, err := audit.Links(v, files)
+	if err != nil {
+		return err
+	}
+	return printFindings(e, findings, *asJSON)
+}
+
+// vaultPaths turns file arguments, relative to the working directory, into
+// vault-relative paths. It returns nil for no arguments.
+func vaultPaths(v *vault.Vault, args []string) ([]string, error) {
+	var out []string
+	for _, a := range args {
+		abs, err := filepath.Abs(a)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(v.Root, abs)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return nil, usagef("%s is outside the vault", a)
+		}
+		out = append(out, filepath.ToSlash(rel))
+	}
+	return out, nil
+}
+
+// printFindings writes audit findings as text or JSON and turns any
+// finding into exit status 1.
+func printFindings(e *env, findings []audit.Finding, asJSON bool) error {
+	if asJSON {
+		if findings == nil {
+			findings = []audit.Finding{}
+		}
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(findings); err != nil {
+			return err
+		}
+	} else {
+		for _, f := range findings {
+			fmt.Fprintf(e.stdout, "%s: %s\n", findingWhere(f), findingText(f))
+		}
+	}
+	if len(findings) > 0 {
+		return exitCode(1)
+	}
+	return nil
+}
+
+// findingWhere returns "path" or "path:line".
+func findingWhere(f audit.Finding) string {
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d", f.Path, f.Line)
+	}
+	return f.Path
+}
+
+// findingText returns "KIND (direction): message (fix: ...)".
+func findingText(f audit.Finding) string {
+	kind := f.Kind
+	if f.Direction != "" {
+		kind += " (" + f.Direction + ")"
+	}
+	if f.Fix != "" {
+		return kind + ": " + f.Message + " (fix: " + f.Fix + ")"
+	}
+	return kind + ": " + f.Message
+}
+
+func runAuditReferences(e *env, args []string) error {
+	fs := newFlags(e, "audit references")
+	asJSON := fs.Bool("json", false, "print the findings as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("unexpected arguments: %v", fs.Args())
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	findings, err := audit.References(v)
+	if err != nil {
+		return err
+	}
+	return printFindings(e, findings, *asJSON)
+}
+
+func runAuditOrphans(e *env, args []string) error {
+	fs := newFlags(e, "audit orphans")
+	days := fs.Int("days", audit.DefaultStaleDays, "report sessions older than this many `days`")
+	archive := fs.Bool("archive", false, "move stale sessions to 05_ARCHIVE/ with a deprecation header and rewrite links to them")
+	a := approvalFlags(fs)
+	asJSON := fs.Bool("json", false, "print the findings as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *days < 0 {
+		return usagef("-days must not be negative")
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	findings, stale, err := audit.Orphans(v, now, *days)
+	if err != nil {
+		return err
+	}
+	if !*archive || len(stale) == 0 {
+		return printFindings(e, findings, *asJSON)
+	}
+	if err := printFindings(e, findings, *asJSON); err != nil && !errors.As(err, new(exitCode)) {
+		return err
+	}
+
+	var moves []rename.Move
+	for _, s := range stale {
+		moves = append(moves, rename.Move{From: s.Path, To: s.Archive})
+	}
+	plan, err := rename.Prepare(v.Root, moves)
+	if err != nil {
+		return err
+	}
+	set := changeset.New(v.Root, fmt.Sprintf("Archive %d stale session(s)", len(stale)))
+	set.Step("Move each session last dated over %d days ago to its domain's 05_ARCHIVE/", *days)
+	set.Step("Add a deprecation header saying when and why it was archived")
+	set.Step("Rewrite the links to the archived sessions")
+	if err := plan.AddTo(set, "stale session"); err != nil {
+		return err
+	}
+	for _, s := range stale {
+		data, err := plan.Text(s.Archive)
+		if err != nil {
+			return err
+		}
+		doc, err := frontmatter.Parse(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.Path, err)
+		}
+		reason := fmt.Sprintf("stale session, last dated %s (over %d days)", s.Date.Format("2006-01-02"), *days)
+		if err := doc.SetBody(audit.Deprecate(doc.Body(), now, s.Path, reason)); err != nil {
+			return err
+		}
+		if err := set.Modify(s.Archive, doc.Bytes(), "deprecation header"); err != nil {
+			return err
+		}
+	}
+	printEdits(e, plan)
+	applied, err := e.apply(v, set, a)
+	if err != nil {
+		return err
+	}
+	if !applied || len(set.Risks) > 0 {
+		return exitCode(1)
+	}
+	// Orphan skills need a person to decide; archiving does not fix them.
+	for _, f := range findings {
+		if f.Kind == audit.OrphanSkill {
+			return exitCode(1)
+		}
+	}
+	return nil
+}
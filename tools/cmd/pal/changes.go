@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/changeset"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+// approval holds the flags of every command that changes files through a
+// changeset.
+type approval struct {
+	yes, dryRun, savePlan *bool
+}
+
+func approvalFlags(fs *flag.FlagSet) approval {
+	return approval{
+		yes:      fs.Bool("yes", false, "apply the plan without asking"),
+		dryRun:   fs.Bool("dry-run", false, "print the plan without changing any file"),
+		savePlan: fs.Bool("save-plan", false, "also save the plan to inbox/Plan/ for review in Obsidian"),
+	}
+}
+
+// changesDir is the state folder that holds the undo journals.
+func changesDir(v *vault.Vault) string {
+	state, _ := v.Find(layout.State)
+	return path.Join(state, "changes")
+}
+
+// apply shows the plan of set, saves it when asked, and applies it once
+// approved, writing the undo journal first. It reports whether the changes
+// were made.
+func (e *env) apply(v *vault.Vault, set *changeset.Set, a approval) (bool, error) {
+	if set.Len() == 0 {
+		return false, nil
+	}
+	now := time.Now()
+	plan := set.Markdown(now)
+	e.stdout.Write(plan)
+	if *a.savePlan {
+		dir, _ := v.Find(layout.InboxPlan)
+		rel := path.Join(dir, set.PlanName(now))
+		if err := os.MkdirAll(filepath.Dir(v.Abs(rel)), 0o755); err != nil {
+			return false, err
+		}
+		if err := writeFile(v.Abs(rel), plan); err != nil {
+			return false, err
+		}
+		fmt.Fprintf(e.stdout, "\nPlan saved to %s\n", rel)
+	}
+	if *a.dryRun {
+		fmt.Fprintln(e.stdout, "\nDry run: no file changed. Run without -dry-run to apply.")
+		return false, nil
+	}
+	if !*a.yes {
+		fmt.Fprintf(e.stderr, "\nApply %d change(s)? [y/N] ", set.Len())
+		answer, _ := bufio.NewReader(e.stdin).ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+		default:
+			fmt.Fprintln(e.stdout, "Plan not applied.")
+			return false, nil
+		}
+	}
+
+	j, err := set.Journal()
+	if err != nil {
+		return false, err
+	}
+	journal := path.Join(changesDir(v), j.Name())
+	if err := j.Save(v.Abs(journal)); err != nil {
+		return false, err
+	}
+	if err := set.Apply(); err != nil {
+		return false, fmt.Errorf("%w (undo with: pal undo %s)", err, journal)
+	}
+	fmt.Fprintf(e.stdout, "\nApplied %d change(s). Undo with: pal undo %s\n", set.Len(), journal)
+	return true, nil
+}
+
+func runUndo(e *env, args []string) error {
+	fs := newFlags(e, "undo")
+	force := fs.Bool("force", false, "undo even when files changed since the apply, losing those changes")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return usagef("usage: pal undo [JOURNAL]")
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	file := fs.Arg(0)
+	if file == "" {
+		if file, err = changeset.LatestJournal(v.Abs(changesDir(v))); err != nil {
+			return err
+		}
+		if file == "" {
+			return fmt.Errorf("no journal in %s", changesDir(v))
+		}
+	} else if _, err := os.Stat(file); err != nil && !filepath.IsAbs(file) {
+		file = v.Abs(file) // as printed: vault-relative
+	}
+	j, err := changeset.ReadJournal(file)
+	if err != nil {
+		return err
+	}
+	if err := j.Undo(v.Root, *force); err != nil {
+		return fmt.Errorf("%s: %w; check them, then pal undo -force", file, err)
+	}
+	for _, f := range j.Files {
+		if f.Op == changeset.OpMove {
+			fmt.Fprintf(e.stdout, "  %-6s %s -> %s\n", f.Op, f.Path, f.From)
+			continue
+		}
+		fmt.Fprintf(e.stdout, "  %-6s %s\n", f.Op, f.Path)
+	}
+	fmt.Fprintf(e.stdout, "Undid %d change(s): %s\n", len(j.Files), j.Objective)
+	return os.Remove(file)
+}
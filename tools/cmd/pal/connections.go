@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/changeset"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/connections"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+func runConnectionsCheck(e *env, args []string) error {
+	fs := newFlags(e, "connections check")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	domains, err := pickDomains(v, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	problems := 0
+	for _, d := range domains {
+		rel := d.Path + "/" + vault.ConnectionsFile
+		data, err := os.ReadFile(v.Abs(rel))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		set, err := connections.Parse(data)
+		if err != nil {
+			fmt.Fprintf(e.stdout, "%s: %v\n", rel, err)
+			problems++
+			continue
+		}
+		for _, p := range connections.Validate(set, v.Root, v.Abs(d.Path)) {
+			if p.Line > 0 {
+				fmt.Fprintf(e.stdout, "%s:%d: %s\n", rel, p.Line, p)
+			} else {
+				fmt.Fprintf(e.stdout, "%s: %s\n", rel, p)
+			}
+			problems++
+		}
+	}
+	if problems > 0 {
+		return exitCode(1)
+	}
+	return nil
+}
+
+func runConnectionsMigrate(e *env, args []string) error {
+	fs := newFlags(e, "connections migrate")
+	a := approvalFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	domains, err := pickDomains(v, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	set := changeset.New(v.Root, fmt.Sprintf("Convert %s files to version %d", vault.ConnectionsFile, connections.Version))
+	set.Step("Rewrite each legacy %s in the version %d format", vault.ConnectionsFile, connections.Version)
+	for _, d := range domains {
+		rel := d.Path + "/" + vault.ConnectionsFile
+		data, err := os.ReadFile(v.Abs(rel))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		out, from, changed, err := connections.Migrate(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", rel, err)
+		}
+		if !changed {
+			continue
+		}
+		if *a.dryRun {
+			fmt.Fprintf(e.stdout, "--- %s (%s)\n%s\n", rel, from, out)
+		}
+		if err := set.Modify(rel, out, fmt.Sprintf("converted from %s", from)); err != nil {
+			return err
+		}
+	}
+	if set.Len() == 0 {
+		fmt.Fprintf(e.stdout, "All %s files already use version %d.\n", vault.ConnectionsFile, connections.Version)
+		return nil
+	}
+	_, err = e.apply(v, set, a)
+	return err
+}
+
+// pickDomains returns the named domains, or every domain when names is
+// empty.
+func pickDomains(v *vault.Vault, names []string) ([]*vault.Domain, error) {
+	if len(names) == 0 {
+		return v.Domains, nil
+	}
+	var out []*vault.Domain
+	for _, n := range names {
+		d := v.Domain(filepath.Base(n))
+		if d == nil {
+			return nil, usagef("no domain %q under %s/", n, vault.DomainsDir)
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
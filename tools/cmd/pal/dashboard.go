@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/changeset"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/frontmatter"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/tasks"
+)
+
+// runDashboard is the project_dashboard workflow (requirement 1.5.3): it
+// writes DASHBOARD.md with every project grouped by status, its progress
+// and when it was last touched. A "## Notes" section at the end of
+// DASHBOARD.md is kept as it is.
+func runDashboard(e *env, args []string) error {
+	fs := newFlags(e, "dashboard")
+	print := fs.Bool("print", false, "print the generated DASHBOARD.md instead of writing it")
+	staleDays := fs.Int("stale-days", tasks.StaleDays, "list projects untouched for more than `n` days as stale")
+	a := approvalFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *staleDays < 0 {
+		return usagef("-stale-days must not be negative")
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	projects, err := tasks.ReadProjects(v)
+	if err != nil {
+		return err
+	}
+	touched, err := tasks.LastTouched(v, projects)
+	if err != nil {
+		return err
+	}
+	dashboard, ok := v.Find(layout.Dashboard)
+	if !ok {
+		dashboard = v.Layout.Path(layout.Dashboard)
+	}
+	out := tasks.Dashboard(projects, touched, time.Now(), *staleDays)
+	old, err := os.ReadFile(v.Abs(dashboard))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if tail, ok := frontmatter.ProtectedTail(old); ok {
+		out = append(append(out, '\n'), tail...)
+	}
+	if *print {
+		_, err := e.stdout.Write(out)
+		return err
+	}
+	set := changeset.New(v.Root, "Regenerate "+dashboard)
+	set.Step("Regenerate %s from every project file, keeping its ## Notes section", dashboard)
+	if err := putState(v, set, dashboard, out, "project dashboard"); err != nil {
+		return err
+	}
+	if set.Len() == 0 {
+		fmt.Fprintf(e.stdout, "%s is up to date.\n", dashboard)
+		return nil
+	}
+	applied, err := e.apply(v, set, a)
+	if err != nil || !applied {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "Wrote %s: %d project(s).\n", dashboard, len(projects))
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/changeset"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/rename"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/scheme"
+)
+
+func runDomainMigrate(e *env, args []string) error {
+	fs := newFlags(e, "domain migrate")
+	a := approvalFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+
+	domains, err := pickDomains(v, fs.Args())
+	if err != nil {
+		return err
+	}
+	var migrations []*scheme.Migration
+	var moves []rename.Move
+	for _, d := range domains {
+		m, err := scheme.Plan(v.Root, d.Path)
+		if err != nil {
+			return err
+		}
+		if m.From == scheme.Current {
+			fmt.Fprintf(e.stdout, "%s: already on the current folder scheme\n", d.Path)
+			continue
+		}
+		migrations = append(migrations, m)
+		moves = append(moves, m.Moves...)
+	}
+	if len(migrations) == 0 {
+		return nil
+	}
+
+	// One plan for every domain, so links between migrating domains are
+	// rewritten against the final layout.
+	plan, err := rename.Prepare(v.Root, moves)
+	if err != nil {
+		return err
+	}
+	set := changeset.New(v.Root, fmt.Sprintf("Move %d domain(s) to the current folder scheme", len(migrations)))
+	set.Step("Move 02_SESSIONS, 03_ASSETS and 04_OUTPUTS to 04_SESSIONS, 02_PAGES and 03_OUTPUT, entry by entry where the new folder exists")
+	set.Step("Rewrite the wikilinks and markdown links into the moved folders")
+	set.Step("Remove the v1 folders the moves leave empty")
+	if err := plan.AddTo(set, "v1 folder scheme"); err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if err := m.RemoveEmptied(v.Root, set); err != nil {
+			return err
+		}
+	}
+	printEdits(e, plan)
+	applied, err := e.apply(v, set, a)
+	if err != nil {
+		return err
+	}
+	if !applied || len(set.Risks) > 0 {
+		return exitCode(1)
+	}
+	return nil
+}
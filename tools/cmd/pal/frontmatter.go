@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/frontmatter"
+)
+
+func runFrontmatterGet(e *env, args []string) error {
+	fs := newFlags(e, "frontmatter get")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usagef("usage: pal frontmatter get FILE KEY")
+	}
+	d, err := readDocument(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	var v yaml.Node
+	if err := d.Decode(fs.Arg(1), &v); err != nil {
+		return exitCode(1)
+	}
+	if v.Kind == yaml.ScalarNode {
+		fmt.Fprintln(e.stdout, v.Value)
+		return nil
+	}
+	out, err := yaml.Marshal(&v)
+	if err != nil {
+		return err
+	}
+	_, err = e.stdout.Write(out)
+	return err
+}
+
+func runFrontmatterSet(e *env, args []string) error {
+	fs := newFlags(e, "frontmatter set")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 3 {
+		return usagef("usage: pal frontmatter set FILE KEY VALUE")
+	}
+	d, err := readDocument(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	// VALUE is YAML, so "[a, b]" sets a list and "2026-03-01" a bare date.
+	var v yaml.Node
+	if err := yaml.Unmarshal([]byte(fs.Arg(2)), &v); err != nil {
+		return usagef("VALUE is not valid YAML: %v", err)
+	}
+	if v.Kind == 0 {
+		v = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+	if err := d.Set(fs.Arg(1), &v); err != nil {
+		return err
+	}
+	return writeDocument(fs.Arg(0), d)
+}
+
+func runFrontmatterDelete(e *env, args []string) error {
+	fs := newFlags(e, "frontmatter delete")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usagef("usage: pal frontmatter delete FILE KEY")
+	}
+	d, err := readDocument(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	ok, err := d.Delete(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return exitCode(1)
+	}
+	return writeDocument(fs.Arg(0), d)
+}
+
+func readDocument(path string) (*frontmatter.Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	d, err := frontmatter.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return d, nil
+}
+
+// writeDocument saves d if it changed, refusing to touch the protected
+// Notes section.
+func writeDocument(path string, d *frontmatter.Document) error {
+	if !d.Changed() {
+		return nil
+	}
+	return writeFile(path, d.Bytes())
+}
+
+// writeFile replaces path with data, keeping the file mode. Existing notes
+// with a "## Notes" section must keep it byte for byte.
+func writeFile(path string, data []byte) error {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+		old, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := frontmatter.CheckProtected(old, data); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return os.WriteFile(path, data, mode)
+}
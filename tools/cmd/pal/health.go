@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/audit"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/validate"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+// Exit statuses of pal health. 2 stays the usage error of every command.
+const (
+	exitHealthy        = 0
+	exitMinorIssues    = 1
+	exitNeedsAttention = 3
+)
+
+func runHealth(e *env, args []string) error {
+	fs := newFlags(e, "health")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	asSARIF := fs.Bool("sarif", false, "print the findings as SARIF 2.1.0")
+	verbose := fs.Bool("v", false, "list every finding below the summary")
+	days := fs.Int("days", audit.DefaultStaleDays, "report sessions older than this many `days`")
+	history := fs.Bool("history", false, "print the week-over-week history instead of running the audits")
+	noSave := fs.Bool("no-save", false, "do not append this run to the history")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *asJSON && *asSARIF {
+		return usagef("-json and -sarif are exclusive")
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	state, _ := v.Find(layout.State)
+	histPath := v.Abs(path.Join(state, audit.HistoryFile))
+	runs, err := audit.LoadHistory(histPath)
+	if err != nil {
+		return err
+	}
+
+	if *history {
+		for _, r := range audit.Weekly(runs) {
+			y, w := r.Time.ISOWeek()
+			fmt.Fprintf(e.stdout, "%d-W%02d  %s  %-16s %3d  %s\n", y, w, r.Time.Format("2006-01-02"), r.Status, r.Issues, auditCounts(r.Audits))
+		}
+		return nil
+	}
+
+	report, err := audit.Health(v, time.Now(), *days,
+		audit.Check{Audit: "agent", Run: validateAgents},
+		audit.Check{Audit: "skill", Run: validateSkills})
+	if err != nil {
+		return err
+	}
+	run := report.Run()
+	if !*noSave {
+		if err := audit.AppendHistory(histPath, run); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case *asJSON, *asSARIF:
+		var out any = report
+		if *asSARIF {
+			out = report.SARIF()
+		}
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+	default:
+		fmt.Fprintf(e.stdout, "PAL health: %s (%d issue(s))\n\n", report.Status, report.Issues)
+		for _, res := range report.Results {
+			line := fmt.Sprintf("  %-11s %3d  %-16s %s", res.Audit, len(res.Findings), res.Status, kindCounts(res.Findings))
+			fmt.Fprintln(e.stdout, strings.TrimRight(line, " "))
+		}
+		if prev, ok := audit.Before(runs, run.Time, 7*24*time.Hour); ok {
+			fmt.Fprintf(e.stdout, "\nWeek over week: %d issue(s), %d on %s (%s)\n", run.Issues, prev.Issues, prev.Time.Format("2006-01-02"), trend(prev.Issues, run.Issues))
+		} else if len(runs) > 0 {
+			prev := runs[len(runs)-1]
+			fmt.Fprintf(e.stdout, "\nSince the last run: %d issue(s), %d on %s (%s)\n", run.Issues, prev.Issues, prev.Time.Format("2006-01-02"), trend(prev.Issues, run.Issues))
+		}
+		if *verbose {
+			fmt.Fprintln(e.stdout)
+			for _, res := range report.Results {
+				for _, f := range res.Findings {
+					fmt.Fprintf(e.stdout, "%s: %s\n", findingWhere(f), findingText(f))
+				}
+			}
+		}
+	}
+
+	switch report.Status {
+	case audit.MinorIssues:
+		return exitCode(exitMinorIssues)
+	case audit.NeedsAttention:
+		return exitCode(exitNeedsAttention)
+	}
+	return nil
+}
+
+// kindCounts summarises findings as "DEAD REFERENCE ×2, UNREGISTERED ×1".
+func kindCounts(fs []audit.Finding) string {
+	n := map[string]int{}
+	var kinds []string
+	for _, f := range fs {
+		if n[f.Kind] == 0 {
+			kinds = append(kinds, f.Kind)
+		}
+		n[f.Kind]++
+	}
+	sort.Strings(kinds)
+	parts := make([]string, len(kinds))
+	for i, k := range kinds {
+		parts[i] = fmt.Sprintf("%s ×%d", k, n[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// auditCounts summarises a history run as "domains=0 links=2 ...", every
+// audit the run recorded in name order.
+func auditCounts(m map[string]int) string {
+	names := make([]string, 0, len(m))
+	for a := range m {
+		names = append(names, a)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, a := range names {
+		parts[i] = fmt.Sprintf("%s=%d", a, m[a])
+	}
+	return strings.Join(parts, " ")
+}
+
+// validateAgents runs validate agent -all.
+func validateAgents(v *vault.Vault) ([]audit.Finding, error) {
+	items, err := audit.Items(v)
+	if err != nil {
+		return nil, err
+	}
+	var out []audit.Finding
+	for _, it := range items {
+		if it.Kind != audit.AgentItem {
+			continue
+		}
+		found, err := validate.Agent(v, it.Path)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, found...)
+	}
+	return out, nil
+}
+
+// validateSkills runs validate skill -all.
+func validateSkills(v *vault.Vault) ([]audit.Finding, error) {
+	dirs, err := skillDirs(v, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	var out []audit.Finding
+	for _, d := range dirs {
+		found, err := validate.Skill(v, d)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, found...)
+	}
+	return out, nil
+}
+
+func trend(before, now int) string {
+	switch {
+	case now < before:
+		return fmt.Sprintf("%d, improving", now-before)
+	case now > before:
+		return fmt.Sprintf("+%d, getting worse", now-before)
+	}
+	return "unchanged"
+}
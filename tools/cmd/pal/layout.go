@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/naming"
+)
+
+func runLayout(e *env, args []string) error {
+	fs := newFlags(e, "layout")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	for _, k := range v.Layout.Keys() {
+		p, ok := v.Find(k)
+		switch {
+		case !ok:
+			fmt.Fprintf(e.stdout, "%-20s %s (missing)\n", k, p)
+		case p != v.Layout.Path(k):
+			fmt.Fprintf(e.stdout, "%-20s %s (on disk as %s)\n", k, v.Layout.Path(k), p)
+		default:
+			fmt.Fprintf(e.stdout, "%-20s %s\n", k, p)
+		}
+	}
+	return nil
+}
+
+func runLayoutCheck(e *env, args []string) error {
+	fs := newFlags(e, "layout check")
+	asJSON := fs.Bool("json", false, "print the findings as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+
+	files, err := v.Markdown()
+	if err != nil {
+		return err
+	}
+	// Skills, agents and commands document paths too.
+	if claude, ok := v.Find(layout.Claude); ok {
+		entries, err := naming.Scan(v.Root, claude)
+		if err != nil {
+			return err
+		}
+		for _, en := range entries {
+			if !en.Dir && strings.HasSuffix(strings.ToLower(en.Path), ".md") {
+				files = append(files, en.Path)
+			}
+		}
+	}
+	var docs []layout.Doc
+	for _, f := range files {
+		data, err := os.ReadFile(v.Abs(f))
+		if err != nil {
+			return err
+		}
+		docs = append(docs, layout.Doc{Path: f, Text: string(data)})
+	}
+
+	findings := v.Layout.Check(v.Root, docs)
+	if *asJSON {
+		if findings == nil {
+			findings = []layout.Finding{}
+		}
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(findings); err != nil {
+			return err
+		}
+	} else {
+		// A whole missing top-level folder (no .claude/ in this checkout)
+		// would otherwise bury the real drift under one line per file.
+		absent := map[string]int{}
+		for _, f := range findings {
+			top, _, _ := strings.Cut(f.Path, "/")
+			if _, ok := layout.Resolve(v.Root, top); !ok && f.Kind == layout.Missing {
+				absent[top]++
+			}
+		}
+		for _, f := range findings {
+			top, _, _ := strings.Cut(f.Path, "/")
+			if n := absent[top]; n > 0 {
+				fmt.Fprintf(e.stdout, "%s: folder missing from this vault; %d documented path(s) below it (-json lists them)\n", top, n)
+				absent[top] = -1
+				continue
+			} else if n < 0 {
+				continue
+			}
+			switch f.Kind {
+			case layout.CaseMismatch:
+				fmt.Fprintf(e.stdout, "%s: wrong case, on disk as %s\n", f.Path, f.Actual)
+			case layout.Renamed:
+				fmt.Fprintf(e.stdout, "%s: moved to %s\n", f.Path, f.Actual)
+			default:
+				if f.Actual != "" {
+					fmt.Fprintf(e.stdout, "%s: does not exist (canonical spelling %s)\n", f.Path, f.Actual)
+				} else {
+					fmt.Fprintf(e.stdout, "%s: does not exist\n", f.Path)
+				}
+			}
+			for _, r := range f.Refs {
+				fmt.Fprintf(e.stdout, "  %s\n", r)
+			}
+		}
+	}
+	if len(findings) > 0 {
+		return exitCode(1)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/wikilink"
+)
+
+func runLinksResolve(e *env, args []string) error {
+	fs := newFlags(e, "links resolve")
+	from := fs.String("from", "", "vault-relative `NOTE` the link is written in")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return usagef("usage: pal links resolve [-from NOTE] LINK...")
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	all, err := v.AllFiles()
+	if err != nil {
+		return err
+	}
+	r := wikilink.NewResolver(all)
+
+	failed := false
+	for _, arg := range fs.Args() {
+		l := wikilink.Parse(strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(arg, "!"), "[["), "]]"))
+		res, ok := r.Lookup(*from, l)
+		if !ok {
+			fmt.Fprintf(e.stdout, "%s: not found\n", arg)
+			failed = true
+			continue
+		}
+		fmt.Fprintf(e.stdout, "%s: %s\n", arg, res.Path)
+		if res.Ambiguous() {
+			fmt.Fprintf(e.stdout, "  ambiguous, also matches: %s\n", strings.Join(res.Candidates[1:], ", "))
+		}
+		if l.Heading == "" && l.Block == "" {
+			continue
+		}
+		data, err := os.ReadFile(v.Abs(res.Path))
+		if err != nil {
+			return err
+		}
+		switch {
+		case l.Heading != "" && !wikilink.HasHeading(string(data), l.Heading):
+			fmt.Fprintf(e.stdout, "  heading %q not found\n", l.Heading)
+			failed = true
+		case l.Block != "" && !wikilink.HasBlock(string(data), l.Block):
+			fmt.Fprintf(e.stdout, "  block ^%s not found\n", l.Block)
+			failed = true
+		}
+	}
+	if failed {
+		return exitCode(1)
+	}
+	return nil
+}
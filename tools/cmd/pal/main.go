@@ -0,0 +1,186 @@
+// Command pal is the command-line companion to a PAL Second Brain vault. It
+// runs the deterministic parts of the PAL skills (audits, task sync,
+// validation) without an LLM session.
+//
+// Usage:
+//
+//	pal [-root DIR] <command> [arguments]
+//
+// The vault root defaults to the nearest directory above the working
+// directory that contains a Domains/ folder.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+// command is one pal subcommand. name may contain spaces for nested commands
+// such as "tasks migrate".
+type command struct {
+	name    string
+	summary string
+	run     func(env *env, args []string) error
+}
+
+var commands = []*command{
+	{"vault", "Print the domains, projects, pages and sessions of the vault", runVault},
+	{"audit domains", "Check each domain's required files and folders, nesting depth and Active Work table", runAuditDomains},
+	{"audit orphans", "Report skills no agent claims and sessions older than 30 days; -archive moves stale sessions to 05_ARCHIVE/", runAuditOrphans},
+	{"audit references", "Compare .claude agents, skills, workflows and commands with ROUTING_TABLE.md and SYSTEM_INDEX.md", runAuditReferences},
+	{"audit links", "Report dead wikilinks, markdown links and requirement Source: lines with the closest existing path", runAuditLinks},
+	{"audit naming", "Check file and folder names against the eight naming categories; -fix renames and rewrites links", runAuditNaming},
+	{"connections check", "Validate each domain's CONNECTIONS.yaml against the current schema", runConnectionsCheck},
+	{"connections migrate", "Convert legacy CONNECTIONS.yaml files to the current schema", runConnectionsMigrate},
+	{"dashboard", "Write inbox/Tasks/DASHBOARD.md: projects grouped by status with completion, blocked tasks, last-touched dates and a stale list", runDashboard},
+	{"domain migrate", "Move v1 domain folders (02_SESSIONS, 03_ASSETS, 04_OUTPUTS) to the current scheme and rewrite links", runDomainMigrate},
+	{"frontmatter get", "Print one frontmatter value of a note", runFrontmatterGet},
+	{"frontmatter set", "Set a frontmatter key, preserving everything else", runFrontmatterSet},
+	{"frontmatter delete", "Remove a frontmatter key", runFrontmatterDelete},
+	{"frontmatter validate", "Check note frontmatter against the JSON Schemas in .claude/schemas/frontmatter/ (built-in defaults otherwise)", runFrontmatterValidate},
+	{"frontmatter schemas", "List the frontmatter schemas and the paths they apply to; -init writes the built-in ones out for editing", runFrontmatterSchemas},
+	{"health", "Run every audit and rate the vault HEALTHY, MINOR ISSUES or NEEDS ATTENTION; records a history", runHealth},
+	{"layout", "List the vault's canonical locations from pal.layout.yaml", runLayout},
+	{"layout check", "Report documented paths that do not exist in the vault as written", runLayoutCheck},
+	{"links resolve", "Show which file a [[wikilink]] opens, using Obsidian's resolution rules", runLinksResolve},
+	{"observations", "Check observation categories in notes; -fix rewrites invalid ones", runObservations},
+	{"relations", "Validate ## Relations sections and track forward references", runRelations},
+	{"requirements", "Parse 06_REQUIREMENTS documents; fails on duplicate, out-of-order or incomplete requirements", runRequirements},
+	{"skill canonicalize", "Rename a skill's folder, SKILL.md, workflows and context files to the conventions, add tools/ and update the routing tables", runSkillCanonicalize},
+	{"tasks pull", "Regenerate inbox/Tasks/MASTER.md from every PROJECT_*.md, grouped per project into Active, Inactive and Done", runTasksPull},
+	{"tasks sync", "Push MASTER.md task edits back to the project files by three-way merge, then pull again", runTasksSync},
+	{"tasks repair", "Give tasks that repeat another task's block ID a new one, and tasks without an ID their first", runTasksRepair},
+	{"tasks migrate", "Rewrite #open/#in-progress/#done tasks in 01_PROJECTS/ into checkbox form", runTasksMigrate},
+	{"validate agent", "Check an agent's 4-field header, domain, 8 sections, Section 5 capabilities and ROUTING_TABLE entry; -all for every agent", runValidateAgent},
+	{"validate skill", "Check a skill's flat layout, tools/ folder, file names and USE WHEN description, with a fix for each problem; -all for every skill", runValidateSkill},
+	{"undo", "Undo the last applied change plan, or the one recorded in the given journal", runUndo},
+}
+
+// env is the state shared by every command.
+type env struct {
+	root   string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// load reads the vault at env.root.
+func (e *env) load() (*vault.Vault, error) {
+	return vault.Load(e.root)
+}
+
+// exitCode ends the process with the given status without printing anything
+// further. Commands use it to report findings through the exit status.
+type exitCode int
+
+func (c exitCode) Error() string { return fmt.Sprintf("exit status %d", int(c)) }
+
+// usageError marks a problem with the command line; pal exits with status 2.
+type usageError struct{ msg string }
+
+func (e usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return usageError{fmt.Sprintf(format, args...)}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("pal", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	root := global.String("root", "", "vault root (default: nearest parent containing Domains/)")
+	global.Usage = func() { usage(stderr) }
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+
+	cmd, rest := lookup(global.Args())
+	if cmd == nil {
+		usage(stderr)
+		return 2
+	}
+
+	e := &env{root: *root, stdin: stdin, stdout: stdout, stderr: stderr}
+	if e.root == "" {
+		wd, err := os.Getwd()
+		if err == nil {
+			e.root, err = vault.FindRoot(wd)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "pal:", err)
+			return 2
+		}
+	}
+
+	err := cmd.run(e, rest)
+	var code exitCode
+	var uerr usageError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &code):
+		return int(code)
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &uerr):
+		fmt.Fprintf(stderr, "pal %s: %s\n", cmd.name, uerr.msg)
+		return 2
+	default:
+		fmt.Fprintf(stderr, "pal %s: %s\n", cmd.name, err)
+		return 1
+	}
+}
+
+// lookup finds the command with the longest name matching the leading words
+// of args.
+func lookup(args []string) (*command, []string) {
+	var best *command
+	n := 0
+	for _, c := range commands {
+		words := strings.Fields(c.name)
+		if len(words) <= len(args) && len(words) > n && strings.Join(args[:len(words)], " ") == c.name {
+			best, n = c, len(words)
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	return best, args[n:]
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: pal [-root DIR] <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-22s %s\n", c.name, c.summary)
+	}
+}
+
+// newFlags returns a FlagSet for a subcommand that reports errors on stderr.
+func newFlags(e *env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("pal "+name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+// parseFlags parses args into fs. The flag package has already printed the
+// problem and usage when this fails, so the error only carries the status.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return exitCode(2)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/changeset"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/naming"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/rename"
+)
+
+func runAuditNaming(e *env, args []string) error {
+	fs := newFlags(e, "audit naming")
+	fix := fs.Bool("fix", false, "rename violating files and folders to the suggested name and rewrite links to them")
+	a := approvalFlags(fs)
+	asJSON := fs.Bool("json", false, "print the violations as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	domains, _ := v.Find(layout.Domains)
+	claude, _ := v.Find(layout.Claude)
+	entries, err := naming.Scan(v.Root, domains, claude)
+	if err != nil {
+		return err
+	}
+
+	var found []naming.Violation
+	for _, en := range entries {
+		if x, ok := naming.Check(en); ok {
+			found = append(found, x)
+		}
+	}
+	moves, err := namingMoves(v.Root, found)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		out := found
+		if out == nil {
+			out = []naming.Violation{}
+		}
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+	} else {
+		for _, x := range found {
+			fix := x.Suggested
+			if fix == "" {
+				fix = "(rename by hand)"
+			}
+			fmt.Fprintf(e.stdout, "%s: %s %q, expected %s, suggest %s\n", x.Path, x.Category, x.Current, x.Expected, fix)
+		}
+	}
+	if !*fix || len(moves) == 0 {
+		if len(found) > 0 {
+			return exitCode(1)
+		}
+		return nil
+	}
+
+	plan, err := rename.Prepare(v.Root, moves)
+	if err != nil {
+		return err
+	}
+	set := changeset.New(v.Root, "Rename paths to the naming conventions")
+	set.Step("Rename %d file(s) and folder(s) to their suggested names, deepest first", len(plan.Moves))
+	set.Step("Rewrite the wikilinks and markdown links that pointed at the old names")
+	if err := plan.AddTo(set, "naming convention"); err != nil {
+		return err
+	}
+	printEdits(e, plan)
+	applied, err := e.apply(v, set, a)
+	if err != nil {
+		return err
+	}
+	if !applied || len(found) > len(plan.Moves) || len(set.Risks) > 0 {
+		return exitCode(1)
+	}
+	return nil
+}
+
+// namingMoves returns the renames to the suggested names of found,
+// deepest paths first so every Move.From is still valid when it runs.
+// Suggestions that would collide with another path, ignoring case, are
+// dropped from found too: those are renamed by hand.
+func namingMoves(root string, found []naming.Violation) ([]rename.Move, error) {
+	for {
+		var moves []rename.Move
+		for _, x := range found {
+			if x.Suggested != "" {
+				moves = append(moves, rename.Move{From: x.Path, To: path.Join(path.Dir(x.Path), x.Suggested)})
+			}
+		}
+		sort.SliceStable(moves, func(i, j int) bool {
+			return strings.Count(moves[i].From, "/") > strings.Count(moves[j].From, "/")
+		})
+		var ce *rename.CollisionError
+		err := rename.Check(root, moves)
+		if !errors.As(err, &ce) {
+			return moves, err
+		}
+		// Dropping a folder's rename changes where the renames inside it
+		// land, so check again.
+		for _, m := range ce.Moves {
+			for i := range found {
+				if found[i].Path == m.From {
+					found[i].Suggested = ""
+				}
+			}
+		}
+	}
+}
+
+// printEdits lists the link rewrites of plan.
+func printEdits(e *env, plan *rename.Plan) {
+	for _, ed := range plan.Edits {
+		if !ed.Protected {
+			fmt.Fprintf(e.stdout, "  %s:%d: %s -> %s\n", ed.File, ed.Line, ed.Old, ed.New)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/changeset"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/observation"
+)
+
+func runObservations(e *env, args []string) error {
+	fs := newFlags(e, "observations")
+	fix := fs.Bool("fix", false, "replace invalid categories with the suggested one")
+	list := fs.Bool("list", false, "print every observation, not only invalid ones")
+	a := approvalFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	files, err := e.markdownArgs(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	invalid, fixed := 0, 0
+	var fixes []file
+	var texts []string
+	for _, f := range files {
+		data, err := os.ReadFile(f.abs)
+		if err != nil {
+			return err
+		}
+		text := string(data)
+		if *list {
+			for _, o := range observation.ParseDocument(text) {
+				fmt.Fprintf(e.stdout, "%s:%d: [%s] %s\n", f.rel, o.Line, o.Category, o.Content)
+			}
+		}
+		findings := observation.Check(text)
+		invalid += len(findings)
+		for _, x := range findings {
+			fmt.Fprintf(e.stdout, "%s:%d: invalid category [%s], suggest [%s]\n", f.rel, x.Line, x.Category, x.Suggestion)
+		}
+		if !*fix || len(findings) == 0 {
+			continue
+		}
+		out, done := observation.Fix(text)
+		if len(done) == 0 {
+			continue
+		}
+		fixes = append(fixes, f)
+		texts = append(texts, out)
+		fixed += len(done)
+	}
+
+	if len(fixes) > 0 {
+		v, err := e.load()
+		if err != nil {
+			return err
+		}
+		set := changeset.New(v.Root, "Replace invalid observation categories")
+		set.Step("Replace each invalid category with the closest of the ten valid ones")
+		for i, f := range fixes {
+			rel, err := vaultPaths(v, []string{f.abs})
+			if err != nil {
+				return err
+			}
+			if err := set.Modify(rel[0], []byte(texts[i]), "invalid observation categories"); err != nil {
+				return err
+			}
+		}
+		applied, err := e.apply(v, set, a)
+		if err != nil {
+			return err
+		}
+		if !applied {
+			fixed = 0
+		}
+	}
+	if invalid > fixed {
+		return exitCode(1)
+	}
+	return nil
+}
+
+// file is a markdown file named on the command line or found in the vault.
+type file struct {
+	rel string // as displayed
+	abs string
+}
+
+// markdownArgs returns the files named in args, or every markdown file in
+// the vault when args is empty.
+func (e *env) markdownArgs(args []string) ([]file, error) {
+	var out []file
+	if len(args) > 0 {
+		for _, a := range args {
+			abs, err := filepath.Abs(a)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, file{rel: a, abs: abs})
+		}
+		return out, nil
+	}
+	v, err := e.load()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := v.Markdown()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		out = append(out, file{rel: p, abs: v.Abs(p)})
+	}
+	return out, nil
+}
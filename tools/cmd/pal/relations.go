@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/relations"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/wikilink"
+)
+
+func runRelations(e *env, args []string) error {
+	fs := newFlags(e, "relations")
+	pending := fs.Bool("pending", false, "list forward references whose target note does not exist yet")
+	noSave := fs.Bool("no-save", false, "do not update the forward-reference ledger")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	all, err := v.AllFiles()
+	if err != nil {
+		return err
+	}
+	resolver := wikilink.NewResolver(all)
+
+	files, err := e.markdownArgs(fs.Args())
+	if err != nil {
+		return err
+	}
+	problems := 0
+	var current []relations.Pending
+	for _, f := range files {
+		data, err := os.ReadFile(f.abs)
+		if err != nil {
+			return err
+		}
+		rels, probs, _ := relations.Parse(string(data))
+		for _, p := range probs {
+			fmt.Fprintf(e.stdout, "%s:%d: %s\n", f.rel, p.Line, p.Message)
+		}
+		problems += len(probs)
+		// Links resolve from the note's place in the vault, not from
+		// the path as typed.
+		from, err := vaultPaths(v, []string{f.abs})
+		if err != nil {
+			return err
+		}
+		for _, r := range rels {
+			if _, ok := resolver.Resolve(from[0], r.Note()); ok {
+				continue
+			}
+			p := relations.Pending{From: from[0], Line: r.Line, Type: r.Type, Target: r.Note()}
+			current = append(current, p)
+			if *pending {
+				fmt.Fprintf(e.stdout, "%s:%d: forward reference %s [[%s]] (not created yet)\n", f.rel, p.Line, p.Type, p.Target)
+			}
+		}
+	}
+
+	// The ledger describes the whole vault, so only a full scan updates it.
+	if len(fs.Args()) == 0 {
+		state, _ := v.Find(layout.State)
+		path := v.Abs(state + "/" + relations.LedgerFile)
+		ledger, err := relations.LoadLedger(path)
+		if err != nil {
+			return err
+		}
+		for _, r := range ledger.Update(current, resolver) {
+			fmt.Fprintf(e.stdout, "%s:%d: forward reference %s [[%s]] resolved by %s\n", r.From, r.Line, r.Type, r.Target, r.Path)
+		}
+		if !*noSave {
+			if err := ledger.Save(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	if problems > 0 {
+		return exitCode(1)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/requirements"
+)
+
+func runRequirements(e *env, args []string) error {
+	fs := newFlags(e, "requirements")
+	asJSON := fs.Bool("json", false, "print the parsed requirements, category counts and problems as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	var order []string
+	docs := map[string]string{}
+	if fs.NArg() > 0 {
+		for _, f := range fs.Args() {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return err
+			}
+			order = append(order, f)
+			docs[f] = string(data)
+		}
+	} else {
+		v, err := e.load()
+		if err != nil {
+			return err
+		}
+		for _, d := range v.Domains {
+			files, err := v.Files(d, requirements.Folder)
+			if err != nil {
+				return err
+			}
+			for _, f := range files {
+				if strings.EqualFold(path.Base(f), "README.md") {
+					continue
+				}
+				data, err := os.ReadFile(v.Abs(f))
+				if err != nil {
+					return err
+				}
+				order = append(order, f)
+				docs[f] = string(data)
+			}
+		}
+	}
+
+	reqs, problems := requirements.Check(order, docs)
+	counts := requirements.Counts(reqs)
+
+	if *asJSON {
+		out := struct {
+			Requirements []requirements.Requirement `json:"requirements"`
+			Categories   map[string]int             `json:"categories"`
+			Problems     []requirements.Problem     `json:"problems"`
+		}{reqs, counts, problems}
+		if out.Requirements == nil {
+			out.Requirements = []requirements.Requirement{}
+		}
+		if out.Problems == nil {
+			out.Problems = []requirements.Problem{}
+		}
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+	} else {
+		for _, p := range problems {
+			fmt.Fprintf(e.stdout, "%s:%d: %s\n", p.Document, p.Line, p.Message)
+		}
+		if len(problems) > 0 {
+			fmt.Fprintln(e.stdout)
+		}
+		cats := make([]string, 0, len(counts))
+		for c := range counts {
+			cats = append(cats, c)
+		}
+		sort.Slice(cats, func(i, j int) bool {
+			if counts[cats[i]] != counts[cats[j]] {
+				return counts[cats[i]] > counts[cats[j]]
+			}
+			return cats[i] < cats[j]
+		})
+		for _, c := range cats {
+			fmt.Fprintf(e.stdout, "%-14s %4d\n", c, counts[c])
+		}
+		fmt.Fprintf(e.stdout, "%-14s %4d requirements in %d documents, %d problem(s)\n", "Total", len(reqs), len(order), len(problems))
+	}
+
+	if len(problems) > 0 {
+		return exitCode(1)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/changeset"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/rename"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/schema"
+)
+
+// runFrontmatterValidate is the validator the post-tool-use hook shares:
+// the hook runs `pal frontmatter validate -json FILE` after each write and
+// turns the issues into schema warnings.
+func runFrontmatterValidate(e *env, args []string) error {
+	fs := newFlags(e, "frontmatter validate")
+	asJSON := fs.Bool("json", false, "print the issues as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	reg, err := schema.Load(v.Root, v.Layout)
+	if err != nil {
+		return err
+	}
+	files, err := vaultPaths(v, fs.Args())
+	if err != nil {
+		return err
+	}
+	if files == nil {
+		all, _, err := rename.Walk(v.Root)
+		if err != nil {
+			return err
+		}
+		// Walk skips dot files; the current-session file is one.
+		files = append(all, path.Join(v.Layout.Path(layout.Sessions), ".current-session"))
+		sort.Strings(files)
+	}
+
+	issues := []schema.Issue{}
+	for _, f := range files {
+		found, err := reg.Check(v.Root, f)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, found...)
+	}
+	if *asJSON {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(issues); err != nil {
+			return err
+		}
+	} else {
+		for _, is := range issues {
+			fmt.Fprintf(e.stdout, "%s: %s (schema %s)\n", is.Path, is.Problem, is.Schema)
+		}
+	}
+	if len(issues) > 0 {
+		return exitCode(1)
+	}
+	return nil
+}
+
+func runFrontmatterSchemas(e *env, args []string) error {
+	fs := newFlags(e, "frontmatter schemas")
+	initDir := fs.Bool("init", false, "write the built-in schemas to the schemas folder for editing")
+	a := approvalFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	if *initDir {
+		dir := v.Layout.Path(layout.Schemas)
+		set := changeset.New(v.Root, "Write the built-in frontmatter schemas to "+dir)
+		set.Step("Copy each built-in schema that %s does not have yet", dir)
+		set.Step("Edit the copies to add fields or allowed values; pal and the hooks pick them up by name")
+		defaults := schema.Defaults()
+		names := make([]string, 0, len(defaults))
+		for n := range defaults {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			p := path.Join(dir, n+schema.Ext)
+			if _, err := os.Stat(v.Abs(p)); err == nil {
+				continue
+			}
+			if err := set.Create(p, defaults[n], "built-in "+n+" schema"); err != nil {
+				return err
+			}
+		}
+		if set.Len() == 0 {
+			fmt.Fprintf(e.stdout, "%s already has every built-in schema.\n", dir)
+			return nil
+		}
+		_, err := e.apply(v, set, a)
+		return err
+	}
+
+	reg, err := schema.Load(v.Root, v.Layout)
+	if err != nil {
+		return err
+	}
+	for _, en := range reg.Entries {
+		fmt.Fprintf(e.stdout, "%s (%s)\n  paths:    %s\n", en.Name, en.Source, strings.Join(en.Paths, ", "))
+		if len(en.Exclude) > 0 {
+			fmt.Fprintf(e.stdout, "  except:   %s\n", strings.Join(en.Exclude, ", "))
+		}
+		if len(en.Schema.Required) > 0 {
+			fmt.Fprintf(e.stdout, "  required: %s\n", strings.Join(en.Schema.Required, ", "))
+		}
+	}
+	return nil
+}
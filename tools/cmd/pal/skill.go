@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/audit"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/changeset"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/frontmatter"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/rename"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/validate"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+// runSkillCanonicalize is the canonicalize_skill workflow (requirement
+// 1.3.6): it renames a skill's folder, SKILL.md, workflows and context
+// files to the naming conventions, rewrites the links to them, sets the
+// SKILL.md name, creates a missing tools/ folder and updates the skill's
+// ROUTING_TABLE.md and SYSTEM_INDEX.md rows to the new names.
+func runSkillCanonicalize(e *env, args []string) error {
+	fs := newFlags(e, "skill canonicalize")
+	all := fs.Bool("all", false, "canonicalize every skill in .claude/skills/")
+	a := approvalFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	if *all == (fs.NArg() > 0) {
+		return usagef("usage: pal skill canonicalize [-yes] [-dry-run] [-save-plan] (-all | SKILL...)")
+	}
+	dirs, err := skillDirs(v, fs.Args(), *all)
+	if err != nil {
+		return err
+	}
+
+	var fixes []*validate.SkillFix
+	var moves []rename.Move
+	for _, d := range dirs {
+		fix, err := validate.Canonical(v, d)
+		if err != nil {
+			return err
+		}
+		fixes = append(fixes, fix)
+		moves = append(moves, fix.Moves...)
+	}
+	plan, err := rename.Prepare(v.Root, moves)
+	if err != nil {
+		return err
+	}
+
+	// The SKILL.md name follows the folder.
+	for _, fix := range fixes {
+		if fix.SkillFile == "" {
+			continue
+		}
+		final := plan.Final(fix.SkillFile)
+		data, err := plan.Text(final)
+		if err != nil {
+			return err
+		}
+		doc, err := frontmatter.Parse(data)
+		if err != nil {
+			continue // validate skill reports the broken header
+		}
+		if name, ok := doc.Get("name"); !ok || name == fix.Name {
+			continue
+		}
+		if err := doc.Set("name", fix.Name); err != nil {
+			return err
+		}
+		if err := plan.Rewrite(final, doc.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err := renameEntries(v, plan); err != nil {
+		return err
+	}
+
+	set := changeset.New(v.Root, fmt.Sprintf("Canonicalize %d skill(s)", len(dirs)))
+	set.Step("Rename the skill folders, SKILL.md, workflows and context files to the naming conventions")
+	set.Step("Rewrite the links to them and the ROUTING_TABLE.md and SYSTEM_INDEX.md rows, and set each SKILL.md name")
+	set.Step("Create the missing tools/ folders")
+	if err := plan.AddTo(set, "skill naming convention"); err != nil {
+		return err
+	}
+	for i, fix := range fixes {
+		if fix.NoTools {
+			keep := path.Join(plan.Final(dirs[i]), validate.ToolsDir, ".gitkeep")
+			if err := set.Create(keep, nil, "missing tools/ folder"); err != nil {
+				return err
+			}
+		}
+	}
+	if set.Len() == 0 {
+		// Only links below ## Notes, which are never rewritten, are off.
+		for _, r := range set.Risks {
+			fmt.Fprintln(e.stdout, r)
+		}
+		if len(set.Risks) > 0 {
+			return exitCode(1)
+		}
+		fmt.Fprintf(e.stdout, "%d skill(s) already canonical.\n", len(dirs))
+		return nil
+	}
+	printEdits(e, plan)
+	applied, err := e.apply(v, set, a)
+	if err != nil {
+		return err
+	}
+	if !applied || len(set.Risks) > 0 {
+		return exitCode(1)
+	}
+	return nil
+}
+
+// skillDirs returns the vault-relative skill folders named by args: a
+// skill name, the folder or its SKILL.md. With all, every folder under
+// the layout's skills location, none when the vault has no such folder.
+func skillDirs(v *vault.Vault, args []string, all bool) ([]string, error) {
+	if all {
+		dir, ok := v.Find(layout.Skills)
+		if !ok {
+			return nil, nil
+		}
+		entries, err := os.ReadDir(v.Abs(dir))
+		if err != nil {
+			return nil, err
+		}
+		var out []string
+		for _, en := range entries {
+			if en.IsDir() && !strings.HasPrefix(en.Name(), ".") {
+				out = append(out, path.Join(dir, en.Name()))
+			}
+		}
+		return out, nil
+	}
+	var out []string
+	for _, a := range args {
+		if !strings.ContainsAny(a, `/\`) {
+			if _, err := os.Stat(a); err != nil {
+				if dir, ok := v.Find(layout.Skills); ok {
+					out = append(out, path.Join(dir, a))
+					continue
+				}
+			}
+		}
+		rel, err := vaultPaths(v, []string{a})
+		if err != nil {
+			return nil, err
+		}
+		p := rel[0]
+		if strings.EqualFold(path.Base(p), validate.SkillFile) {
+			p = path.Dir(p)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// renameEntries updates the ROUTING_TABLE.md and SYSTEM_INDEX.md rows of
+// the skills and workflows plan renames. The plan already rewrites their
+// markdown links; this rewrites the names and the paths written as code
+// or plain text.
+func renameEntries(v *vault.Vault, plan *rename.Plan) error {
+	skills := v.Layout.Path(layout.Skills)
+	names := map[string]map[string]string{audit.SkillItem: {}, audit.WorkflowItem: {}}
+	for _, m := range plan.Moves {
+		from, to := path.Base(m.From), path.Base(m.To)
+		switch {
+		case strings.EqualFold(path.Dir(m.From), skills):
+			names[audit.SkillItem][from] = to
+		case strings.EqualFold(path.Base(path.Dir(m.From)), validate.WorkflowsDir) && strings.EqualFold(path.Ext(from), ".md"):
+			names[audit.WorkflowItem][strings.TrimSuffix(from, path.Ext(from))] = strings.TrimSuffix(to, path.Ext(to))
+		}
+	}
+
+	for _, key := range []string{layout.RoutingTable, layout.SystemIndex} {
+		doc, ok := audit.FindDoc(v, key)
+		if !ok {
+			continue
+		}
+		orig, err := os.ReadFile(v.Abs(doc))
+		if err != nil {
+			return err
+		}
+		final := plan.Final(doc)
+		data, err := plan.Text(final)
+		if err != nil {
+			return err
+		}
+		lines := strings.SplitAfter(string(data), "\n")
+		for _, en := range audit.ParseEntries(v, doc, string(orig), nil) {
+			line := lines[en.Line-1]
+			for _, p := range en.Paths {
+				if f := plan.Final(p); f != p {
+					line = replaceName(line, p, f)
+				}
+			}
+			if n, ok := names[en.Kind][en.Name]; ok {
+				line = replaceName(line, en.Name, n)
+			}
+			lines[en.Line-1] = line
+		}
+		if err := plan.Rewrite(final, []byte(strings.Join(lines, ""))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceName replaces each occurrence of old in s that is not part of a
+// longer name, so "create-skill" leaves "create-skill-v2" alone.
+func replaceName(s, old, new string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, old)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		end := i + len(old)
+		if (i > 0 && nameByte(s[i-1])) || (end < len(s) && nameByte(s[end])) {
+			b.WriteString(s[:end])
+		} else {
+			b.WriteString(s[:i] + new)
+		}
+		s = s[end:]
+	}
+}
+
+func nameByte(c byte) bool {
+	return c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,762 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/audit"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/changeset"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/frontmatter"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/tasks"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+func runTasksMigrate(e *env, args []string) error {
+	fs := newFlags(e, "tasks migrate")
+	a := approvalFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+
+	set := changeset.New(v.Root, "Migrate hashtag-style tasks to checkbox status")
+	set.Step("Rewrite #open, #in-progress and #done tasks in each domain's %s/ as checkbox tasks", vault.ProjectsDir)
+	total := 0
+	for _, d := range v.Domains {
+		files, err := v.Files(d, vault.ProjectsDir)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			data, err := os.ReadFile(v.Abs(f))
+			if err != nil {
+				return err
+			}
+			out, changes := tasks.Migrate(string(data))
+			for _, c := range changes {
+				fmt.Fprintf(e.stdout, "%s:%d\n  - %s\n  + %s\n", f, c.Line, c.Old, c.New)
+			}
+			total += len(changes)
+			if len(changes) == 0 {
+				continue
+			}
+			if err := set.Modify(f, []byte(out), fmt.Sprintf("%d task(s)", len(changes))); err != nil {
+				return err
+			}
+		}
+	}
+	if total == 0 {
+		fmt.Fprintln(e.stdout, "No hashtag-style tasks found.")
+		return nil
+	}
+	fmt.Fprintln(e.stdout)
+	applied, err := e.apply(v, set, a)
+	if err != nil {
+		return err
+	}
+	if applied {
+		fmt.Fprintf(e.stdout, "Migrated %d task(s).\n", total)
+	}
+	return nil
+}
+
+// runTasksPull is the pull_tasks workflow: it regenerates MASTER.md from
+// every project file and records the pull in the snapshot tasks sync
+// merges against. Tasks seen for the first time get a block ID in their
+// project file. A "## Notes" section at the end of MASTER.md is kept as it
+// is. Task edits in MASTER.md that were not synced yet stop the pull,
+// unless -force. Tasks keep the order of their project file unless -sort,
+// or the sort MASTER.md was last pulled with, says otherwise.
+func runTasksPull(e *env, args []string) error {
+	fs := newFlags(e, "tasks pull")
+	check := fs.Bool("check", false, "write nothing; exit 1 when MASTER.md is out of date")
+	print := fs.Bool("print", false, "print the generated MASTER.md instead of writing it; tasks without a block ID are shown without one")
+	force := fs.Bool("force", false, "overwrite task edits in MASTER.md that pal tasks sync has not pushed")
+	sortBy := fs.String("sort", "", "sort the tasks of each section by `order`: file, due or priority (default: as MASTER.md was last pulled)")
+	a := approvalFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *sortBy != "" {
+		if _, err := tasks.ParseOrder(*sortBy); err != nil {
+			return usagef("-sort is file, due or priority, not %q", *sortBy)
+		}
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	projects, err := tasks.ReadProjects(v)
+	if err != nil {
+		return err
+	}
+	var assigned map[string]int
+	if !*print {
+		// -print saves nothing, so it has no IDs to give.
+		printDuplicates(e, tasks.Duplicates(projects))
+		if _, assigned, err = assignIDs(projects); err != nil {
+			return err
+		}
+	}
+	master, ok := v.Find(layout.MasterTasks)
+	if !ok {
+		master = v.Layout.Path(layout.MasterTasks)
+	}
+	old, err := os.ReadFile(v.Abs(master))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	order := masterOrder(old)
+	if *sortBy != "" {
+		order, _ = tasks.ParseOrder(*sortBy)
+	}
+
+	switch {
+	case *print:
+		_, err := e.stdout.Write(masterText(projects, order, old))
+		return err
+	case *check:
+		if !bytes.Equal(old, masterText(projects, order, old)) || len(assigned) > 0 {
+			fmt.Fprintf(e.stdout, "%s is out of date; run pal tasks pull.\n", master)
+			return exitCode(1)
+		}
+		fmt.Fprintf(e.stdout, "%s is up to date.\n", master)
+		return nil
+	}
+	snapshot := statePath(v, tasks.SnapshotFile)
+	if len(old) > 0 && !*force {
+		base, err := tasks.ReadSnapshot(v.Abs(snapshot))
+		if err != nil {
+			return err
+		}
+		if syncs, _ := tasks.Merge(base, tasks.ParseMaster(string(old), projects), projects); len(syncs) > 0 {
+			n := 0
+			for _, s := range syncs {
+				n += len(s.Updates)
+			}
+			return fmt.Errorf("%s has %d task edit(s) not synced to the project files; run pal tasks sync, or pal tasks pull -force to drop them", master, n)
+		}
+	}
+
+	set := changeset.New(v.Root, "Pull the project tasks into "+master)
+	set.Step("Give every task without a block ID one in its project file")
+	set.Step("Regenerate %s from every project file, keeping its ## Notes section", master)
+	set.Step("Record the pull in %s for pal tasks sync", snapshot)
+	for _, p := range projects {
+		if n := assigned[p.Path]; n > 0 {
+			if err := set.Modify(p.Path, p.Bytes(), fmt.Sprintf("%d new task ID(s)", n)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := pullInto(v, set, master, old, projects, order); err != nil {
+		return err
+	}
+	if set.Len() == 0 {
+		fmt.Fprintf(e.stdout, "%s is up to date.\n", master)
+		return nil
+	}
+	applied, err := e.apply(v, set, a)
+	if err != nil || !applied {
+		return err
+	}
+	n := 0
+	for _, p := range projects {
+		n += len(p.Tasks)
+	}
+	fmt.Fprintf(e.stdout, "Pulled %d task(s) from %d project(s) into %s.\n", n, len(projects), master)
+	return nil
+}
+
+// runTasksSync is the update_plan workflow (requirements 1.5.6 to 1.5.9):
+// it three-way merges MASTER.md, the snapshot of the last pull and the
+// project files, pushes the MASTER.md edits back to the project files,
+// logs them by task ID, dates the domains' Active Work rows and pulls
+// again. Conflicts and tasks deleted from MASTER.md are asked about, or
+// resolved by -conflicts and -deleted.
+func runTasksSync(e *env, args []string) error {
+	fs := newFlags(e, "tasks sync")
+	conflicts := fs.String("conflicts", "ask", "resolve a project with conflicts by `mode`: ask, force, skip or manual")
+	deleted := fs.String("deleted", "ask", "resolve a task deleted from MASTER.md by `mode`: ask, archive, restore or delete")
+	a := approvalFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if !oneOf(*conflicts, "ask", "force", "skip", "manual") {
+		return usagef("-conflicts is ask, force, skip or manual, not %q", *conflicts)
+	}
+	if !oneOf(*deleted, "ask", "archive", "restore", "delete") {
+		return usagef("-deleted is ask, archive, restore or delete, not %q", *deleted)
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	projects, err := tasks.ReadProjects(v)
+	if err != nil {
+		return err
+	}
+	if dups := tasks.Duplicates(projects); len(dups) > 0 {
+		printDuplicates(e, dups)
+		return fmt.Errorf("%d task(s) repeat another task's ID; run pal tasks repair first", len(dups))
+	}
+	ids, assigned, err := assignIDs(projects)
+	if err != nil {
+		return err
+	}
+	master, ok := v.Find(layout.MasterTasks)
+	if !ok {
+		return fmt.Errorf("%s not found; run pal tasks pull first", v.Layout.Path(layout.MasterTasks))
+	}
+	text, err := os.ReadFile(v.Abs(master))
+	if err != nil {
+		return err
+	}
+	snapshot := statePath(v, tasks.SnapshotFile)
+	base, err := tasks.ReadSnapshot(v.Abs(snapshot))
+	if err != nil {
+		return err
+	}
+	syncs, orphans := tasks.Merge(base, tasks.ParseMaster(string(text), projects), projects)
+	for _, o := range orphans {
+		fmt.Fprintf(e.stdout, "%s:%d: no project claims %q; add its project's #tag or move it under the project's heading\n", master, o.Line, o.Text)
+	}
+
+	// One reader for every answer, apply's included.
+	in := bufio.NewReader(e.stdin)
+	e.stdin = in
+	set := changeset.New(v.Root, "Push MASTER.md task edits to the project files")
+	set.Step("Give every task without a block ID one")
+	set.Step("Apply the status changes, rewordings, new tasks, moves and deletions made in %s to each PROJECT_*.md, moving tasks to the section of their status", master)
+	set.Step("Date completed Tasks-plugin tasks and add the next occurrence of recurring ones")
+	set.Step("Log the changes by task ID in %s", statePath(v, tasks.HistoryFile))
+	set.Step("Date the Active Work rows of the changed projects in their domain's %s", vault.IndexFile)
+	set.Step("Pull again: regenerate %s and the snapshot in %s", master, snapshot)
+	unresolved := len(orphans)
+	now := time.Now()
+	why := map[string][]string{}
+	for p, n := range assigned {
+		why[p] = append(why[p], fmt.Sprintf("%d task ID(s) assigned", n))
+	}
+	var changed []*tasks.Project
+	var history []byte
+	for _, s := range syncs {
+		updates, left := resolveSync(e, in, s, *conflicts, *deleted)
+		unresolved += left
+		updates, unknown := tasks.Complete(updates, now)
+		for _, t := range unknown {
+			fmt.Fprintf(e.stdout, "%s: cannot follow the recurrence %q of %q; add its next occurrence by hand\n", s.Project.Path, t.Recurrence, t.Text)
+		}
+		if len(updates) == 0 {
+			continue
+		}
+		for i := range updates {
+			if updates[i].Kind == tasks.AddTask && updates[i].Task.ID == "" {
+				updates[i].Task.ID = ids.New()
+			}
+		}
+		p, err := s.Project.WithText(s.Project.Apply(updates))
+		if err != nil {
+			return err
+		}
+		for i := range projects {
+			if projects[i] == s.Project {
+				projects[i] = p
+			}
+		}
+		why[p.Path] = append(why[p.Path], describeUpdates(updates))
+		history = append(history, tasks.History(s.Project, updates, now)...)
+		changed = append(changed, p)
+	}
+	for _, p := range projects {
+		if len(why[p.Path]) > 0 {
+			if err := set.Modify(p.Path, p.Bytes(), strings.Join(why[p.Path], ", ")); err != nil {
+				return err
+			}
+		}
+	}
+	if len(history) > 0 {
+		if err := appendState(v, set, statePath(v, tasks.HistoryFile), history, "task changes pushed"); err != nil {
+			return err
+		}
+	}
+	if err := dateActiveWork(v, set, changed, now); err != nil {
+		return err
+	}
+	if unresolved == 0 {
+		if err := pullInto(v, set, master, text, projects, masterOrder(text)); err != nil {
+			return err
+		}
+	} else {
+		set.Risk("%d task(s) are left unresolved, so %s keeps its edits and is not pulled again", unresolved, master)
+	}
+
+	if set.Len() == 0 {
+		if unresolved > 0 {
+			fmt.Fprintf(e.stdout, "%d task(s) left to resolve by hand in %s or the project files; then run pal tasks sync again.\n", unresolved, master)
+			return exitCode(1)
+		}
+		fmt.Fprintf(e.stdout, "%s and the project files are in sync.\n", master)
+		return nil
+	}
+	fmt.Fprintln(e.stdout)
+	applied, err := e.apply(v, set, a)
+	if err != nil {
+		return err
+	}
+	if applied {
+		fmt.Fprintf(e.stdout, "Synced %d project(s).\n", len(changed))
+	}
+	if unresolved > 0 {
+		fmt.Fprintf(e.stdout, "%d task(s) left to resolve by hand in %s or the project files; then run pal tasks sync again.\n", unresolved, master)
+		return exitCode(1)
+	}
+	return nil
+}
+
+// resolveSync turns the conflicts and tombstones of s into updates, asking
+// on in for each mode left at "ask". left counts the conflicts left for
+// manual review.
+func resolveSync(e *env, in *bufio.Reader, s *tasks.Sync, conflicts, deleted string) (updates []tasks.Update, left int) {
+	p := s.Project
+	var clash []tasks.Update
+	for _, u := range s.Updates {
+		if u.Kind == tasks.Conflict {
+			clash = append(clash, u)
+		}
+	}
+	if len(clash) > 0 {
+		fmt.Fprintf(e.stdout, "%s changed since the last pull and conflicts with MASTER.md:\n", p.Path)
+		for _, u := range clash {
+			fmt.Fprintf(e.stdout, "  %s:%d: %s; [%c] at the last pull, [%c] in the project, [%c] in MASTER.md\n",
+				p.Path, u.Task.Line, u.Task.Text, u.Base, u.Task.Status, u.Status)
+			if u.Text != "" {
+				fmt.Fprintf(e.stdout, "    reworded in MASTER.md: %s\n", u.Text)
+			}
+		}
+		if conflicts == "ask" {
+			fmt.Fprintf(e.stderr, "[f]orce the MASTER.md status, [s]kip the project or [m]anual review? [m] ")
+			switch answer(in) {
+			case "f", "force":
+				conflicts = "force"
+			case "s", "skip":
+				conflicts = "skip"
+			default:
+				conflicts = "manual"
+			}
+		}
+		if conflicts == "skip" {
+			fmt.Fprintf(e.stdout, "Skipped %s.\n", p.Path)
+			return nil, len(clash)
+		}
+	}
+
+	for _, u := range s.Updates {
+		switch u.Kind {
+		case tasks.Conflict:
+			if conflicts != "force" {
+				left++
+				continue
+			}
+			u.Kind = tasks.SetStatus
+		case tasks.Tombstone:
+			mode := deleted
+			if mode == "ask" {
+				fmt.Fprintf(e.stdout, "%s:%d: %q was deleted from MASTER.md.\n", p.Path, u.Task.Line, u.Task.Text)
+				as := "not doing"
+				if u.Task.Status == tasks.Done {
+					as = "done"
+				}
+				fmt.Fprintf(e.stderr, "[a]rchive it as %s, [r]estore it or [d]elete it from the project? [r] ", as)
+				switch answer(in) {
+				case "a", "archive":
+					mode = "archive"
+				case "d", "delete":
+					mode = "delete"
+				default:
+					mode = "restore"
+				}
+			}
+			switch mode {
+			case "archive":
+				// A done task stays [x] in Done, the record that it was
+				// completed.
+				if u.Task.Status == tasks.NotDoing || u.Task.Status == tasks.Done {
+					continue
+				}
+				u.Kind, u.Status = tasks.SetStatus, tasks.NotDoing
+			case "delete":
+				u.Kind = tasks.RemoveTask
+			default:
+				continue // the pull puts it back in MASTER.md
+			}
+		}
+		updates = append(updates, u)
+	}
+	return updates, left
+}
+
+func answer(in *bufio.Reader) string {
+	s, _ := in.ReadString('\n')
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// describeUpdates summarises updates for the plan.
+func describeUpdates(updates []tasks.Update) string {
+	n := map[tasks.UpdateKind]int{}
+	reworded, meta := 0, 0
+	for _, u := range updates {
+		if u.Kind != tasks.SetStatus || u.Status != u.Task.Status {
+			n[u.Kind]++
+		}
+		if u.Text != "" {
+			reworded++
+		}
+		if u.Meta != nil && *u.Meta != u.Task.Meta {
+			meta++
+		}
+	}
+	var parts []string
+	if n[tasks.SetStatus] > 0 {
+		parts = append(parts, fmt.Sprintf("%d status change(s)", n[tasks.SetStatus]))
+	}
+	if reworded > 0 {
+		parts = append(parts, fmt.Sprintf("%d task(s) reworded", reworded))
+	}
+	if meta > 0 {
+		parts = append(parts, fmt.Sprintf("%d task(s) redated or reprioritised", meta))
+	}
+	if n[tasks.AddTask] > 0 {
+		parts = append(parts, fmt.Sprintf("%d task(s) added", n[tasks.AddTask]))
+	}
+	if n[tasks.RemoveTask] > 0 {
+		parts = append(parts, fmt.Sprintf("%d task(s) removed", n[tasks.RemoveTask]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// dateActiveWork sets the Last Updated cell of the Active Work rows that
+// list the changed projects, and the INDEX.md updated field, to today
+// (requirement 1.5.6).
+func dateActiveWork(v *vault.Vault, set *changeset.Set, changed []*tasks.Project, now time.Time) error {
+	today := now.Format("2006-01-02")
+	for _, d := range v.Domains {
+		if d.Index == nil {
+			continue
+		}
+		var rows []vault.ActiveWork
+		for _, p := range changed {
+			if p.Domain != d.Name {
+				continue
+			}
+			for _, vp := range d.Projects {
+				if vp.Path != p.Path {
+					continue
+				}
+				for _, w := range d.Index.ActiveWork {
+					if audit.ListsProject(w, vp) && w.LastUpdated != today {
+						rows = append(rows, w)
+					}
+				}
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		data, err := os.ReadFile(v.Abs(d.Index.Path))
+		if err != nil {
+			return err
+		}
+		lines := strings.SplitAfter(string(data), "\n")
+		for _, w := range rows {
+			i := w.Line - 1
+			if col := lastUpdatedColumn(lines, i); col >= 0 {
+				lines[i] = markdown.SetCell(lines[i], col, today)
+			}
+		}
+		out := []byte(strings.Join(lines, ""))
+		if doc, err := frontmatter.Parse(out); err == nil && doc.Has("updated") {
+			if err := doc.Set("updated", frontmatter.Date(now)); err != nil {
+				return err
+			}
+			out = doc.Bytes()
+		}
+		if err := set.Modify(d.Index.Path, out, fmt.Sprintf("%d Active Work row(s) dated %s", len(rows), today)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lastUpdatedColumn returns the index of the Last Updated column of the
+// table holding line i, or -1.
+func lastUpdatedColumn(lines []string, i int) int {
+	h := i
+	for h > 0 && strings.HasPrefix(strings.TrimSpace(lines[h-1]), "|") {
+		h--
+	}
+	t := markdown.Table{Header: markdown.SplitRow(lines[h])}
+	return t.Column("Last Updated")
+}
+
+// pullInto adds the regenerated MASTER.md, sorted by order and keeping
+// the "## Notes" tail of old, and the snapshot of projects to set.
+func pullInto(v *vault.Vault, set *changeset.Set, master string, old []byte, projects []*tasks.Project, order tasks.Order) error {
+	if err := putState(v, set, master, masterText(projects, order, old), "pulled from the project files"); err != nil {
+		return err
+	}
+	return putState(v, set, statePath(v, tasks.SnapshotFile), tasks.NewSnapshot(projects).Marshal(), "snapshot of the pull")
+}
+
+// masterText returns MASTER.md generated from projects, with the
+// "## Notes" tail of old, the file as it is now.
+func masterText(projects []*tasks.Project, order tasks.Order, old []byte) []byte {
+	out := tasks.Master(projects, order)
+	if tail, ok := frontmatter.ProtectedTail(old); ok {
+		out = append(append(out, '\n'), tail...)
+	}
+	return out
+}
+
+// putState sets the content of the vault-relative file rel, creating it
+// if needed.
+func putState(v *vault.Vault, set *changeset.Set, rel string, data []byte, why string) error {
+	if _, err := os.Stat(v.Abs(rel)); errors.Is(err, os.ErrNotExist) {
+		return set.Create(rel, data, why)
+	}
+	return set.Modify(rel, data, why)
+}
+
+// masterOrder returns the sort MASTER.md, as read in old, was pulled with:
+// its "sort" frontmatter field, ByFile when it has none or names no order.
+func masterOrder(old []byte) tasks.Order {
+	doc, err := frontmatter.Parse(old)
+	if err != nil {
+		return tasks.ByFile
+	}
+	s, _ := doc.Get("sort")
+	order, _ := tasks.ParseOrder(s)
+	return order
+}
+
+// appendState adds data to the end of the vault-relative state file rel,
+// creating it if needed.
+func appendState(v *vault.Vault, set *changeset.Set, rel string, data []byte, why string) error {
+	old, err := os.ReadFile(v.Abs(rel))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return set.Create(rel, data, why)
+	case err != nil:
+		return err
+	}
+	return set.Modify(rel, append(old, data...), why)
+}
+
+// assignIDs gives every task of projects that has no block ID one,
+// replacing the projects in place. It returns the IDs in use and the
+// number assigned per project file.
+func assignIDs(projects []*tasks.Project) (tasks.IDs, map[string]int, error) {
+	ids := tasks.TakenIDs(projects)
+	assigned := map[string]int{}
+	for i, p := range projects {
+		np, n, err := p.AssignIDs(ids)
+		if err != nil {
+			return nil, nil, err
+		}
+		if n > 0 {
+			projects[i] = np
+			assigned[p.Path] = n
+		}
+	}
+	return ids, assigned, nil
+}
+
+func printDuplicates(e *env, dups []tasks.Duplicate) {
+	for _, d := range dups {
+		fmt.Fprintf(e.stderr, "%s:%d: task ID ^%s is also on %s:%d; run pal tasks repair\n", d.Project.Path, d.Task.Line, d.ID, d.First.Path, d.FirstLine)
+	}
+}
+
+// statePath returns the vault-relative path of rel in the state folder.
+func statePath(v *vault.Vault, rel string) string {
+	state, _ := v.Find(layout.State)
+	return path.Join(state, rel)
+}
+
+func oneOf(s string, list ...string) bool {
+	for _, x := range list {
+		if s == x {
+			return true
+		}
+	}
+	return false
+}
+
+// runTasksRepair gives a new block ID to every task that repeats an
+// earlier task's ID, most often a line copied with its ID, and an ID to
+// every task without one. The MASTER.md line and the snapshot entry of a
+// task that gets a new ID follow it, so the next sync still knows the
+// task.
+func runTasksRepair(e *env, args []string) error {
+	fs := newFlags(e, "tasks repair")
+	a := approvalFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("usage: pal tasks repair [-yes] [-dry-run] [-save-plan]")
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	projects, err := tasks.ReadProjects(v)
+	if err != nil {
+		return err
+	}
+	orig := append([]*tasks.Project{}, projects...)
+	dups := tasks.Duplicates(projects)
+	ids := tasks.TakenIDs(projects)
+
+	set := changeset.New(v.Root, "Repair task block IDs")
+	set.Step("Give each task that repeats an earlier task's block ID a new one; the first task with the ID keeps it")
+	set.Step("Give every task without a block ID one")
+	set.Step("Follow the new IDs in MASTER.md and in the snapshot of the last pull")
+	lines := map[string][]int{}
+	for _, d := range dups {
+		lines[d.Project.Path] = append(lines[d.Project.Path], d.Task.Line)
+	}
+	newID := map[string]map[int]string{}
+	why := map[string][]string{}
+	for i, p := range projects {
+		if ls := lines[p.Path]; len(ls) > 0 {
+			np, got, err := p.ReID(ls, ids)
+			if err != nil {
+				return err
+			}
+			projects[i], newID[p.Path] = np, got
+			why[p.Path] = append(why[p.Path], fmt.Sprintf("%d duplicated ID(s) replaced", len(ls)))
+		}
+	}
+	for _, d := range dups {
+		fmt.Fprintf(e.stdout, "%s:%d: ^%s is also on %s:%d; new ID ^%s\n", d.Project.Path, d.Task.Line, d.ID, d.First.Path, d.FirstLine, newID[d.Project.Path][d.Task.Line])
+	}
+	_, assigned, err := assignIDs(projects)
+	if err != nil {
+		return err
+	}
+	for p, n := range assigned {
+		why[p] = append(why[p], fmt.Sprintf("%d task ID(s) assigned", n))
+	}
+	for _, p := range projects {
+		if len(why[p.Path]) > 0 {
+			if err := set.Modify(p.Path, p.Bytes(), strings.Join(why[p.Path], ", ")); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(dups) > 0 {
+		if err := followIDs(v, set, orig, dups, newID); err != nil {
+			return err
+		}
+	}
+	if set.Len() == 0 {
+		fmt.Fprintln(e.stdout, "Every task has a block ID of its own.")
+		return nil
+	}
+	fmt.Fprintln(e.stdout)
+	applied, err := e.apply(v, set, a)
+	if err != nil {
+		return err
+	}
+	if applied {
+		n := 0
+		for _, k := range assigned {
+			n += k
+		}
+		fmt.Fprintf(e.stdout, "Replaced %d duplicated ID(s) and assigned %d.\n", len(dups), n)
+	}
+	return nil
+}
+
+// followIDs gives the MASTER.md line and the snapshot entry of each
+// duplicate the duplicate's new ID. Only a line or entry with the
+// duplicate's text counts; when the task keeping the ID has that text too,
+// in the same project, the later of two such lines does. Anything else is
+// left for the next sync to match by text.
+func followIDs(v *vault.Vault, set *changeset.Set, projects []*tasks.Project, dups []tasks.Duplicate, newID map[string]map[int]string) error {
+	need := func(d tasks.Duplicate) int {
+		if d.First == d.Project {
+			for _, t := range d.First.Tasks {
+				if t.Line == d.FirstLine && t.Text == d.Task.Text {
+					return 2
+				}
+			}
+		}
+		return 1
+	}
+
+	if master, ok := v.Find(layout.MasterTasks); ok {
+		data, err := os.ReadFile(v.Abs(master))
+		if err != nil {
+			return err
+		}
+		lines := strings.SplitAfter(string(data), "\n")
+		n := 0
+		mts := tasks.ParseMaster(string(data), projects)
+		for _, d := range dups {
+			var at []int
+			for _, mt := range mts {
+				if mt.ID == d.ID && mt.Project == d.Project && mt.Text == d.Task.Text {
+					at = append(at, mt.Line)
+				}
+			}
+			if len(at) < need(d) {
+				continue
+			}
+			i := at[len(at)-1] - 1
+			l := strings.TrimRight(lines[i], "\r\n")
+			lines[i] = tasks.WithID(l, newID[d.Project.Path][d.Task.Line]) + lines[i][len(l):]
+			n++
+		}
+		if err := set.Modify(master, []byte(strings.Join(lines, "")), fmt.Sprintf("%d task line(s) follow their new ID", n)); err != nil {
+			return err
+		}
+	}
+
+	snapshot := statePath(v, tasks.SnapshotFile)
+	base, err := tasks.ReadSnapshot(v.Abs(snapshot))
+	if err != nil || len(base.Projects) == 0 {
+		return err
+	}
+	for _, d := range dups {
+		for i := range base.Projects {
+			sp := &base.Projects[i]
+			if sp.Path != d.Project.Path {
+				continue
+			}
+			var at []int
+			for j, t := range sp.Tasks {
+				if t.ID == d.ID && t.Text == d.Task.Text {
+					at = append(at, j)
+				}
+			}
+			if len(at) >= need(d) {
+				sp.Tasks[at[len(at)-1]].ID = newID[d.Project.Path][d.Task.Line]
+			}
+		}
+	}
+	return set.Modify(snapshot, base.Marshal(), "snapshot entries follow their new ID")
+}
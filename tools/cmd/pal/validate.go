@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/audit"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/validate"
+)
+
+func runValidateAgent(e *env, args []string) error {
+	fs := newFlags(e, "validate agent")
+	all := fs.Bool("all", false, "validate every agent in .claude/agents/")
+	asJSON := fs.Bool("json", false, "print the findings as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *all == (fs.NArg() > 0) {
+		return usagef("usage: pal validate agent [-json] (-all | FILE...)")
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	files, err := vaultPaths(v, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *all {
+		items, err := audit.Items(v)
+		if err != nil {
+			return err
+		}
+		for _, it := range items {
+			if it.Kind == audit.AgentItem {
+				files = append(files, it.Path)
+			}
+		}
+	}
+
+	var findings []audit.Finding
+	failed := map[string]bool{}
+	for _, f := range files {
+		found, err := validate.Agent(v, f)
+		if err != nil {
+			return err
+		}
+		for _, x := range found {
+			failed[x.Path] = true
+		}
+		findings = append(findings, found...)
+	}
+	if err := printFindings(e, findings, *asJSON); err != nil {
+		if !*asJSON {
+			fmt.Fprintf(e.stdout, "\n%d agent(s) checked, %d with problems.\n", len(files), len(failed))
+		}
+		return err
+	}
+	if !*asJSON {
+		fmt.Fprintf(e.stdout, "%d agent(s) checked, all valid.\n", len(files))
+	}
+	return nil
+}
+
+func runValidateSkill(e *env, args []string) error {
+	fs := newFlags(e, "validate skill")
+	all := fs.Bool("all", false, "validate every skill in .claude/skills/")
+	asJSON := fs.Bool("json", false, "print the findings as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *all == (fs.NArg() > 0) {
+		return usagef("usage: pal validate skill [-json] (-all | SKILL...)")
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+	dirs, err := skillDirs(v, fs.Args(), *all)
+	if err != nil {
+		return err
+	}
+
+	var findings []audit.Finding
+	failed := map[string]bool{}
+	for _, d := range dirs {
+		found, err := validate.Skill(v, d)
+		if err != nil {
+			return err
+		}
+		if len(found) > 0 {
+			failed[d] = true
+		}
+		findings = append(findings, found...)
+	}
+	if err := printFindings(e, findings, *asJSON); err != nil {
+		if !*asJSON {
+			fmt.Fprintf(e.stdout, "\n%d skill(s) checked, %d with problems. pal skill canonicalize fixes the names and tools/ folders.\n", len(dirs), len(failed))
+		}
+		return err
+	}
+	if !*asJSON {
+		fmt.Fprintf(e.stdout, "%d skill(s) checked, all valid.\n", len(dirs))
+	}
+	return nil
+}
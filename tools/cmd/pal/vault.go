@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/connections"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+func runVault(e *env, args []string) error {
+	fs := newFlags(e, "vault")
+	asJSON := fs.Bool("json", false, "print the vault as JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	v, err := e.load()
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(vaultView(v))
+	}
+
+	for _, d := range v.Domains {
+		fmt.Fprintf(e.stdout, "%s (%s)\n", d.Name, d.Path)
+		if d.Index != nil {
+			fmt.Fprintf(e.stdout, "  index: %s [%s] agent=%s\n", d.Index.Name, d.Index.Status, d.Index.DomainAgent)
+			for _, w := range d.Index.ActiveWork {
+				fmt.Fprintf(e.stdout, "  active work: %s [%s] %s\n", w.Project, w.Status, w.LastUpdated)
+			}
+		}
+		for _, p := range d.Projects {
+			fmt.Fprintf(e.stdout, "  project: %s\n", p.Path)
+		}
+		for _, p := range d.Pages {
+			fmt.Fprintf(e.stdout, "  page: %s\n", p.Path)
+		}
+		for _, s := range d.Sessions {
+			fmt.Fprintf(e.stdout, "  session: %s\n", s.Path)
+		}
+		for _, c := range d.Connections {
+			fmt.Fprintf(e.stdout, "  connection: %s (%s)\n", c.Name, c.Type)
+		}
+		for _, p := range d.Problems {
+			fmt.Fprintf(e.stdout, "  problem: %v\n", p)
+		}
+	}
+	return nil
+}
+
+type domainJSON struct {
+	Name        string                   `json:"name"`
+	Path        string                   `json:"path"`
+	Index       *indexJSON               `json:"index,omitempty"`
+	Folders     map[string]bool          `json:"folders"`
+	Extra       []string                 `json:"extra_folders,omitempty"`
+	Connections []connections.Connection `json:"connections,omitempty"`
+	Projects    []string                 `json:"projects,omitempty"`
+	Pages       []string                 `json:"pages,omitempty"`
+	Sessions    []string                 `json:"sessions,omitempty"`
+	Problems    []string                 `json:"problems,omitempty"`
+}
+
+type indexJSON struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Status      string             `json:"status"`
+	Created     string             `json:"created"`
+	Updated     string             `json:"updated"`
+	DomainAgent string             `json:"domain_agent"`
+	ActiveWork  []vault.ActiveWork `json:"active_work,omitempty"`
+}
+
+func vaultView(v *vault.Vault) []domainJSON {
+	out := []domainJSON{}
+	for _, d := range v.Domains {
+		j := domainJSON{
+			Name:        d.Name,
+			Path:        d.Path,
+			Folders:     d.Folders,
+			Extra:       d.Extra,
+			Connections: d.Connections,
+		}
+		if d.Index != nil {
+			j.Index = &indexJSON{
+				Name:        d.Index.Name,
+				Description: d.Index.Description,
+				Status:      d.Index.Status,
+				Created:     d.Index.Created,
+				Updated:     d.Index.Updated,
+				DomainAgent: d.Index.DomainAgent,
+				ActiveWork:  d.Index.ActiveWork,
+			}
+		}
+		for _, p := range d.Projects {
+			j.Projects = append(j.Projects, p.Path)
+		}
+		for _, p := range d.Pages {
+			j.Pages = append(j.Pages, p.Path)
+		}
+		for _, s := range d.Sessions {
+			j.Sessions = append(j.Sessions, s.Path)
+		}
+		for _, p := range d.Problems {
+			j.Problems = append(j.Problems, p.Error())
+		}
+		out = append(out, j)
+	}
+	return out
+}
@@ -0,0 +1,361 @@
+// Package connections reads, validates and migrates a domain's
+// CONNECTIONS.yaml. The current schema is one versioned list:
+//
+//	version: 1
+//	connections:
+//	  - name: Claude Code Documentation
+//	    type: documentation
+//	    url: https://docs.anthropic.com/claude-code
+//	    purpose: Official SDK documentation
+//	  - name: system-build skill
+//	    type: internal_reference
+//	    location: .claude/skills/system-build/SKILL.md
+//
+// Two older shapes are still read: a "connections:" list without a version
+// (type api or file) and the grouped apis / documentation / data_sources /
+// internal_references lists. Migrate rewrites either one into the current
+// schema.
+package connections
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the current schema version.
+const Version = 1
+
+// Connection types.
+const (
+	API               = "api"
+	File              = "file"
+	Documentation     = "documentation"
+	DataSource        = "data_source"
+	InternalReference = "internal_reference"
+)
+
+// Types are the valid connection types.
+var Types = []string{API, File, Documentation, DataSource, InternalReference}
+
+// Connection is one external source or integration of a domain.
+type Connection struct {
+	Name string `yaml:"name" json:"name"`
+	Type string `yaml:"type" json:"type"`
+	// URL locates api, documentation and data_source connections.
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+	// Path is the file a file connection reads, absolute or relative to the
+	// domain folder.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// Location is the vault-relative file an internal_reference points to.
+	Location string `yaml:"location,omitempty" json:"location,omitempty"`
+	Purpose  string `yaml:"purpose,omitempty" json:"purpose,omitempty"`
+
+	// Line is the 1-based line the entry starts on; zero for migrated
+	// entries.
+	Line int `yaml:"-" json:"-"`
+}
+
+// Shape identifies which CONNECTIONS.yaml layout a file uses.
+type Shape int
+
+const (
+	Current Shape = iota
+	// LegacyList is an unversioned "connections:" list.
+	LegacyList
+	// LegacyGrouped is the apis / documentation / data_sources /
+	// internal_references layout.
+	LegacyGrouped
+)
+
+func (s Shape) String() string {
+	switch s {
+	case LegacyList:
+		return "legacy connections list"
+	case LegacyGrouped:
+		return "legacy grouped lists"
+	}
+	return fmt.Sprintf("version %d", Version)
+}
+
+// Set is a parsed CONNECTIONS.yaml.
+type Set struct {
+	Version     int
+	Shape       Shape
+	Connections []Connection
+}
+
+// groups maps the legacy grouped keys to connection types, in file order.
+var groups = []struct{ key, typ string }{
+	{"apis", API},
+	{"documentation", Documentation},
+	{"data_sources", DataSource},
+	{"internal_references", InternalReference},
+}
+
+// legacyEntry is an entry of the grouped layout, which called the purpose
+// "notes".
+type legacyEntry struct {
+	Name     string `yaml:"name"`
+	URL      string `yaml:"url"`
+	Path     string `yaml:"path"`
+	Location string `yaml:"location"`
+	Notes    string `yaml:"notes"`
+	Purpose  string `yaml:"purpose"`
+}
+
+// Parse reads a CONNECTIONS.yaml in any supported shape. Unknown top-level
+// keys and unknown entry fields are errors so that typos do not silently
+// drop a connection.
+func Parse(data []byte) (*Set, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	s := &Set{}
+	if doc.Kind == 0 {
+		return s, nil // empty file
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a mapping at the top level", root.Line)
+	}
+
+	keys := map[string]*yaml.Node{}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		keys[root.Content[i].Value] = root.Content[i+1]
+	}
+	grouped := false
+	for _, g := range groups {
+		if _, ok := keys[g.key]; ok {
+			grouped = true
+		}
+	}
+	_, versioned := keys["version"]
+
+	switch {
+	case grouped && !versioned:
+		s.Shape = LegacyGrouped
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			k := root.Content[i]
+			if !isGroup(k.Value) {
+				return nil, fmt.Errorf("line %d: unknown key %q", k.Line, k.Value)
+			}
+		}
+		for _, g := range groups {
+			list, ok := keys[g.key]
+			if !ok || list.Tag == "!!null" {
+				continue
+			}
+			if list.Kind != yaml.SequenceNode {
+				return nil, fmt.Errorf("line %d: %s must be a list", list.Line, g.key)
+			}
+			for _, n := range list.Content {
+				var e legacyEntry
+				if err := decodeStrict(n, &e); err != nil {
+					return nil, err
+				}
+				purpose := e.Purpose
+				if purpose == "" {
+					purpose = e.Notes
+				}
+				s.Connections = append(s.Connections, Connection{
+					Name: e.Name, Type: g.typ, URL: e.URL, Path: e.Path,
+					Location: e.Location, Purpose: purpose, Line: n.Line,
+				})
+			}
+		}
+		return s, nil
+
+	case !versioned:
+		s.Shape = LegacyList
+	default:
+		if err := keys["version"].Decode(&s.Version); err != nil {
+			return nil, fmt.Errorf("line %d: version must be a number", keys["version"].Line)
+		}
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if k := root.Content[i]; k.Value != "version" && k.Value != "connections" {
+			return nil, fmt.Errorf("line %d: unknown key %q", k.Line, k.Value)
+		}
+	}
+	list, ok := keys["connections"]
+	if !ok || list.Tag == "!!null" {
+		return s, nil
+	}
+	if list.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("line %d: connections must be a list", list.Line)
+	}
+	for _, n := range list.Content {
+		var c Connection
+		if err := decodeStrict(n, &c); err != nil {
+			return nil, err
+		}
+		c.Line = n.Line
+		s.Connections = append(s.Connections, c)
+	}
+	return s, nil
+}
+
+func isGroup(key string) bool {
+	for _, g := range groups {
+		if g.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeStrict decodes n into v, rejecting keys v has no yaml field for.
+func decodeStrict(n *yaml.Node, v any) error {
+	if n.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping", n.Line)
+	}
+	known := map[string]bool{}
+	t := reflect.TypeOf(v).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		known[name] = true
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if k := n.Content[i]; !known[k.Value] || k.Value == "-" {
+			return fmt.Errorf("line %d: unknown field %q", k.Line, k.Value)
+		}
+	}
+	if err := n.Decode(v); err != nil {
+		return fmt.Errorf("line %d: %w", n.Line, err)
+	}
+	return nil
+}
+
+// header documents the schema at the top of every migrated file.
+const header = `# Domain connections: external sources and integrations of this domain.
+#
+# type                required  meaning
+# api                 url       external API
+# file                path      file or folder, absolute or relative to this domain
+# documentation       url       reference documentation
+# data_source         url       dataset or feed
+# internal_reference  location  vault file, relative to the vault root`
+
+// Migrate converts a legacy CONNECTIONS.yaml into the current schema.
+// changed is false, and data is returned as is, when the file already uses
+// it. It edits the YAML tree rather than re-encoding the connections, so
+// comments, field order and quoting survive; the comment above a legacy
+// group moves to the group's first entry.
+func Migrate(data []byte) (out []byte, from Shape, changed bool, err error) {
+	s, err := Parse(data)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if s.Shape == Current {
+		return data, Current, false, nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, 0, false, err
+	}
+	root := doc.Content[0]
+	root.Style = 0 // "{}" grows a key
+	if s.Shape == LegacyGrouped {
+		ungroup(root)
+	}
+	root.Content = append([]*yaml.Node{scalar("version"), {Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprint(Version)}}, root.Content...)
+	if doc.HeadComment != "" {
+		doc.HeadComment = header + "\n\n" + doc.HeadComment
+	} else {
+		doc.HeadComment = header
+	}
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, 0, false, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, 0, false, err
+	}
+	return b.Bytes(), s.Shape, true, nil
+}
+
+// ungroup replaces the legacy group keys of root with one connections
+// list: each entry gets the type of its group after its name, and notes
+// becomes purpose. The comments of an empty group go to the next entry,
+// or below the list when none follows.
+func ungroup(root *yaml.Node) {
+	keys := map[string][2]*yaml.Node{}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		keys[root.Content[i].Value] = [2]*yaml.Node{root.Content[i], root.Content[i+1]}
+	}
+	list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	var pending string
+	for _, g := range groups {
+		kv, ok := keys[g.key]
+		if !ok {
+			continue
+		}
+		k, v := kv[0], kv[1]
+		pending = joinComments(pending, k.HeadComment, k.LineComment, v.LineComment, k.FootComment)
+		for _, n := range v.Content {
+			retype(n, g.typ)
+			n.HeadComment, pending = joinComments(pending, n.HeadComment), ""
+			list.Content = append(list.Content, n)
+		}
+	}
+	if len(list.Content) == 0 {
+		list.Style = yaml.FlowStyle
+	}
+	key := scalar("connections")
+	key.FootComment = pending
+	root.Content = []*yaml.Node{key, list}
+}
+
+// retype gives the legacy entry n its type and renames its notes to
+// purpose, which Parse prefers when an entry has both.
+func retype(n *yaml.Node, typ string) {
+	at, purpose := 0, false
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		switch n.Content[i].Value {
+		case "name":
+			at = i + 2
+		case "purpose":
+			purpose = true
+		}
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value != "notes" {
+			continue
+		}
+		if purpose {
+			n.Content = append(n.Content[:i], n.Content[i+2:]...)
+			if at > i {
+				at -= 2
+			}
+		} else {
+			n.Content[i].Value = "purpose"
+		}
+		break
+	}
+	pair := []*yaml.Node{scalar("type"), scalar(typ)}
+	n.Content = append(n.Content[:at], append(pair, n.Content[at:]...)...)
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// joinComments joins the non-empty comment blocks cs, one after another.
+func joinComments(cs ...string) string {
+	var out []string
+	for _, c := range cs {
+		if c != "" {
+			out = append(out, c)
+		}
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,120 @@
+package connections
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name, in string
+		from     Shape
+		want     string // after the schema header; "" when unchanged
+	}{
+		{
+			name: "legacy list keeps its comments",
+			in: "# Life domain\n\nconnections:\n  - name: Notion\n    type: api # sync\n    url: https://api.notion.com\n\n" +
+				"# Example:\n#   - name: x\n",
+			from: LegacyList,
+			want: "# Life domain\n\nversion: 1\nconnections:\n  - name: Notion\n    type: api # sync\n    url: https://api.notion.com\n\n" +
+				"# Example:\n#   - name: x\n",
+		},
+		{
+			name: "grouped lists become one typed list",
+			in: "# External APIs\napis: []\n\n# Documentation sources\ndocumentation:\n  - name: Docs\n    url: 'https://docs.example.com'\n    notes: Reference\n\n" +
+				"internal_references:\n  - name: Skill\n    location: .claude/skills/x/SKILL.md\n    notes: old\n    purpose: new\n",
+			from: LegacyGrouped,
+			want: "version: 1\nconnections:\n  # External APIs\n  # Documentation sources\n" +
+				"  - name: Docs\n    type: documentation\n    url: 'https://docs.example.com'\n    purpose: Reference\n" +
+				"  - name: Skill\n    type: internal_reference\n    location: .claude/skills/x/SKILL.md\n    purpose: new\n",
+		},
+		{
+			name: "empty groups",
+			in:   "apis: []\n# nothing yet\ndata_sources:\n",
+			from: LegacyGrouped,
+			want: "version: 1\nconnections: []\n# nothing yet\n",
+		},
+		{
+			name: "current schema",
+			in:   "version: 1\nconnections: []\n",
+			from: Current,
+		},
+	}
+	for _, tt := range tests {
+		out, from, changed, err := Migrate([]byte(tt.in))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if from != tt.from || changed != (tt.want != "") {
+			t.Errorf("%s: from %s, changed %v", tt.name, from, changed)
+			continue
+		}
+		if !changed {
+			if string(out) != tt.in {
+				t.Errorf("%s: unchanged file rewritten to %q", tt.name, out)
+			}
+			continue
+		}
+		got, ok := strings.CutPrefix(string(out), header+"\n\n")
+		if !ok {
+			t.Errorf("%s: no schema header in %q", tt.name, out)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.name, got, tt.want)
+		}
+		s, err := Parse(out)
+		if err != nil || s.Shape != Current || s.Version != Version {
+			t.Errorf("%s: migrated file parses as %+v, %v", tt.name, s, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name, in string
+		shape    Shape
+		conns    []Connection
+		err      string
+	}{
+		{
+			name:  "current",
+			in:    "version: 1\nconnections:\n  - name: Docs\n    type: documentation\n    url: https://x.dev\n",
+			shape: Current,
+			conns: []Connection{{Name: "Docs", Type: Documentation, URL: "https://x.dev", Line: 3}},
+		},
+		{
+			name:  "grouped notes are the purpose",
+			in:    "data_sources:\n  - name: Feed\n    url: https://x.dev/feed\n    notes: daily\n",
+			shape: LegacyGrouped,
+			conns: []Connection{{Name: "Feed", Type: DataSource, URL: "https://x.dev/feed", Purpose: "daily", Line: 2}},
+		},
+		{name: "empty file", in: "", shape: Current},
+		{name: "unknown key", in: "version: 1\nconection: []\n", err: `line 2: unknown key "conection"`},
+		{name: "unknown field", in: "version: 1\nconnections:\n  - name: A\n    urll: x\n", err: `line 4: unknown field "urll"`},
+		{name: "group not a list", in: "apis: none\n", err: "line 1: apis must be a list"},
+	}
+	for _, tt := range tests {
+		s, err := Parse([]byte(tt.in))
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: err = %v, want %s", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if s.Shape != tt.shape || len(s.Connections) != len(tt.conns) {
+			t.Errorf("%s: %+v", tt.name, s)
+			continue
+		}
+		for i, c := range tt.conns {
+			if s.Connections[i] != c {
+				t.Errorf("%s: connection %d = %+v, want %+v", tt.name, i, s.Connections[i], c)
+			}
+		}
+	}
+}
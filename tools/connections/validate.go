@@ -0,0 +1,114 @@
+package connections
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/fuzzy"
+)
+
+// Problem is a schema or reference error in a CONNECTIONS.yaml. Line is
+// zero for problems with the file as a whole.
+type Problem struct {
+	Line    int
+	Name    string
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Name == "" {
+		return p.Message
+	}
+	return fmt.Sprintf("%s: %s", p.Name, p.Message)
+}
+
+// Validate checks s against the current schema. root is the vault root and
+// dir the domain folder, both as filesystem paths: internal_reference
+// locations must exist under root, and file paths, resolved against dir,
+// must be readable.
+func Validate(s *Set, root, dir string) []Problem {
+	var out []Problem
+	switch {
+	case s.Shape != Current:
+		out = append(out, Problem{Message: fmt.Sprintf("uses the %s; run \"pal connections migrate\" to convert it to version %d", s.Shape, Version)})
+	case s.Version != Version:
+		out = append(out, Problem{Message: fmt.Sprintf("unsupported version %d, expected %d", s.Version, Version)})
+	}
+
+	seen := map[string]bool{}
+	for _, c := range s.Connections {
+		bad := func(format string, args ...any) {
+			out = append(out, Problem{Line: c.Line, Name: c.Name, Message: fmt.Sprintf(format, args...)})
+		}
+		if strings.TrimSpace(c.Name) == "" {
+			bad("missing name")
+		} else if k := strings.ToLower(c.Name); seen[k] {
+			bad("duplicate name")
+		} else {
+			seen[k] = true
+		}
+
+		switch c.Type {
+		case API, Documentation, DataSource:
+			if c.URL == "" {
+				bad("%s connection needs a url", c.Type)
+			} else if u, err := url.Parse(c.URL); err != nil || u.Scheme == "" || u.Host == "" {
+				bad("url %q is not an absolute URL", c.URL)
+			}
+		case File:
+			if c.Path == "" {
+				bad("file connection needs a path")
+			} else if err := readable(filePath(c.Path, dir)); err != nil {
+				bad("path %q is not readable: %v", c.Path, unwrap(err))
+			}
+		case InternalReference:
+			if c.Location == "" {
+				bad("internal_reference needs a location")
+			} else if l := path.Clean(filepath.ToSlash(c.Location)); path.IsAbs(l) || strings.HasPrefix(l, "../") {
+				bad("location %q must be relative to the vault root", c.Location)
+			} else if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(l))); err != nil {
+				bad("location %q does not exist", c.Location)
+			}
+		case "":
+			bad("missing type")
+		default:
+			best, _ := fuzzy.Closest(c.Type, Types)
+			bad("unknown type %q, did you mean %q?", c.Type, best)
+		}
+	}
+	return out
+}
+
+// filePath resolves a file connection path: "~/" is the home directory and
+// relative paths start at the domain folder.
+func filePath(p, dir string) string {
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+func readable(p string) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// unwrap drops the path an *os.PathError repeats.
+func unwrap(err error) error {
+	if pe, ok := err.(*os.PathError); ok {
+		return pe.Err
+	}
+	return err
+}
@@ -0,0 +1,56 @@
+package connections
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "Domains", "Work")
+	for _, p := range []string{filepath.Join(dir, "export.csv"), filepath.Join(root, "Notes", "a.md")} {
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		c    Connection
+		want string // "" when valid
+	}{
+		{c: Connection{Name: "API", Type: API, URL: "https://api.example.com/v1"}},
+		{c: Connection{Name: "Export", Type: File, Path: "export.csv"}},
+		{c: Connection{Name: "Note", Type: InternalReference, Location: "Notes/a.md"}},
+		{c: Connection{Name: "", Type: API, URL: "https://x.dev"}, want: "missing name"},
+		{c: Connection{Name: "Docs", Type: Documentation}, want: "Docs: documentation connection needs a url"},
+		{c: Connection{Name: "Docs", Type: Documentation, URL: "docs.example.com"}, want: `Docs: url "docs.example.com" is not an absolute URL`},
+		{c: Connection{Name: "Gone", Type: File, Path: "gone.csv"}, want: `Gone: path "gone.csv" is not readable: no such file or directory`},
+		{c: Connection{Name: "Up", Type: InternalReference, Location: "../etc/passwd"}, want: `Up: location "../etc/passwd" must be relative to the vault root`},
+		{c: Connection{Name: "Missing", Type: InternalReference, Location: "Notes/b.md"}, want: `Missing: location "Notes/b.md" does not exist`},
+		{c: Connection{Name: "Typo", Type: "documentaton", URL: "https://x.dev"}, want: `Typo: unknown type "documentaton", did you mean "documentation"?`},
+		{c: Connection{Name: "Untyped"}, want: "Untyped: missing type"},
+	}
+	for _, tt := range tests {
+		got := Validate(&Set{Version: Version, Connections: []Connection{tt.c}}, root, dir)
+		switch {
+		case tt.want == "" && len(got) > 0:
+			t.Errorf("%+v: %v", tt.c, got)
+		case tt.want != "" && (len(got) != 1 || got[0].String() != tt.want):
+			t.Errorf("%+v: %v, want %s", tt.c, got, tt.want)
+		}
+	}
+
+	dup := &Set{Version: Version, Connections: []Connection{
+		{Name: "API", Type: API, URL: "https://a.dev"},
+		{Name: "api", Type: API, URL: "https://b.dev"},
+	}}
+	if got := Validate(dup, root, dir); len(got) != 1 || got[0].String() != "api: duplicate name" {
+		t.Errorf("duplicates: %v", got)
+	}
+	if got := Validate(&Set{Shape: LegacyList}, root, dir); len(got) != 1 {
+		t.Errorf("legacy shape: %v", got)
+	}
+}
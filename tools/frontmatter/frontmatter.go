@@ -0,0 +1,463 @@
+// Package frontmatter edits the YAML frontmatter of markdown notes without
+// disturbing anything it was not asked to change.
+//
+// Notes in a PAL vault are edited by hand in Obsidian, by skills and by
+// hooks. A naive decode/encode round trip would reorder keys, drop comments
+// and requote values on every write, so this package works on the raw lines
+// instead: YAML is parsed only to find where each top-level key starts and
+// ends, and an edit replaces exactly those lines. Everything else — key
+// order, comments, quoting, blank lines and the note body — is copied through
+// byte for byte.
+//
+// Requirement 1.4.31 makes everything from the "## Notes" heading down
+// off-limits to every workflow. Document enforces that: any edit that would
+// change those bytes fails with ErrProtected.
+package frontmatter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Delimiter is the line that opens and closes a frontmatter block.
+const Delimiter = "---"
+
+// ErrProtected is returned when an edit would modify the protected
+// "## Notes" section at the end of a note.
+var ErrProtected = errors.New("frontmatter: edit would modify content below ## Notes")
+
+// Document is a markdown file split into editable frontmatter and body.
+type Document struct {
+	orig  []byte
+	nl    string   // line terminator for new lines
+	open  string   // opening delimiter line as written
+	close string   // closing delimiter line as written
+	lines []string // frontmatter lines between the delimiters, with terminators
+	body  []byte
+	has   bool // whether the file had (or now has) a frontmatter block
+
+	spans []span
+}
+
+// span locates one top-level key within lines.
+type span struct {
+	key        string
+	head       int // first comment line directly above the key
+	start, end int // lines[start:end] hold the key and its value
+	keyNode    *yaml.Node
+	valueNode  *yaml.Node
+}
+
+// Parse reads data. A file without a leading "---" line has an empty
+// frontmatter block; Set will create one.
+func Parse(data []byte) (*Document, error) {
+	d := &Document{orig: data, nl: "\n"}
+	if bytes.Contains(data, []byte("\r\n")) {
+		d.nl = "\r\n"
+	}
+	d.open, d.close = Delimiter+d.nl, Delimiter+d.nl
+	yml, body, ok := Split(data)
+	d.body = body
+	d.has = ok
+	if ok {
+		d.open = string(data[:bytes.IndexByte(data, '\n')+1])
+		d.close = string(data[len(d.open)+len(yml) : len(data)-len(body)])
+		d.lines = splitKeep(string(yml))
+	}
+	if err := d.reparse(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Split returns the YAML between a leading "---" line and the next "---"
+// line, and the body after it. ok is false when data has no frontmatter, in
+// which case body is data.
+func Split(data []byte) (yml, body []byte, ok bool) {
+	if !bytes.HasPrefix(data, []byte(Delimiter+"\n")) && !bytes.HasPrefix(data, []byte(Delimiter+"\r\n")) {
+		return nil, data, false
+	}
+	start := bytes.IndexByte(data, '\n') + 1
+	for i := start; i < len(data); {
+		end := bytes.IndexByte(data[i:], '\n')
+		line, next := data[i:], len(data)
+		if end >= 0 {
+			line, next = data[i:i+end], i+end+1
+		}
+		if string(bytes.TrimRight(line, "\r")) == Delimiter {
+			return data[start:i], data[next:], true
+		}
+		i = next
+	}
+	return nil, data, false
+}
+
+// Has reports whether key is present.
+func (d *Document) Has(key string) bool {
+	return d.find(key) >= 0
+}
+
+// Keys returns the top-level keys in file order.
+func (d *Document) Keys() []string {
+	keys := make([]string, len(d.spans))
+	for i, s := range d.spans {
+		keys[i] = s.key
+	}
+	return keys
+}
+
+// Get returns the raw scalar text of key. ok is false when the key is
+// missing or its value is not a scalar.
+func (d *Document) Get(key string) (value string, ok bool) {
+	i := d.find(key)
+	if i < 0 || d.spans[i].valueNode.Kind != yaml.ScalarNode {
+		return "", false
+	}
+	v := d.spans[i].valueNode
+	if v.Tag == "!!null" {
+		return "", true
+	}
+	return v.Value, true
+}
+
+// Decode decodes the value of key into out.
+func (d *Document) Decode(key string, out any) error {
+	i := d.find(key)
+	if i < 0 {
+		return fmt.Errorf("frontmatter: no key %q", key)
+	}
+	return d.spans[i].valueNode.Decode(out)
+}
+
+// Set assigns value to key. An existing key is rewritten in place, keeping
+// its position, its trailing comment and, for strings and lists, its quoting
+// or flow style. A new key is appended to the end of the block. value may be
+// a *yaml.Node to control the encoding exactly.
+func (d *Document) Set(key string, value any) error {
+	node, err := toNode(value)
+	if err != nil {
+		return err
+	}
+	i := d.find(key)
+	if i < 0 {
+		text, err := render(&yaml.Node{Kind: yaml.ScalarNode, Value: key}, node, "")
+		if err != nil {
+			return err
+		}
+		d.lines = append(d.lines, d.terminate(text)...)
+		d.has = true
+		return d.reparse()
+	}
+
+	s := d.spans[i]
+	inheritStyle(node, s.valueNode)
+	text, err := render(s.keyNode, node, s.valueNode.LineComment)
+	if err != nil {
+		return err
+	}
+	// Keep the key exactly as written (quotes, spacing before the colon).
+	if colon := keyEnd(d.lines[s.start], s.keyNode); colon > 0 {
+		if k := renderKey(s.keyNode) + ":"; strings.HasPrefix(text[0], k) {
+			text[0] = d.lines[s.start][:colon] + text[0][len(k):]
+		}
+	}
+	d.replace(s.start, s.end, d.terminate(text))
+	return d.reparse()
+}
+
+// Delete removes key, its value and the comment lines directly above it,
+// other than a comment block at the top of the frontmatter. It reports
+// whether the key existed.
+func (d *Document) Delete(key string) (bool, error) {
+	i := d.find(key)
+	if i < 0 {
+		return false, nil
+	}
+	s := d.spans[i]
+	d.replace(s.head, s.end, nil)
+	return true, d.reparse()
+}
+
+// Body returns the markdown after the frontmatter block.
+func (d *Document) Body() []byte {
+	return d.body
+}
+
+// SetBody replaces the markdown after the frontmatter block. It fails with
+// ErrProtected if the new body changes the protected Notes section.
+func (d *Document) SetBody(body []byte) error {
+	if err := CheckProtected(d.body, body); err != nil {
+		return err
+	}
+	d.body = body
+	return nil
+}
+
+// Bytes renders the document. The result is identical to the parsed input
+// when nothing was changed.
+func (d *Document) Bytes() []byte {
+	if !d.has {
+		return append([]byte(nil), d.body...)
+	}
+	var b bytes.Buffer
+	b.WriteString(d.open)
+	for _, l := range d.lines {
+		b.WriteString(l)
+	}
+	b.WriteString(d.close)
+	b.Write(d.body)
+	return b.Bytes()
+}
+
+// Changed reports whether Bytes differs from the parsed input.
+func (d *Document) Changed() bool {
+	return !bytes.Equal(d.orig, d.Bytes())
+}
+
+// CheckProtected returns ErrProtected when before has a "## Notes" section
+// and after does not end with exactly the same bytes from that heading on.
+func CheckProtected(before, after []byte) error {
+	tail, ok := ProtectedTail(before)
+	if !ok {
+		return nil
+	}
+	got, ok := ProtectedTail(after)
+	if !ok || !bytes.Equal(tail, got) {
+		return ErrProtected
+	}
+	return nil
+}
+
+// ProtectedTail returns the bytes from the first "## Notes" heading to the end
+// of data. The frontmatter, where "## Notes" is a YAML comment, and headings
+// inside fenced code blocks are ignored.
+func ProtectedTail(data []byte) ([]byte, bool) {
+	pos, found := -1, false
+	fence := ""
+	_, body, _ := Split(data)
+	for off := len(data) - len(body); off < len(data); {
+		end := bytes.IndexByte(data[off:], '\n')
+		next := len(data)
+		if end >= 0 {
+			next = off + end + 1
+		}
+		line := strings.TrimRight(string(data[off:next]), "\r\n")
+		t := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~"):
+			if fence == "" {
+				fence = t[:3]
+			} else if strings.HasPrefix(t, fence) {
+				fence = ""
+			}
+		case fence == "" && !found && isNotesHeading(line):
+			pos, found = off, true
+		}
+		off = next
+	}
+	if !found {
+		return nil, false
+	}
+	return data[pos:], true
+}
+
+func isNotesHeading(line string) bool {
+	if !strings.HasPrefix(line, "## ") {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(strings.TrimRight(line[3:], "# ")), "Notes")
+}
+
+// find returns the index in d.spans of key, or -1.
+func (d *Document) find(key string) int {
+	for i, s := range d.spans {
+		if s.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d *Document) replace(start, end int, with []string) {
+	lines := append([]string(nil), d.lines[:start]...)
+	lines = append(lines, with...)
+	d.lines = append(lines, d.lines[end:]...)
+}
+
+// reparse refreshes the key spans from d.lines.
+func (d *Document) reparse() error {
+	d.spans = nil
+	if len(d.lines) == 0 {
+		return nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(strings.Join(d.lines, "")), &doc); err != nil {
+		return fmt.Errorf("frontmatter: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return errors.New("frontmatter: block is not a mapping")
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		k, v := root.Content[i], root.Content[i+1]
+		d.spans = append(d.spans, span{key: k.Value, start: k.Line - 1, keyNode: k, valueNode: v})
+	}
+	for i := range d.spans {
+		end := len(d.lines)
+		if i+1 < len(d.spans) {
+			end = d.spans[i+1].start
+		}
+		// Blank lines and column-0 comments just above the next key belong
+		// to that key, not to this value.
+		for end-1 > d.spans[i].start {
+			l := strings.TrimSpace(d.lines[end-1])
+			if l == "" || strings.HasPrefix(d.lines[end-1], "#") {
+				end--
+				continue
+			}
+			break
+		}
+		d.spans[i].end = end
+
+		// Only comments directly above the key are its own. A comment
+		// block that opens the frontmatter is about the whole note.
+		head := d.spans[i].start
+		for head > 0 && strings.HasPrefix(d.lines[head-1], "#") {
+			head--
+		}
+		if head == 0 {
+			head = d.spans[i].start
+		}
+		d.spans[i].head = head
+	}
+	return nil
+}
+
+// Date returns a node that encodes t as a bare YYYY-MM-DD date, the form
+// PAL uses for created, updated and last_modified.
+func Date(t time.Time) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: t.Format("2006-01-02")}
+}
+
+// toNode converts value to a YAML node.
+func toNode(value any) (*yaml.Node, error) {
+	if n, ok := value.(*yaml.Node); ok {
+		if n.Kind == yaml.DocumentNode && len(n.Content) == 1 {
+			return n.Content[0], nil
+		}
+		return n, nil
+	}
+	var n yaml.Node
+	if err := n.Encode(value); err != nil {
+		return nil, fmt.Errorf("frontmatter: %w", err)
+	}
+	return &n, nil
+}
+
+// inheritStyle carries the old value's presentation over to its replacement:
+// quoting for strings, flow style for lists and maps.
+func inheritStyle(n, old *yaml.Node) {
+	if n.Kind != old.Kind {
+		return
+	}
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if n.Tag == "!!str" || n.Tag == "" {
+			quoted := old.Style & (yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle)
+			if quoted != 0 {
+				n.Style = quoted
+			}
+		}
+	case yaml.SequenceNode, yaml.MappingNode:
+		n.Style |= old.Style & yaml.FlowStyle
+	}
+}
+
+// render encodes "key: value" as lines with two-space indentation.
+func render(key, value *yaml.Node, comment string) ([]string, error) {
+	k := *key
+	k.HeadComment, k.LineComment, k.FootComment = "", "", ""
+	v := *value
+	v.HeadComment, v.FootComment = "", ""
+	v.LineComment = comment
+	m := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{&k, &v}}
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(m); err != nil {
+		return nil, fmt.Errorf("frontmatter: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("frontmatter: %w", err)
+	}
+	return splitLines(strings.TrimSuffix(b.String(), "\n")), nil
+}
+
+// renderKey is how the encoder spells a key node on its own.
+func renderKey(key *yaml.Node) string {
+	k := *key
+	k.HeadComment, k.LineComment, k.FootComment = "", "", ""
+	out, err := yaml.Marshal(&k)
+	if err != nil {
+		return key.Value
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+// keyEnd returns the index just past the colon that ends key on line, or -1.
+func keyEnd(line string, key *yaml.Node) int {
+	col := key.Column - 1
+	if col < 0 || col > len(line) {
+		return -1
+	}
+	rest := line[col:]
+	if key.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 && len(rest) > 0 {
+		q := rest[0]
+		if j := strings.IndexByte(rest[1:], q); j >= 0 {
+			if c := strings.IndexByte(rest[j+2:], ':'); c >= 0 {
+				return col + j + 2 + c + 1
+			}
+		}
+		return -1
+	}
+	if c := strings.Index(rest, ":"); c >= 0 {
+		return col + c + 1
+	}
+	return -1
+}
+
+// terminate appends the document's line terminator to rendered lines.
+func (d *Document) terminate(lines []string) []string {
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = l + d.nl
+	}
+	return out
+}
+
+// splitKeep splits s into lines that keep their terminators.
+func splitKeep(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,170 @@
+package frontmatter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []string{
+		"",
+		"# No frontmatter\n\nJust a body.\n",
+		"---\nname: Note\n---\nbody\n",
+		"---\r\nname: Note\r\ntags: [a, b]\r\n---\r\nbody\r\n",
+		"---\n# About this note\n\nname:   'Quoted'   # trailing\nlist:\n  - one\n  -   two\n\n# status comment\nstatus: active\n---\n\n## Notes\n\nmine\n",
+		"---\n---\nempty block\n",
+	}
+	for _, in := range tests {
+		d, err := Parse([]byte(in))
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", in, err)
+		}
+		if got := string(d.Bytes()); got != in {
+			t.Errorf("round trip of %q gave %q", in, got)
+		}
+		if d.Changed() {
+			t.Errorf("Changed() after parsing %q", in)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name, in string
+		key      string
+		value    any
+		want     string
+	}{
+		{
+			name:  "keeps quoting and comment",
+			in:    "---\nname: 'Old' # why\nstatus: active\n---\nbody\n",
+			key:   "name",
+			value: "New",
+			want:  "---\nname: 'New' # why\nstatus: active\n---\nbody\n",
+		},
+		{
+			name:  "keeps flow lists",
+			in:    "---\ntags: [a]\n---\n",
+			key:   "tags",
+			value: []string{"a", "b"},
+			want:  "---\ntags: [a, b]\n---\n",
+		},
+		{
+			name:  "appends a new key",
+			in:    "---\nname: Note\n---\nbody\n",
+			key:   "status",
+			value: "done",
+			want:  "---\nname: Note\nstatus: done\n---\nbody\n",
+		},
+		{
+			name:  "creates the block",
+			in:    "body\n",
+			key:   "name",
+			value: "Note",
+			want:  "---\nname: Note\n---\nbody\n",
+		},
+		{
+			name:  "keeps CRLF",
+			in:    "---\r\nname: Note\r\n---\r\n",
+			key:   "status",
+			value: "done",
+			want:  "---\r\nname: Note\r\nstatus: done\r\n---\r\n",
+		},
+	}
+	for _, tt := range tests {
+		d, err := Parse([]byte(tt.in))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if err := d.Set(tt.key, tt.value); err != nil {
+			t.Fatalf("%s: Set: %v", tt.name, err)
+		}
+		if got := string(d.Bytes()); got != tt.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		name, in, key, want string
+		found               bool
+	}{
+		{
+			name:  "value lines",
+			in:    "---\nname: Note\nlist:\n  - a\n  - b\nstatus: active\n---\n",
+			key:   "list",
+			want:  "---\nname: Note\nstatus: active\n---\n",
+			found: true,
+		},
+		{
+			name:  "comment directly above",
+			in:    "---\nname: Note\n# the status\nstatus: active\n---\n",
+			key:   "status",
+			want:  "---\nname: Note\n---\n",
+			found: true,
+		},
+		{
+			name:  "comment after a blank line stays",
+			in:    "---\nname: Note\n# about the rest\n\nstatus: active\n---\n",
+			key:   "status",
+			want:  "---\nname: Note\n# about the rest\n\n---\n",
+			found: true,
+		},
+		{
+			name:  "leading comment block stays",
+			in:    "---\n# Generated by a template\n# keep me\nname: Note\nstatus: active\n---\n",
+			key:   "name",
+			want:  "---\n# Generated by a template\n# keep me\nstatus: active\n---\n",
+			found: true,
+		},
+		{
+			name: "missing key",
+			in:   "---\nname: Note\n---\n",
+			key:  "status",
+			want: "---\nname: Note\n---\n",
+		},
+	}
+	for _, tt := range tests {
+		d, err := Parse([]byte(tt.in))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		found, err := d.Delete(tt.key)
+		if err != nil {
+			t.Fatalf("%s: Delete: %v", tt.name, err)
+		}
+		if found != tt.found {
+			t.Errorf("%s: found = %v, want %v", tt.name, found, tt.found)
+		}
+		if got := string(d.Bytes()); got != tt.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckProtected(t *testing.T) {
+	before := []byte("---\nname: a\n---\nbody\n\n## Notes\n\nmine\n")
+	tests := []struct {
+		after string
+		err   error
+	}{
+		{"---\nname: b\n---\nnew body\n\n## Notes\n\nmine\n", nil},
+		{"---\nname: a\n---\nbody\n\n## Notes\n\nmine, edited\n", ErrProtected},
+		{"---\nname: a\n---\nbody\n", ErrProtected},
+	}
+	for _, tt := range tests {
+		if err := CheckProtected(before, []byte(tt.after)); !errors.Is(err, tt.err) {
+			t.Errorf("CheckProtected(%q) = %v, want %v", tt.after, err, tt.err)
+		}
+	}
+	if _, ok := ProtectedTail([]byte("```\n## Notes\n```\n")); ok {
+		t.Error("ProtectedTail found a heading inside a code fence")
+	}
+	if _, ok := ProtectedTail([]byte("---\n## Notes\nname: a\n---\nbody\n")); ok {
+		t.Error("ProtectedTail took a YAML comment for the heading")
+	}
+	if tail, _ := ProtectedTail([]byte("---\n## Notes\n---\n## Notes\nmine\n")); string(tail) != "## Notes\nmine\n" {
+		t.Errorf("ProtectedTail = %q, want the heading below the frontmatter", tail)
+	}
+}
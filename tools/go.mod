@@ -0,0 +1,5 @@
+module github.com/superuser-pal/PAL_Second_Brain/tools
+
+go 1.22
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,48 @@
+// Package fuzzy finds the closest string in a candidate list, for the "did
+// you mean" suggestions the PAL validators print.
+package fuzzy
+
+import "strings"
+
+// Distance returns the Levenshtein edit distance between a and b.
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// Closest returns the candidate nearest to s, compared case-insensitively,
+// and its distance. Ties go to the earlier candidate. It returns "", -1 when
+// there are no candidates.
+func Closest(s string, candidates []string) (string, int) {
+	best, dist := "", -1
+	ls := strings.ToLower(s)
+	for _, c := range candidates {
+		d := Distance(ls, strings.ToLower(c))
+		if dist < 0 || d < dist {
+			best, dist = c, d
+		}
+	}
+	return best, dist
+}
+
+// Near reports whether a distance is small enough, relative to the length
+// of the input, to be a typo rather than a different word.
+func Near(s string, dist int) bool {
+	return dist >= 0 && dist <= max(2, len([]rune(s))/3)
+}
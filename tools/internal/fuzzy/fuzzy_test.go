@@ -0,0 +1,64 @@
+package fuzzy
+
+import "testing"
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"derived_from", "derivd_from", 1},
+		{"café", "cafe", 1},
+		{"Case", "case", 1},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"Decision", "Question", "Idea", "Ideb"}
+	tests := []struct {
+		s    string
+		want string
+		dist int
+	}{
+		{"decison", "Decision", 1},
+		{"QUESTION", "Question", 0},
+		{"idec", "Idea", 1},
+		{"zzzzzzzz", "Decision", 8}, // ties with Question; the earlier wins
+	}
+	for _, tt := range tests {
+		if got, d := Closest(tt.s, candidates); got != tt.want || d != tt.dist {
+			t.Errorf("Closest(%q) = %q, %d; want %q, %d", tt.s, got, d, tt.want, tt.dist)
+		}
+	}
+	if got, d := Closest("x", nil); got != "" || d != -1 {
+		t.Errorf("Closest without candidates = %q, %d; want \"\", -1", got, d)
+	}
+}
+
+func TestNear(t *testing.T) {
+	tests := []struct {
+		s    string
+		dist int
+		want bool
+	}{
+		{"ab", 2, true},
+		{"ab", 3, false},
+		{"derived_from", 4, true},
+		{"derived_from", 5, false},
+		{"x", -1, false},
+	}
+	for _, tt := range tests {
+		if got := Near(tt.s, tt.dist); got != tt.want {
+			t.Errorf("Near(%q, %d) = %v, want %v", tt.s, tt.dist, got, tt.want)
+		}
+	}
+}
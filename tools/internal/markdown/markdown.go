@@ -0,0 +1,326 @@
+// Package markdown holds the small amount of markdown structure the PAL
+// tools need: heading sections, inline links and pipe tables. It is line
+// based on purpose; vault files are hand edited in Obsidian and a full
+// CommonMark parser would reject or normalise more than we want.
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Heading is an ATX heading ("## Title") found in a document.
+type Heading struct {
+	Level int
+	Text  string
+	Line  int // 1-based line number within the scanned text
+}
+
+// ParseHeading reports whether line is an ATX heading and returns it.
+// Headings inside fenced code blocks are the caller's concern; see Headings.
+func ParseHeading(line string) (Heading, bool) {
+	trimmed := strings.TrimRight(line, " \t\r")
+	level := 0
+	for level < len(trimmed) && trimmed[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return Heading{}, false
+	}
+	if level < len(trimmed) && trimmed[level] != ' ' && trimmed[level] != '\t' {
+		return Heading{}, false
+	}
+	text := strings.TrimSpace(trimmed[level:])
+	text = strings.TrimSpace(strings.TrimRight(text, "#"))
+	return Heading{Level: level, Text: text}, true
+}
+
+// Lines splits text into lines without their terminators.
+func Lines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSuffix(l, "\r")
+	}
+	return lines
+}
+
+// Headings returns every heading outside fenced code blocks.
+func Headings(text string) []Heading {
+	var out []Heading
+	fence := ""
+	for i, line := range Lines(text) {
+		if f, ok := fenceMarker(line); ok {
+			switch {
+			case fence == "":
+				fence = f
+			case strings.HasPrefix(strings.TrimSpace(line), fence):
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		if h, ok := ParseHeading(line); ok {
+			h.Line = i + 1
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// Section returns the lines below the first heading whose text matches title
+// (case-insensitive), up to the next heading of the same or a higher level.
+// The returned start is the 1-based line number of the first body line.
+func Section(text, title string) (lines []string, start int, ok bool) {
+	all := Lines(text)
+	for _, h := range Headings(text) {
+		if !strings.EqualFold(h.Text, title) {
+			continue
+		}
+		end := len(all)
+		for _, next := range Headings(text) {
+			if next.Line > h.Line && next.Level <= h.Level {
+				end = next.Line - 1
+				break
+			}
+		}
+		return all[h.Line:end], h.Line + 1, true
+	}
+	return nil, 0, false
+}
+
+// InFence reports, for every line of text, whether it sits inside (or is the
+// delimiter of) a fenced code block.
+func InFence(lines []string) []bool {
+	out := make([]bool, len(lines))
+	fence := ""
+	for i, line := range lines {
+		if f, ok := fenceMarker(line); ok {
+			out[i] = true
+			switch {
+			case fence == "":
+				fence = f
+			case strings.HasPrefix(strings.TrimSpace(line), fence):
+				fence = ""
+			}
+			continue
+		}
+		out[i] = fence != ""
+	}
+	return out
+}
+
+func fenceMarker(line string) (string, bool) {
+	t := strings.TrimSpace(line)
+	for _, m := range []string{"```", "~~~"} {
+		if strings.HasPrefix(t, m) {
+			return m, true
+		}
+	}
+	return "", false
+}
+
+// CodeSpans returns the [start, end) byte ranges of the inline code spans
+// in line.
+func CodeSpans(line string) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(line); {
+		if line[i] != '`' {
+			i++
+			continue
+		}
+		n := 0
+		for i+n < len(line) && line[i+n] == '`' {
+			n++
+		}
+		ticks := line[i : i+n]
+		end := strings.Index(line[i+n:], ticks)
+		if end < 0 {
+			break
+		}
+		spans = append(spans, [2]int{i, i + n + end + n})
+		i += n + end + n
+	}
+	return spans
+}
+
+// InSpans reports whether pos falls inside one of spans.
+func InSpans(spans [][2]int, pos int) bool {
+	for _, s := range spans {
+		if pos >= s[0] && pos < s[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// Link is one inline markdown link, [text](target "title"), or image.
+type Link struct {
+	Raw         string
+	Target      string // path as written, escapes included, without #fragment
+	Fragment    string // text after "#", or ""
+	TargetStart int    // offset of Target within Raw
+	Line, Col   int    // 1-based line, 0-based byte column
+}
+
+var linkRe = regexp.MustCompile(`!?\[[^\]]*\]\(\s*(<[^>]*>|[^)\s]+)(?:\s+"[^"]*")?\s*\)`)
+
+// Links returns the inline links of text outside fenced code blocks and
+// code spans. URLs and mailto links are skipped; same-page anchors come
+// back with an empty Target.
+func Links(text string) []Link {
+	lines := Lines(text)
+	fenced := InFence(lines)
+	var out []Link
+	for i, line := range lines {
+		if fenced[i] {
+			continue
+		}
+		code := CodeSpans(line)
+		for _, m := range linkRe.FindAllStringSubmatchIndex(line, -1) {
+			if InSpans(code, m[0]) {
+				continue
+			}
+			t := line[m[2]:m[3]]
+			start := m[2] - m[0]
+			if strings.HasPrefix(t, "<") {
+				t, start = t[1:len(t)-1], start+1
+			}
+			t, frag, _ := strings.Cut(t, "#")
+			if strings.Contains(t, ":") {
+				continue // URL or mailto
+			}
+			out = append(out, Link{
+				Raw:         line[m[0]:m[1]],
+				Target:      t,
+				Fragment:    frag,
+				TargetStart: start,
+				Line:        i + 1,
+				Col:         m[0],
+			})
+		}
+	}
+	return out
+}
+
+// Table is a pipe table. Cells are trimmed; escaped pipes are unescaped.
+type Table struct {
+	Header []string
+	Rows   []Row
+}
+
+// Row is one body row of a Table.
+type Row struct {
+	Cells []string
+	Line  int // 1-based line number relative to the scanned lines
+}
+
+// Get returns the cell under the named column (case-insensitive), or "".
+func (t *Table) Get(r Row, column string) string {
+	for i, h := range t.Header {
+		if strings.EqualFold(h, column) && i < len(r.Cells) {
+			return r.Cells[i]
+		}
+	}
+	return ""
+}
+
+// Column returns the index of the named column (case-insensitive), or -1.
+func (t *Table) Column(column string) int {
+	for i, h := range t.Header {
+		if strings.EqualFold(h, column) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Tables returns every pipe table in lines. A table is a header row followed
+// by a delimiter row (|---|:--:|) and any number of body rows. offset is added
+// to row line numbers so callers can report positions in the whole file.
+func Tables(lines []string, offset int) []Table {
+	var out []Table
+	fenced := InFence(lines)
+	for i := 0; i+1 < len(lines); i++ {
+		if fenced[i] || !isTableRow(lines[i]) || !isDelimiterRow(lines[i+1]) {
+			continue
+		}
+		t := Table{Header: SplitRow(lines[i])}
+		j := i + 2
+		for ; j < len(lines) && !fenced[j] && isTableRow(lines[j]); j++ {
+			t.Rows = append(t.Rows, Row{Cells: SplitRow(lines[j]), Line: j + offset})
+		}
+		out = append(out, t)
+		i = j - 1
+	}
+	return out
+}
+
+func isTableRow(line string) bool {
+	t := strings.TrimSpace(line)
+	return strings.HasPrefix(t, "|") && strings.Count(t, "|") >= 2
+}
+
+func isDelimiterRow(line string) bool {
+	if !isTableRow(line) {
+		return false
+	}
+	for _, c := range SplitRow(line) {
+		c = strings.Trim(c, ":")
+		if c == "" || strings.Trim(c, "-") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// SplitRow splits a pipe table row into trimmed cells.
+func SplitRow(line string) []string {
+	t := strings.TrimSpace(line)
+	t = strings.TrimPrefix(t, "|")
+	t = strings.TrimSuffix(t, "|")
+	var cells []string
+	var cur strings.Builder
+	for i := 0; i < len(t); i++ {
+		switch {
+		case t[i] == '\\' && i+1 < len(t) && t[i+1] == '|':
+			cur.WriteByte('|')
+			i++
+		case t[i] == '|':
+			cells = append(cells, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteByte(t[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cur.String()))
+}
+
+// SetCell returns the table row line with cell i, counted as by SplitRow,
+// replaced by value. The other cells keep their spacing; a row with no
+// cell i comes back unchanged.
+func SetCell(line string, i int, value string) string {
+	start := strings.Index(line, "|") + 1
+	if start == 0 {
+		return line
+	}
+	n := 0
+	for j := start; j < len(line); j++ {
+		switch {
+		case line[j] == '\\' && j+1 < len(line) && line[j+1] == '|':
+			j++
+		case line[j] == '|':
+			if n == i {
+				return line[:start] + " " + strings.ReplaceAll(value, "|", `\|`) + " " + line[j:]
+			}
+			n++
+			start = j + 1
+		}
+	}
+	return line
+}
@@ -0,0 +1,161 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHeading(t *testing.T) {
+	tests := []struct {
+		line  string
+		want  Heading
+		found bool
+	}{
+		{line: "# Title", want: Heading{Level: 1, Text: "Title"}, found: true},
+		{line: "### Notes ###  ", want: Heading{Level: 3, Text: "Notes"}, found: true},
+		{line: "##\tTabbed\r", want: Heading{Level: 2, Text: "Tabbed"}, found: true},
+		{line: "#", want: Heading{Level: 1}, found: true},
+		{line: "#tag"},
+		{line: "####### seven"},
+		{line: "text # not a heading"},
+	}
+	for _, tt := range tests {
+		got, ok := ParseHeading(tt.line)
+		if ok != tt.found || got != tt.want {
+			t.Errorf("ParseHeading(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.found)
+		}
+	}
+}
+
+func TestSection(t *testing.T) {
+	text := "# Doc\n\n## Active\n\n- one\n### Sub\n- two\n\n```\n## Fenced\n```\n## Done\n\n- three\n"
+	tests := []struct {
+		title string
+		lines []string
+		start int
+		found bool
+	}{
+		{title: "active", lines: []string{"", "- one", "### Sub", "- two", "", "```", "## Fenced", "```"}, start: 4, found: true},
+		{title: "Sub", lines: []string{"- two", "", "```", "## Fenced", "```"}, start: 7, found: true},
+		{title: "Done", lines: []string{"", "- three"}, start: 13, found: true},
+		{title: "Fenced"},
+	}
+	for _, tt := range tests {
+		lines, start, ok := Section(text, tt.title)
+		if ok != tt.found || start != tt.start || !reflect.DeepEqual(lines, tt.lines) {
+			t.Errorf("Section(%q) = %q, %d, %v; want %q, %d, %v", tt.title, lines, start, ok, tt.lines, tt.start, tt.found)
+		}
+	}
+}
+
+func TestInFence(t *testing.T) {
+	lines := Lines("a\n```go\nb\n~~~\n```\nc\n~~~\nd\n")
+	want := []bool{false, true, true, true, true, false, true, true}
+	if got := InFence(lines); !reflect.DeepEqual(got, want) {
+		t.Errorf("InFence(%q) = %v, want %v", lines, got, want)
+	}
+}
+
+func TestCodeSpans(t *testing.T) {
+	tests := []struct {
+		line string
+		want [][2]int
+	}{
+		{line: "no code"},
+		{line: "a `b` c", want: [][2]int{{2, 5}}},
+		{line: "``x ` y`` and `z`", want: [][2]int{{0, 9}, {14, 17}}},
+		{line: "open `only"},
+	}
+	for _, tt := range tests {
+		if got := CodeSpans(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CodeSpans(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestLinks(t *testing.T) {
+	text := "See [a](notes/a.md) and ![img](<my pic.png> \"Pic\").\n" +
+		"[anchor](#top) [web](https://x.dev) [mail](mailto:a@b.c)\n" +
+		"`[code](skip.md)` [sec](b.md#Part-2)\n" +
+		"```\n[fenced](skip.md)\n```\n"
+	want := []Link{
+		{Raw: "[a](notes/a.md)", Target: "notes/a.md", TargetStart: 4, Line: 1, Col: 4},
+		{Raw: "![img](<my pic.png> \"Pic\")", Target: "my pic.png", TargetStart: 8, Line: 1, Col: 24},
+		{Raw: "[anchor](#top)", Fragment: "top", TargetStart: 9, Line: 2},
+		{Raw: "[sec](b.md#Part-2)", Target: "b.md", Fragment: "Part-2", TargetStart: 6, Line: 3, Col: 18},
+	}
+	got := Links(text)
+	if len(got) != len(want) {
+		t.Fatalf("Links gave %d links, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("link %d = %+v, want %+v", i, got[i], want[i])
+		}
+		if l := got[i]; l.Target != "" && !strings.HasPrefix(l.Raw[l.TargetStart:], l.Target) {
+			t.Errorf("link %d: TargetStart %d does not point at %q in %q", i, l.TargetStart, l.Target, l.Raw)
+		}
+	}
+}
+
+func TestTables(t *testing.T) {
+	lines := Lines("intro\n| Name | Status |\n|:-----|:------:|\n| API | active |\n| a \\| b |\n\n" +
+		"| not | a table |\n| x | y |\n\n```\n| A |\n|---|\n| 1 |\n```\n| Only | Header |\n| --- | --- |\n")
+	got := Tables(lines, 10)
+	want := []Table{
+		{Header: []string{"Name", "Status"}, Rows: []Row{
+			{Cells: []string{"API", "active"}, Line: 13},
+			{Cells: []string{"a | b"}, Line: 14},
+		}},
+		{Header: []string{"Only", "Header"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Tables = %+v, want %+v", got, want)
+	}
+
+	tb := got[0]
+	tests := []struct {
+		row    int
+		column string
+		want   string
+	}{
+		{row: 0, column: "status", want: "active"},
+		{row: 0, column: "Owner"},
+		{row: 1, column: "Name", want: "a | b"},
+		{row: 1, column: "Status"},
+	}
+	for _, tt := range tests {
+		if got := tb.Get(tb.Rows[tt.row], tt.column); got != tt.want {
+			t.Errorf("Get(row %d, %q) = %q, want %q", tt.row, tt.column, got, tt.want)
+		}
+	}
+	if tb.Column("STATUS") != 1 || tb.Column("Owner") != -1 {
+		t.Errorf("Column: STATUS=%d Owner=%d", tb.Column("STATUS"), tb.Column("Owner"))
+	}
+}
+
+func TestSetCell(t *testing.T) {
+	tests := []struct {
+		line  string
+		i     int
+		value string
+		want  string
+	}{
+		{line: "| API | active |", i: 1, value: "done", want: "| API | done |"},
+		{line: "|API|active|", i: 0, value: "Web", want: "| Web |active|"},
+		{line: "  | a \\| b |   c |", i: 1, value: "x|y", want: "  | a \\| b | x\\|y |"},
+		{line: "| only |", i: 3, value: "x", want: "| only |"},
+		{line: "no table", i: 0, value: "x", want: "no table"},
+	}
+	for _, tt := range tests {
+		got := SetCell(tt.line, tt.i, tt.value)
+		if got != tt.want {
+			t.Errorf("SetCell(%q, %d, %q) = %q, want %q", tt.line, tt.i, tt.value, got, tt.want)
+			continue
+		}
+		if got != tt.line && SplitRow(got)[tt.i] != tt.value {
+			t.Errorf("SetCell(%q, %d, %q) splits back to %q", tt.line, tt.i, tt.value, SplitRow(got))
+		}
+	}
+}
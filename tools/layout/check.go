@@ -0,0 +1,136 @@
+package layout
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+)
+
+// Kind classifies a documented path that does not match the vault.
+type Kind string
+
+const (
+	// CaseMismatch paths exist, but only with different capitalisation.
+	CaseMismatch Kind = "case"
+	// Renamed paths use an alias of a location that has moved.
+	Renamed Kind = "renamed"
+	// Missing paths do not exist at all.
+	Missing Kind = "missing"
+)
+
+// Finding is a path mentioned in documentation that does not exist as
+// written.
+type Finding struct {
+	Kind Kind `json:"kind"`
+	// Path is the path as documented; Actual the on-disk or canonical path
+	// it should be, when known.
+	Path   string `json:"path"`
+	Actual string `json:"actual,omitempty"`
+	// Location is the layout key Path falls under, if any.
+	Location string `json:"location,omitempty"`
+	// Refs are the places that mention Path, as "file:line".
+	Refs []string `json:"refs"`
+}
+
+// docPath finds paths starting at a vault root folder; other slash-separated
+// words in prose ("and/or", URLs) are not vault paths.
+var docPath = regexp.MustCompile("(?i)(?:^|[^A-Za-z0-9_./@-])@?(/?(?:\\.claude|inbox|ports|domains|tasks)/[^\\s`'\"()<>|,;]*)")
+
+// placeholder marks a path element that stands for many names:
+// "PROJECT_*.md", "[domain]", "YYYY-MM-DD_title.md", "DD-MM-YY.md".
+var placeholder = regexp.MustCompile(`[*\[\]{}<>]|YYYY|DD-MM|\.\.\.|…`)
+
+// Doc is one documentation file to scan.
+type Doc struct {
+	Path string
+	Text string
+}
+
+// Check scans docs for vault paths and reports those that do not exist
+// under root exactly as written. Placeholder elements end the part of a
+// path that is checked: for "Domains/*/INDEX.md" only Domains/ is.
+func (l *Layout) Check(root string, docs []Doc) []Finding {
+	byPath := map[string]*Finding{}
+	for _, d := range docs {
+		lines := markdown.Lines(d.Text)
+		for i, line := range lines {
+			for _, m := range docPath.FindAllStringSubmatch(line, -1) {
+				p := documented(m[1])
+				if p == "" {
+					continue
+				}
+				ref := d.Path + ":" + strconv.Itoa(i+1)
+				if f, seen := byPath[p]; seen {
+					if f != nil {
+						f.Refs = append(f.Refs, ref)
+					}
+					continue
+				}
+				f, ok := l.check(root, p)
+				if !ok {
+					byPath[p] = nil
+					continue
+				}
+				f.Refs = []string{ref}
+				byPath[p] = &f
+			}
+		}
+	}
+	var out []Finding
+	for _, f := range byPath {
+		if f != nil {
+			out = append(out, *f)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// check returns the finding for one documented path, or false when the
+// path exists as written.
+func (l *Layout) check(root, p string) (Finding, bool) {
+	if got, ok := Resolve(root, p); ok {
+		if got == p {
+			return Finding{}, false
+		}
+		key, _, _ := l.Match(p)
+		return Finding{Kind: CaseMismatch, Path: p, Actual: got, Location: key}, true
+	}
+	key, rest, alias := l.Match(p)
+	if alias {
+		actual := l.Path(key)
+		if rest != "" {
+			actual += "/" + rest
+		}
+		if got, ok := Resolve(root, actual); ok {
+			actual = got
+		}
+		return Finding{Kind: Renamed, Path: p, Actual: actual, Location: key}, true
+	}
+	f := Finding{Kind: Missing, Path: p, Location: key}
+	if key != "" && !strings.HasPrefix(p, l.Path(key)) {
+		// Nothing exists there, but at least spell it the canonical way.
+		f.Actual = l.Path(key)
+		if rest != "" {
+			f.Actual += "/" + rest
+		}
+	}
+	return f, true
+}
+
+// documented trims a matched path to the part that can be checked.
+func documented(p string) string {
+	p = strings.TrimRight(p, ".:!?")
+	p = strings.Trim(p, "/")
+	var keep []string
+	for _, part := range strings.Split(p, "/") {
+		if part == "" || placeholder.MatchString(part) {
+			break
+		}
+		keep = append(keep, part)
+	}
+	return strings.Join(keep, "/")
+}
@@ -0,0 +1,240 @@
+// Package layout names the canonical locations of a PAL vault. The vault
+// root may carry a pal.layout.yaml manifest:
+//
+//	version: 1
+//	locations:
+//	  domains:
+//	    path: Domains
+//	  master_tasks:
+//	    path: inbox/Tasks/MASTER.md
+//	    aliases: [tasks/MASTER.md, Inbox/Dashboards/TASKS.md]
+//
+// Tools ask for a location by key instead of hard-coding its path, and
+// resolve it case-insensitively, so "inbox/notes" in a skill file still
+// finds inbox/Notes on a case-sensitive filesystem. Aliases record the
+// spellings the documentation used before a location moved. Without a
+// manifest the built-in Default applies.
+package layout
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is the manifest file name at the vault root.
+const File = "pal.layout.yaml"
+
+// Version is the manifest schema version.
+const Version = 1
+
+// Location keys every layout defines.
+const (
+	Domains          = "domains"
+	Inbox            = "inbox"
+	InboxNotes       = "inbox_notes"
+	InboxPlan        = "inbox_plan"
+	InboxPlanArchive = "inbox_plan_archive"
+	InboxResources   = "inbox_resources"
+	InboxTasks       = "inbox_tasks"
+	InboxWhiteboards = "inbox_whiteboards"
+	MasterTasks      = "master_tasks"
+	Dashboard        = "dashboard"
+	PortsIn          = "ports_in"
+	PortsOut         = "ports_out"
+	Docs             = "docs"
+	Claude           = "claude"
+	Skills           = "skills"
+	Agents           = "agents"
+	Commands         = "commands"
+	Sessions         = "sessions"
+	State            = "state"
+	RoutingTable     = "routing_table"
+	SystemIndex      = "system_index"
+	Schemas          = "schemas"
+)
+
+// Location is one named place in the vault.
+type Location struct {
+	// Path is vault-relative with forward slashes.
+	Path    string   `yaml:"path"`
+	Aliases []string `yaml:"aliases,omitempty"`
+}
+
+// Layout is a vault's set of canonical locations.
+type Layout struct {
+	Version   int                 `yaml:"version"`
+	Locations map[string]Location `yaml:"locations"`
+}
+
+// Default returns the layout of a stock PAL vault.
+func Default() *Layout {
+	return &Layout{Version: Version, Locations: map[string]Location{
+		Domains:          {Path: "Domains"},
+		Inbox:            {Path: "inbox"},
+		InboxNotes:       {Path: "inbox/Notes"},
+		InboxPlan:        {Path: "inbox/Plan"},
+		InboxPlanArchive: {Path: "inbox/Plan/archive"},
+		InboxResources:   {Path: "inbox/Resources"},
+		InboxTasks:       {Path: "inbox/Tasks"},
+		InboxWhiteboards: {Path: "inbox/Whiteboards"},
+		MasterTasks:      {Path: "inbox/Tasks/MASTER.md", Aliases: []string{"tasks/MASTER.md", "Inbox/Dashboards/TASKS.md"}},
+		Dashboard:        {Path: "inbox/Tasks/DASHBOARD.md"},
+		PortsIn:          {Path: "Ports/In"},
+		PortsOut:         {Path: "Ports/Out"},
+		Docs:             {Path: "Docs"},
+		Claude:           {Path: ".claude"},
+		Skills:           {Path: ".claude/skills"},
+		Agents:           {Path: ".claude/agents"},
+		Commands:         {Path: ".claude/commands"},
+		Sessions:         {Path: ".claude/sessions"},
+		State:            {Path: ".claude/state"},
+		RoutingTable:     {Path: ".claude/core/system/ROUTING_TABLE.md"},
+		SystemIndex:      {Path: ".claude/core/system/SYSTEM_INDEX.md"},
+		Schemas:          {Path: ".claude/schemas/frontmatter"},
+	}}
+}
+
+// Load reads root's manifest. Keys the manifest leaves out keep their
+// default path, so a manifest only needs to list what it changes.
+func Load(root string) (*Layout, error) {
+	l := Default()
+	data, err := os.ReadFile(filepath.Join(root, File))
+	if errors.Is(err, fs.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m Layout
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", File, err)
+	}
+	if m.Version != Version {
+		return nil, fmt.Errorf("%s: unsupported version %d, expected %d", File, m.Version, Version)
+	}
+	for k, loc := range m.Locations {
+		loc.Path = clean(loc.Path)
+		if loc.Path == "" || loc.Path == "." || strings.HasPrefix(loc.Path, "../") {
+			return nil, fmt.Errorf("%s: location %q needs a path inside the vault", File, k)
+		}
+		for i, a := range loc.Aliases {
+			loc.Aliases[i] = clean(a)
+		}
+		l.Locations[k] = loc
+	}
+	return l, nil
+}
+
+// Path returns the vault-relative path of the location key. It panics on
+// an unknown key: keys are constants, so that is a programming error.
+func (l *Layout) Path(key string) string {
+	loc, ok := l.Locations[key]
+	if !ok {
+		panic("layout: unknown location " + key)
+	}
+	return loc.Path
+}
+
+// Find returns the on-disk, vault-relative path of the location key under
+// root, matching each path element case-insensitively when the exact
+// spelling does not exist. ok is false when nothing matches; path is then
+// the canonical spelling.
+func (l *Layout) Find(root, key string) (p string, ok bool) {
+	want := l.Path(key)
+	if got, ok := Resolve(root, want); ok {
+		return got, true
+	}
+	return want, false
+}
+
+// Keys returns the location keys in lexical order.
+func (l *Layout) Keys() []string {
+	keys := make([]string, 0, len(l.Locations))
+	for k := range l.Locations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Match returns the location key whose path or alias equals p or contains
+// it, ignoring case, and the rest of p below that location. The longest
+// match wins, so "inbox/Tasks/MASTER.md" is master_tasks, not inbox_tasks.
+func (l *Layout) Match(p string) (key, rest string, alias bool) {
+	p = clean(p)
+	best := -1
+	for _, k := range l.Keys() {
+		loc := l.Locations[k]
+		for i, cand := range append([]string{loc.Path}, loc.Aliases...) {
+			r, ok := under(p, cand)
+			if ok && len(cand) > best {
+				best, key, rest, alias = len(cand), k, r, i > 0
+			}
+		}
+	}
+	return key, rest, alias
+}
+
+// under reports whether p is base or below it, ignoring case, and returns
+// the remainder.
+func under(p, base string) (string, bool) {
+	switch {
+	case strings.EqualFold(p, base):
+		return "", true
+	case len(p) > len(base) && p[len(base)] == '/' && strings.EqualFold(p[:len(base)], base):
+		return p[len(base)+1:], true
+	}
+	return "", false
+}
+
+// Resolve finds the vault-relative path rel under root. Each element is
+// matched exactly if possible and otherwise case-insensitively; the
+// returned path is spelled the way it is on disk.
+func Resolve(root, rel string) (string, bool) {
+	rel = clean(rel)
+	if rel == "" || rel == "." {
+		return "", true
+	}
+	var got []string
+	dir := root
+	for _, part := range strings.Split(rel, "/") {
+		if _, err := os.Lstat(filepath.Join(dir, part)); err == nil {
+			got = append(got, part)
+			dir = filepath.Join(dir, part)
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return "", false
+		}
+		found := ""
+		for _, e := range entries {
+			if strings.EqualFold(e.Name(), part) {
+				found = e.Name()
+				break
+			}
+		}
+		if found == "" {
+			return "", false
+		}
+		got = append(got, found)
+		dir = filepath.Join(dir, found)
+	}
+	return strings.Join(got, "/"), true
+}
+
+func clean(p string) string {
+	p = strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(p)), "/")
+	if p == "" {
+		return ""
+	}
+	return path.Clean(p)
+}
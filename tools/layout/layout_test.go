@@ -0,0 +1,141 @@
+package layout
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// tree writes files, keyed by vault-relative path, into a fresh vault root.
+func tree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for p, data := range files {
+		abs := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(abs, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name, manifest string
+		paths          map[string]string
+		err            string
+	}{
+		{name: "no manifest", paths: map[string]string{Domains: "Domains", MasterTasks: "inbox/Tasks/MASTER.md"}},
+		{
+			name:     "overrides keep the other defaults",
+			manifest: "version: 1\nlocations:\n  domains:\n    path: /Areas/\n  custom:\n    path: ./x/y\n",
+			paths:    map[string]string{Domains: "Areas", "custom": "x/y", Inbox: "inbox"},
+		},
+		{name: "wrong version", manifest: "version: 2\n", err: "pal.layout.yaml: unsupported version 2, expected 1"},
+		{name: "outside the vault", manifest: "version: 1\nlocations:\n  docs:\n    path: ../Docs\n", err: `pal.layout.yaml: location "docs" needs a path inside the vault`},
+		{name: "empty path", manifest: "version: 1\nlocations:\n  docs: {}\n", err: `pal.layout.yaml: location "docs" needs a path inside the vault`},
+	}
+	for _, tt := range tests {
+		files := map[string]string{}
+		if tt.manifest != "" {
+			files[File] = tt.manifest
+		}
+		l, err := Load(tree(t, files))
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: err = %v, want %s", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		for k, want := range tt.paths {
+			if got := l.Path(k); got != want {
+				t.Errorf("%s: Path(%s) = %q, want %q", tt.name, k, got, want)
+			}
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	root := tree(t, map[string]string{
+		"inbox/Notes/Idea.md":   "",
+		"Domains/Work/INDEX.md": "",
+		".claude/skills/x/a.md": "",
+	})
+	tests := []struct {
+		rel, want string
+		ok        bool
+	}{
+		{rel: "inbox/Notes/Idea.md", want: "inbox/Notes/Idea.md", ok: true},
+		{rel: "Inbox/notes/idea.MD", want: "inbox/Notes/Idea.md", ok: true},
+		{rel: "/domains/work/", want: "Domains/Work", ok: true},
+		{rel: ".CLAUDE/Skills", want: ".claude/skills", ok: true},
+		{rel: "", want: "", ok: true},
+		{rel: "inbox/Plan"},
+		{rel: "inbox/Notes/Idea.md/deeper"},
+	}
+	for _, tt := range tests {
+		got, ok := Resolve(root, tt.rel)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Resolve(%q) = %q, %v; want %q, %v", tt.rel, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	l := Default()
+	if p, ok := l.Find(root, InboxNotes); p != "inbox/Notes" || !ok {
+		t.Errorf("Find(%s) = %q, %v", InboxNotes, p, ok)
+	}
+	if p, ok := l.Find(root, InboxPlan); p != "inbox/Plan" || ok {
+		t.Errorf("Find(%s) = %q, %v", InboxPlan, p, ok)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		p, key, rest string
+		alias        bool
+	}{
+		{p: "inbox/Tasks/MASTER.md", key: MasterTasks},
+		{p: "inbox/tasks/archive/old.md", key: InboxTasks, rest: "archive/old.md"},
+		{p: "tasks/MASTER.md", key: MasterTasks, alias: true},
+		{p: "Inbox/Dashboards/TASKS.md", key: MasterTasks, alias: true},
+		{p: "/.claude/skills/pm/SKILL.md", key: Skills, rest: "pm/SKILL.md"},
+		{p: ".claude/core/x.md", key: Claude, rest: "core/x.md"},
+		{p: "inboxes/a.md"},
+	}
+	l := Default()
+	for _, tt := range tests {
+		key, rest, alias := l.Match(tt.p)
+		if key != tt.key || rest != tt.rest || alias != tt.alias {
+			t.Errorf("Match(%q) = %q, %q, %v; want %q, %q, %v", tt.p, key, rest, alias, tt.key, tt.rest, tt.alias)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	root := tree(t, map[string]string{
+		"inbox/Notes/a.md":      "",
+		"inbox/Tasks/MASTER.md": "",
+		"Domains/Work/INDEX.md": "",
+	})
+	docs := []Doc{
+		{Path: "README.md", Text: "Notes go in inbox/Notes/a.md and `inbox/notes/`.\n" +
+			"Tasks live in tasks/MASTER.md; see Domains/*/INDEX.md and Domains/[domain]/01_PROJECTS.\n"},
+		{Path: ".claude/skills/x/SKILL.md", Text: "Read inbox/notes/ first.\nWrite to Ports/Out/report.md, and/or https://x.dev/inbox/a.\n"},
+	}
+	want := []Finding{
+		{Kind: Missing, Path: "Ports/Out/report.md", Location: PortsOut, Refs: []string{".claude/skills/x/SKILL.md:2"}},
+		{Kind: CaseMismatch, Path: "inbox/notes", Actual: "inbox/Notes", Location: InboxNotes, Refs: []string{"README.md:1", ".claude/skills/x/SKILL.md:1"}},
+		{Kind: Renamed, Path: "tasks/MASTER.md", Actual: "inbox/Tasks/MASTER.md", Location: MasterTasks, Refs: []string{"README.md:2"}},
+	}
+	if got := Default().Check(root, docs); !reflect.DeepEqual(got, want) {
+		t.Errorf("Check =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -0,0 +1,384 @@
+// Package naming checks vault paths against PAL's naming conventions
+// (requirements 0.3.1 to 0.3.3 and 1.8.7). A path's category follows from
+// where it lives:
+//
+//	System protocols   UPPER_SNAKE_CASE.md     .claude/base, .claude/core, domain roots, 06_REQUIREMENTS, SKILL.md
+//	Domain folders     PascalCase              Domains/*
+//	Folders            lower-kebab-case        .claude/**, subfolders of domain folders
+//	Agent files        lower-kebab-case.md     .claude/agents/*.md
+//	Context files      lower_snake_case.md     00_CONTEXT, 02_PAGES, skill roots and workflows
+//	Project files      PROJECT_NAME.md         01_PROJECTS/*.md (or PLAN_NAME.md)
+//	Session files      YYYY-MM-DD_title.md     04_SESSIONS, .claude/sessions
+//	Asset files        lower_snake_case        03_OUTPUT, non-markdown files in domains
+//
+// README.md, INDEX.md and CONNECTIONS.yaml keep their fixed names
+// everywhere.
+package naming
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Category is one of the eight naming categories.
+type Category string
+
+const (
+	Protocol     Category = "system protocol"
+	DomainFolder Category = "domain folder"
+	Folder       Category = "folder"
+	AgentFile    Category = "agent file"
+	ContextFile  Category = "context file"
+	ProjectFile  Category = "project file"
+	SessionFile  Category = "session file"
+	AssetFile    Category = "asset file"
+)
+
+// Categories lists the categories in the order of requirement 1.8.7.
+var Categories = []Category{Protocol, DomainFolder, Folder, AgentFile, ContextFile, ProjectFile, SessionFile, AssetFile}
+
+// Entry is one file or folder to check.
+type Entry struct {
+	// Path is vault-relative with forward slashes.
+	Path    string
+	Dir     bool
+	ModTime time.Time
+}
+
+// Violation is an entry whose name breaks its category's convention.
+type Violation struct {
+	Path     string   `json:"path"`
+	Dir      bool     `json:"dir,omitempty"`
+	Category Category `json:"category"`
+	// Current is the base name, Expected the pattern it should follow and
+	// Suggested a conforming base name ("" when none can be derived).
+	Current   string `json:"current"`
+	Expected  string `json:"expected"`
+	Suggested string `json:"suggested,omitempty"`
+}
+
+// rule is the convention of one category.
+type rule struct {
+	pattern string
+	valid   func(name string) bool
+	suggest func(e Entry) string
+}
+
+var (
+	upperSnake = regexp.MustCompile(`^[A-Z0-9]+(?:_[A-Z0-9]+)*$`)
+	pascal     = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	kebab      = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	// lower_snake_case, allowing ISO dates as one word
+	// ("life_summary_2026-03-01").
+	snake   = regexp.MustCompile(`^(?:[a-z0-9]+|\d{4}-\d{2}-\d{2})(?:_(?:[a-z0-9]+|\d{4}-\d{2}-\d{2}))*$`)
+	project = regexp.MustCompile(`^(?:PROJECT|PLAN)_[A-Z0-9]+(?:_[A-Z0-9]+)*$`)
+	session = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}_[a-z0-9]+(?:[_-][a-z0-9]+)*$`)
+	isoDate = regexp.MustCompile(`\d{4}-\d{2}-\d{2}|\d{8}`)
+)
+
+var rules = map[Category]rule{
+	Protocol: {"UPPER_SNAKE_CASE.md", md(upperSnake.MatchString),
+		func(e Entry) string { return join(words(stem(e)), "_", strings.ToUpper) + ".md" }},
+	DomainFolder: {"PascalCase", pascal.MatchString,
+		func(e Entry) string { return join(words(stem(e)), "", title) }},
+	Folder: {"lower-kebab-case", kebab.MatchString,
+		func(e Entry) string { return join(words(stem(e)), "-", strings.ToLower) }},
+	AgentFile: {"lower-kebab-case.md", md(kebab.MatchString),
+		func(e Entry) string { return join(words(stem(e)), "-", strings.ToLower) + ".md" }},
+	ContextFile: {"lower_snake_case.md", md(snake.MatchString),
+		func(e Entry) string { return snakeName(stem(e)) + ".md" }},
+	ProjectFile: {"PROJECT_NAME.md or PLAN_NAME.md", md(project.MatchString), suggestProject},
+	SessionFile: {"YYYY-MM-DD_title.md", md(session.MatchString), suggestSession},
+	AssetFile: {"lower_snake_case", func(name string) bool {
+		return snake.MatchString(strings.TrimSuffix(name, path.Ext(name))) && path.Ext(name) == strings.ToLower(path.Ext(name))
+	}, func(e Entry) string { return snakeName(stem(e)) + strings.ToLower(path.Ext(e.Path)) }},
+}
+
+// fixed are names PAL prescribes verbatim.
+var fixed = map[string]bool{"README.md": true, "INDEX.md": true, "CONNECTIONS.yaml": true}
+
+// Classify returns the category of e, or false when no convention covers
+// it.
+func Classify(e Entry) (Category, bool) {
+	parts := strings.Split(e.Path, "/")
+	name := parts[len(parts)-1]
+	if strings.HasPrefix(name, ".") || fixed[name] {
+		return "", false
+	}
+	isMD := !e.Dir && strings.EqualFold(path.Ext(name), ".md")
+
+	switch strings.ToLower(parts[0]) {
+	case ".claude":
+		if e.Dir {
+			return Folder, len(parts) > 1
+		}
+		if !isMD || len(parts) < 3 {
+			return "", false
+		}
+		switch parts[1] {
+		case "base", "core":
+			return Protocol, true
+		case "agents":
+			return AgentFile, len(parts) == 3
+		case "sessions":
+			return SessionFile, len(parts) == 3
+		case "skills":
+			switch {
+			case len(parts) == 4 && strings.EqualFold(name, "SKILL.md"):
+				return Protocol, true
+			case len(parts) == 4, len(parts) == 5 && parts[3] == "workflows":
+				return ContextFile, true
+			}
+		}
+		return "", false
+
+	case "domains":
+		switch {
+		case len(parts) == 1:
+			return "", false
+		case len(parts) == 2:
+			if e.Dir {
+				return DomainFolder, true
+			}
+			return "", false
+		case len(parts) == 3:
+			if e.Dir {
+				return "", false // the fixed 00_CONTEXT … 05_ARCHIVE layout
+			}
+			if isMD {
+				return Protocol, true
+			}
+			return "", false
+		}
+		folder := parts[2]
+		if e.Dir {
+			if folder == "01_PROJECTS" || folder == "05_ARCHIVE" {
+				return "", false // project folders follow their own IDs
+			}
+			return Folder, true
+		}
+		switch folder {
+		case "05_ARCHIVE":
+			return "", false
+		case "03_OUTPUT":
+			return AssetFile, true
+		case "06_REQUIREMENTS":
+			return Protocol, isMD
+		}
+		if !isMD {
+			return AssetFile, true
+		}
+		switch folder {
+		case "00_CONTEXT", "02_PAGES":
+			return ContextFile, true
+		case "01_PROJECTS":
+			return ProjectFile, len(parts) == 4
+		case "04_SESSIONS":
+			// Running logs such as UPDATES.md sit beside the dated sessions.
+			if upperSnake.MatchString(stem(e)) {
+				return Protocol, true
+			}
+			return SessionFile, len(parts) == 4
+		}
+	}
+	return "", false
+}
+
+// Check returns the violation of e, if any.
+func Check(e Entry) (Violation, bool) {
+	c, ok := Classify(e)
+	if !ok {
+		return Violation{}, false
+	}
+	r := rules[c]
+	name := path.Base(e.Path)
+	if c == Protocol && strings.EqualFold(name, "SKILL.md") {
+		if name == "SKILL.md" {
+			return Violation{}, false
+		}
+		return Violation{e.Path, e.Dir, c, name, "SKILL.md", "SKILL.md"}, true
+	}
+	if r.valid(name) {
+		return Violation{}, false
+	}
+	s := r.suggest(e)
+	if s == name || !r.valid(s) {
+		s = ""
+	}
+	return Violation{e.Path, e.Dir, c, name, r.pattern, s}, true
+}
+
+// Pattern returns the expected pattern of category c.
+func Pattern(c Category) string { return rules[c].pattern }
+
+// Valid reports whether the base name follows the convention of c.
+func Valid(c Category, name string) bool { return rules[c].valid(name) }
+
+// Suggest returns a base name in the convention of c for name, or "" when
+// none can be derived.
+func Suggest(c Category, name string, dir bool) string {
+	s := rules[c].suggest(Entry{Path: name, Dir: dir})
+	if !rules[c].valid(s) {
+		return ""
+	}
+	return s
+}
+
+func md(valid func(string) bool) func(string) bool {
+	return func(name string) bool {
+		return path.Ext(name) == ".md" && valid(strings.TrimSuffix(name, ".md"))
+	}
+}
+
+func stem(e Entry) string {
+	name := path.Base(e.Path)
+	if e.Dir {
+		return name
+	}
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// words splits a name at separators and case changes:
+// "myProjectNotes v2" → my, Project, Notes, v2.
+func words(s string) []string {
+	var out []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			out = append(out, string(cur))
+			cur = nil
+		}
+	}
+	rs := []rune(s)
+	for i, r := range rs {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && len(cur) > 0 &&
+			(unicode.IsLower(cur[len(cur)-1]) || i+1 < len(rs) && unicode.IsLower(rs[i+1])):
+			flush()
+			cur = append(cur, r)
+		default:
+			cur = append(cur, r)
+		}
+	}
+	flush()
+	return out
+}
+
+func join(ws []string, sep string, f func(string) string) string {
+	out := make([]string, len(ws))
+	for i, w := range ws {
+		out[i] = f(w)
+	}
+	return strings.Join(out, sep)
+}
+
+func title(w string) string {
+	if w == "" {
+		return w
+	}
+	rs := []rune(w)
+	return string(unicode.ToUpper(rs[0])) + string(rs[1:])
+}
+
+// snakeName keeps ISO dates intact while snake-casing the rest.
+func snakeName(s string) string {
+	var parts []string
+	for {
+		loc := isoDate.FindStringIndex(s)
+		if loc == nil || len(s[loc[0]:loc[1]]) != 10 {
+			break
+		}
+		parts = append(parts, words(s[:loc[0]])...)
+		parts = append(parts, s[loc[0]:loc[1]])
+		s = s[loc[1]:]
+	}
+	parts = append(parts, words(s)...)
+	return join(parts, "_", strings.ToLower)
+}
+
+func suggestProject(e Entry) string {
+	ws := words(stem(e))
+	prefix := "PROJECT"
+	if len(ws) > 0 && (strings.EqualFold(ws[0], "plan") || strings.EqualFold(ws[0], "project")) {
+		prefix = strings.ToUpper(ws[0])
+		ws = ws[1:]
+	}
+	if len(ws) == 0 {
+		return ""
+	}
+	return prefix + "_" + join(ws, "_", strings.ToUpper) + ".md"
+}
+
+// suggestSession keeps a date found in the name, falling back to the
+// file's modification date, and snake-cases the rest as the title.
+func suggestSession(e Entry) string {
+	s := stem(e)
+	date := ""
+	if loc := isoDate.FindStringIndex(s); loc != nil {
+		d := s[loc[0]:loc[1]]
+		if len(d) == 8 {
+			d = d[:4] + "-" + d[4:6] + "-" + d[6:]
+		}
+		if _, err := time.Parse("2006-01-02", d); err == nil {
+			date = d
+			s = s[:loc[0]] + " " + s[loc[1]:]
+		}
+	}
+	if date == "" {
+		if e.ModTime.IsZero() {
+			return ""
+		}
+		date = e.ModTime.Format("2006-01-02")
+	}
+	t := join(words(s), "_", strings.ToLower)
+	if t == "" {
+		t = "session"
+	}
+	return date + "_" + t + ".md"
+}
+
+// Scan lists the entries below the given vault-relative folders (the
+// vault's Domains/ and .claude/), skipping hidden files and folders inside
+// them.
+func Scan(root string, dirs ...string) ([]Entry, error) {
+	var out []Entry
+	for _, top := range dirs {
+		dir := filepath.Join(root, top)
+		err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) && p == dir {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if p != dir && strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			out = append(out, Entry{Path: filepath.ToSlash(rel), Dir: d.IsDir(), ModTime: info.ModTime()})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,73 @@
+package naming
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	mod := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		path      string
+		dir       bool
+		ok        bool // a violation
+		category  Category
+		suggested string
+	}{
+		{path: "Domains/Work", dir: true},
+		{path: "Domains/my work", dir: true, ok: true, category: DomainFolder, suggested: "MyWork"},
+		{path: "Domains/Work/INDEX.md"},
+		{path: "Domains/Work/00_CONTEXT", dir: true},
+		{path: "Domains/Work/goals.md", ok: true, category: Protocol, suggested: "GOALS.md"},
+		{path: "Domains/Work/00_CONTEXT/brand_voice.md"},
+		{path: "Domains/Work/00_CONTEXT/Brand Voice.md", ok: true, category: ContextFile, suggested: "brand_voice.md"},
+		{path: "Domains/Work/00_CONTEXT/life summary 2026-03-01.md", ok: true, category: ContextFile, suggested: "life_summary_2026-03-01.md"},
+		{path: "Domains/Work/00_CONTEXT/Old Notes", dir: true, ok: true, category: Folder, suggested: "old-notes"},
+		{path: "Domains/Work/01_PROJECTS/PROJECT_API.md"},
+		{path: "Domains/Work/01_PROJECTS/PLAN_Q3.md"},
+		{path: "Domains/Work/01_PROJECTS/project_goals.md", ok: true, category: ProjectFile, suggested: "PROJECT_GOALS.md"},
+		{path: "Domains/Work/01_PROJECTS/api redesign.md", ok: true, category: ProjectFile, suggested: "PROJECT_API_REDESIGN.md"},
+		{path: "Domains/Work/01_PROJECTS/Drafts", dir: true},
+		{path: "Domains/Work/03_OUTPUT/Deck Final.PDF", ok: true, category: AssetFile, suggested: "deck_final.pdf"},
+		{path: "Domains/Work/04_SESSIONS/2026-03-01_kickoff.md"},
+		{path: "Domains/Work/04_SESSIONS/UPDATES.md"},
+		{path: "Domains/Work/04_SESSIONS/Kickoff 20260302.md", ok: true, category: SessionFile, suggested: "2026-03-02_kickoff.md"},
+		{path: "Domains/Work/04_SESSIONS/Retro.md", ok: true, category: SessionFile, suggested: "2026-03-01_retro.md"},
+		{path: "Domains/Work/05_ARCHIVE/whatever name.md"},
+		{path: "Domains/Work/06_REQUIREMENTS/reqs.md", ok: true, category: Protocol, suggested: "REQS.md"},
+		{path: ".claude/agents/Content Writer.md", ok: true, category: AgentFile, suggested: "content-writer.md"},
+		{path: ".claude/skills/Writing", dir: true, ok: true, category: Folder, suggested: "writing"},
+		{path: ".claude/skills/writing/skill.md", ok: true, category: Protocol, suggested: "SKILL.md"},
+		{path: ".claude/skills/writing/SKILL.md"},
+		{path: ".claude/skills/writing/workflows/Draft Post.md", ok: true, category: ContextFile, suggested: "draft_post.md"},
+		{path: ".claude/core/rules.md", ok: true, category: Protocol, suggested: "RULES.md"},
+		{path: "Domains/Work/README.md"},
+		{path: "Domains/Work/00_CONTEXT/.hidden.md"},
+		{path: "Domains/Work/02_PAGES/---.md", ok: true, category: ContextFile, suggested: ""},
+	}
+	for _, tt := range tests {
+		got, ok := Check(Entry{Path: tt.path, Dir: tt.dir, ModTime: mod})
+		if ok != tt.ok {
+			t.Errorf("Check(%q) = %+v, %v; want a violation: %v", tt.path, got, ok, tt.ok)
+			continue
+		}
+		if ok && (got.Category != tt.category || got.Suggested != tt.suggested) {
+			t.Errorf("Check(%q) = %s, suggest %q; want %s, suggest %q", tt.path, got.Category, got.Suggested, tt.category, tt.suggested)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := map[string]string{
+		"myProjectNotes v2": "my Project Notes v2",
+		"HTTPServer":        "HTTP Server",
+		"already_snake":     "already snake",
+		"  ":                "",
+	}
+	for in, want := range tests {
+		got := join(words(in), " ", func(s string) string { return s })
+		if got != want {
+			t.Errorf("words(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
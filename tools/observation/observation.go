@@ -0,0 +1,145 @@
+// Package observation parses the categorised statements PAL notes are built
+// from (requirements 1.4.9 and 1.4.11):
+//
+//	syntax:   - [category] content #tag1 #tag2
+//	example:  - [decision] Ship the API behind a feature flag #api #release
+//
+// Only ten categories are valid. Check reports every other category together
+// with the closest valid one, and Fix rewrites them.
+package observation
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/tasks"
+)
+
+// Categories are the valid observation categories, in the order the
+// note-taking templates list them.
+var Categories = []string{
+	"fact", "idea", "decision", "technique", "requirement",
+	"question", "insight", "problem", "solution", "action",
+}
+
+// Observation is one "- [category] content #tags" line.
+type Observation struct {
+	Category string
+	// Content is the text after the category, tags included.
+	Content string
+	Tags    []string
+	// Line is the 1-based line number; Raw the line as read.
+	Line int
+	Raw  string
+}
+
+// A category is a single word of two or more characters. Single characters
+// are task checkboxes ("[x]", "[I]") and a following "(" or ":" makes the
+// brackets a markdown link or footnote instead.
+var obsLine = regexp.MustCompile(`^([ \t]*[-*+][ \t]+)\[([\p{L}][\p{L}\p{N}_-]+)\]([ \t]+)(\S.*)$`)
+
+// Parse parses a single line. ok is false when the line is not an
+// observation.
+func Parse(line string) (o Observation, ok bool) {
+	line = strings.TrimSuffix(line, "\r")
+	m := obsLine.FindStringSubmatch(line)
+	if m == nil {
+		return Observation{}, false
+	}
+	return Observation{
+		Category: m[2],
+		Content:  m[4],
+		Tags:     tasks.Tags(m[4]),
+		Raw:      line,
+	}, true
+}
+
+// Valid reports whether category is one of the ten Categories. Case matters:
+// "[Fact]" is flagged so that Fix can normalise it.
+func Valid(category string) bool {
+	for _, c := range Categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseDocument returns every observation in text outside fenced code.
+func ParseDocument(text string) []Observation {
+	lines := markdown.Lines(text)
+	fenced := markdown.InFence(lines)
+	var out []Observation
+	for i, line := range lines {
+		if fenced[i] {
+			continue
+		}
+		if o, ok := Parse(line); ok {
+			o.Line = i + 1
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// Finding is an observation with an invalid category.
+type Finding struct {
+	Observation
+	// Suggestion is the valid category Fix would use.
+	Suggestion string
+}
+
+// Check returns a Finding for every invalid observation in text.
+func Check(text string) []Finding {
+	var out []Finding
+	for _, o := range ParseDocument(text) {
+		if !Valid(o.Category) {
+			out = append(out, Finding{Observation: o, Suggestion: Suggest(o.Category)})
+		}
+	}
+	return out
+}
+
+// Fix replaces every invalid category in text with its suggestion. Lines from
+// the protected "## Notes" heading down are reported by Check but never
+// rewritten; the returned findings are the ones that were fixed.
+func Fix(text string) (string, []Finding) {
+	nl := "\n"
+	if strings.Contains(text, "\r\n") {
+		nl = "\r\n"
+	}
+	lines := markdown.Lines(text)
+	var fixed []Finding
+	for _, f := range Check(text) {
+		if protected(lines, f.Line) {
+			continue
+		}
+		m := obsLine.FindStringSubmatch(lines[f.Line-1])
+		lines[f.Line-1] = m[1] + "[" + f.Suggestion + "]" + m[3] + m[4]
+		fixed = append(fixed, f)
+	}
+	if len(fixed) == 0 {
+		return text, nil
+	}
+	out := strings.Join(lines, nl)
+	if strings.HasSuffix(text, "\n") {
+		out += nl
+	}
+	return out, fixed
+}
+
+// protected reports whether the 1-based line sits at or below the first
+// level-two "Notes" heading.
+func protected(lines []string, line int) bool {
+	fenced := markdown.InFence(lines)
+	for i := 0; i < line && i < len(lines); i++ {
+		if fenced[i] {
+			continue
+		}
+		if h, ok := markdown.ParseHeading(lines[i]); ok && h.Level == 2 && strings.EqualFold(h.Text, "Notes") {
+			return true
+		}
+	}
+	return false
+}
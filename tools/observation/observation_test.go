@@ -0,0 +1,91 @@
+package observation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		line string
+		want Observation
+		ok   bool
+	}{
+		{
+			line: "- [decision] Ship behind a flag #api #release",
+			want: Observation{Category: "decision", Content: "Ship behind a flag #api #release", Tags: []string{"api", "release"}},
+			ok:   true,
+		},
+		{line: "  * [Bug]\tCrash on save\r", want: Observation{Category: "Bug", Content: "Crash on save"}, ok: true},
+		{line: "- [x] done task"},
+		{line: "- [ ] open task"},
+		{line: "- [link](target.md) text"},
+		{line: "- [fact]"},
+		{line: "[fact] not a list item"},
+		{line: "- [2fa] starts with a digit"},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.line)
+		got.Raw = ""
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := map[string]string{
+		"fact":       "fact",
+		"Decision":   "decision",
+		"todo":       "action",
+		"Bug":        "problem",
+		"how-to":     "technique",
+		"questions":  "question",
+		"insigt":     "insight",
+		"requirment": "requirement",
+		"musing":     "idea",
+	}
+	for in, want := range tests {
+		if got := Suggest(in); got != want {
+			t.Errorf("Suggest(%q) = %q, want %q", in, got, want)
+		}
+	}
+	for alias, c := range aliases {
+		if !Valid(c) {
+			t.Errorf("alias %q maps to invalid category %q", alias, c)
+		}
+	}
+}
+
+func TestCheckAndFix(t *testing.T) {
+	in := "# Note\r\n\r\n- [fact] fine\r\n- [todo] call Sam #work\r\n```\r\n- [bogus] in a fence\r\n```\r\n" +
+		"  - [Insight]  nested\r\n\r\n## Notes\r\n\r\n- [misc] mine\r\n"
+	tests := []struct {
+		line           int
+		category, want string
+	}{
+		{line: 4, category: "todo", want: "action"},
+		{line: 8, category: "Insight", want: "insight"},
+		{line: 12, category: "misc", want: "idea"},
+	}
+	found := Check(in)
+	if len(found) != len(tests) {
+		t.Fatalf("Check gave %d findings, want %d: %+v", len(found), len(tests), found)
+	}
+	for i, tt := range tests {
+		if f := found[i]; f.Line != tt.line || f.Category != tt.category || f.Suggestion != tt.want {
+			t.Errorf("finding %d = line %d [%s] → %s; want line %d [%s] → %s",
+				i, f.Line, f.Category, f.Suggestion, tt.line, tt.category, tt.want)
+		}
+	}
+
+	out, fixed := Fix(in)
+	want := "# Note\r\n\r\n- [fact] fine\r\n- [action] call Sam #work\r\n```\r\n- [bogus] in a fence\r\n```\r\n" +
+		"  - [insight]  nested\r\n\r\n## Notes\r\n\r\n- [misc] mine\r\n"
+	if out != want || len(fixed) != 2 {
+		t.Errorf("Fix = %q (%d fixed), want %q", out, len(fixed), want)
+	}
+	if again, fixed := Fix(out); again != out || fixed != nil {
+		t.Errorf("Fix of a fixed note changed it: %q", again)
+	}
+}
@@ -0,0 +1,68 @@
+package observation
+
+import (
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/fuzzy"
+)
+
+// aliases maps words people reach for instead of a valid category.
+var aliases = map[string]string{
+	"note":        "fact",
+	"info":        "fact",
+	"data":        "fact",
+	"stat":        "fact",
+	"thought":     "idea",
+	"random":      "idea",
+	"misc":        "idea",
+	"brainstorm":  "idea",
+	"choice":      "decision",
+	"decided":     "decision",
+	"how-to":      "technique",
+	"howto":       "technique",
+	"method":      "technique",
+	"tip":         "technique",
+	"pattern":     "technique",
+	"req":         "requirement",
+	"must":        "requirement",
+	"constraint":  "requirement",
+	"q":           "question",
+	"ask":         "question",
+	"unknown":     "question",
+	"learning":    "insight",
+	"lesson":      "insight",
+	"learned":     "insight",
+	"observation": "insight",
+	"realization": "insight",
+	"issue":       "problem",
+	"bug":         "problem",
+	"risk":        "problem",
+	"blocker":     "problem",
+	"fix":         "solution",
+	"answer":      "solution",
+	"resolution":  "solution",
+	"todo":        "action",
+	"task":        "action",
+	"next":        "action",
+	"next-step":   "action",
+}
+
+// Suggest returns the valid category closest to category: the category
+// itself in lower case, a known alias ("todo" → "action"), a near spelling
+// ("insigt" → "insight"), or "idea" as the catch-all for free-form thoughts.
+func Suggest(category string) string {
+	c := strings.ToLower(category)
+	if Valid(c) {
+		return c
+	}
+	if a, ok := aliases[c]; ok {
+		return a
+	}
+	if s := strings.TrimSuffix(c, "s"); Valid(s) {
+		return s
+	}
+	if best, dist := fuzzy.Closest(c, Categories); fuzzy.Near(c, dist) {
+		return best
+	}
+	return "idea"
+}
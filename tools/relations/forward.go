@@ -0,0 +1,104 @@
+package relations
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// LedgerFile is the forward-reference ledger's file name inside the vault's
+// state folder (.claude/state by default).
+const LedgerFile = "forward-references.json"
+
+// Resolver finds the note a wikilink target points to. from is the
+// vault-relative path of the note containing the link.
+type Resolver interface {
+	Resolve(from, target string) (path string, ok bool)
+}
+
+// Pending is a forward reference: a relation whose target note does not
+// exist yet (requirement 1.4.16).
+type Pending struct {
+	From   string `json:"from"`
+	Line   int    `json:"line"`
+	Type   string `json:"type"`
+	Target string `json:"target"`
+}
+
+// Resolved is a previously pending reference whose target now exists.
+type Resolved struct {
+	Pending
+	// Path is the vault-relative path of the note that resolved it.
+	Path string
+}
+
+// Ledger remembers the pending forward references of the last full scan,
+// so the next scan can tell which ones a newly created note resolved.
+type Ledger struct {
+	Pending []Pending `json:"pending"`
+}
+
+// LoadLedger reads the ledger at path. A missing file is an empty ledger.
+func LoadLedger(path string) (*Ledger, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Ledger{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var l Ledger
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// Save writes the ledger to path, creating its folder if needed.
+func (l *Ledger) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Update replaces the ledger's pending list with current and returns the
+// previously pending references that r now resolves. References that simply
+// disappeared (the relation line was deleted) are dropped silently.
+func (l *Ledger) Update(current []Pending, r Resolver) []Resolved {
+	still := map[Pending]bool{}
+	for _, p := range current {
+		still[key(p)] = true
+	}
+	var out []Resolved
+	for _, p := range l.Pending {
+		if still[key(p)] {
+			continue
+		}
+		if path, ok := r.Resolve(p.From, p.Target); ok {
+			out = append(out, Resolved{Pending: p, Path: path})
+		}
+	}
+	sort.SliceStable(current, func(i, j int) bool {
+		if current[i].From != current[j].From {
+			return current[i].From < current[j].From
+		}
+		return current[i].Line < current[j].Line
+	})
+	l.Pending = current
+	return out
+}
+
+// key identifies a pending reference independent of its line number, which
+// shifts as the note is edited.
+func key(p Pending) Pending {
+	p.Line = 0
+	return p
+}
@@ -0,0 +1,134 @@
+// Package relations parses and validates the "## Relations" section of PAL
+// notes (requirements 1.4.15 to 1.4.17):
+//
+//	## Relations
+//
+//	- part_of [[Launch Plan]]
+//	- inspired_by [[Deep Work]]
+//
+// Each line names one of ten relation types and a wikilink target. A note
+// may carry at most five relations, and targets may be forward references
+// to notes that do not exist yet.
+package relations
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/fuzzy"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/wikilink"
+)
+
+// Types are the valid relation types.
+var Types = []string{
+	"part_of", "supports", "contradicts", "evolved_from", "informs",
+	"blocks", "inspired_by", "relates_to", "originated_with", "follows",
+}
+
+// MaxPerNote is the relation cap of requirement 1.4.17.
+const MaxPerNote = 5
+
+// SectionTitle is the heading that holds a note's relations.
+const SectionTitle = "Relations"
+
+// Relation is one "- type [[Target]]" line.
+type Relation struct {
+	Type string
+	// Target is the text inside the wikilink brackets, alias and heading
+	// included, e.g. "Launch Plan|the plan".
+	Target string
+	// Line is the 1-based line number; Raw the line as read.
+	Line int
+	Raw  string
+}
+
+// Note returns the target note name: Target without "|alias" or
+// "#heading".
+func (r Relation) Note() string {
+	return wikilink.Parse(r.Target).Target
+}
+
+var relLine = regexp.MustCompile(`^[ \t]*[-*+][ \t]+([A-Za-z_]+)[ \t]+\[\[([^\]]+)\]\]`)
+
+// Kind classifies a Problem.
+type Kind string
+
+const (
+	InvalidType Kind = "invalid-type"
+	TooMany     Kind = "too-many"
+	Malformed   Kind = "malformed"
+)
+
+// Problem is a rule violation inside a Relations section.
+type Problem struct {
+	Kind    Kind
+	Line    int
+	Message string
+}
+
+// Parse returns the relations in text's Relations section and any problems
+// with them. found is false when the note has no such section.
+func Parse(text string) (rels []Relation, problems []Problem, found bool) {
+	lines, start, ok := markdown.Section(text, SectionTitle)
+	if !ok {
+		return nil, nil, false
+	}
+	fenced := markdown.InFence(lines)
+	for i, line := range lines {
+		if fenced[i] || strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "<!--") {
+			continue
+		}
+		n := start + i
+		m := relLine.FindStringSubmatch(line)
+		if m == nil {
+			if isListItem(line) {
+				problems = append(problems, Problem{Malformed, n,
+					fmt.Sprintf("%q is not a relation; expected \"- relation_type [[Target]]\"", strings.TrimSpace(line))})
+			}
+			continue
+		}
+		r := Relation{Type: m[1], Target: m[2], Line: n, Raw: line}
+		if !Valid(r.Type) {
+			problems = append(problems, Problem{InvalidType, n,
+				fmt.Sprintf("unknown relation type %q, suggest %q", r.Type, Suggest(r.Type))})
+		}
+		rels = append(rels, r)
+	}
+	for i := MaxPerNote; i < len(rels); i++ {
+		problems = append(problems, Problem{TooMany, rels[i].Line,
+			fmt.Sprintf("relation %d of %d exceeds the limit of %d; replace an existing relation or drop this one", i+1, len(rels), MaxPerNote)})
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+	return rels, problems, true
+}
+
+// Valid reports whether t is one of the ten relation Types.
+func Valid(t string) bool {
+	for _, v := range Types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Suggest returns the relation type closest to t, or "relates_to" when
+// nothing is close.
+func Suggest(t string) string {
+	norm := strings.ReplaceAll(strings.ToLower(t), "-", "_")
+	if Valid(norm) {
+		return norm
+	}
+	if best, dist := fuzzy.Closest(norm, Types); fuzzy.Near(norm, dist) {
+		return best
+	}
+	return "relates_to"
+}
+
+func isListItem(line string) bool {
+	t := strings.TrimLeft(line, " \t")
+	return strings.HasPrefix(t, "- ") || strings.HasPrefix(t, "* ") || strings.HasPrefix(t, "+ ")
+}
@@ -0,0 +1,109 @@
+package relations
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	text := "# Note\n\n## Relations\n\n<!-- - part_of [[Template]] -->\n" +
+		"- part_of [[Launch Plan|the plan]]\n- inspird_by [[Deep Work]]\n- see also Deep Work\n\n" +
+		"- supports [[A]]\n- informs [[B#Goals]]\n- blocks [[C]]\n- follows [[D]]\n\n## Notes\n\n- part_of [[Ignored]]\n"
+	rels, problems, found := Parse(text)
+	if !found {
+		t.Fatal("Parse found no Relations section")
+	}
+	want := []struct {
+		typ, target, note string
+		line              int
+	}{
+		{typ: "part_of", target: "Launch Plan|the plan", note: "Launch Plan", line: 6},
+		{typ: "inspird_by", target: "Deep Work", note: "Deep Work", line: 7},
+		{typ: "supports", target: "A", note: "A", line: 10},
+		{typ: "informs", target: "B#Goals", note: "B", line: 11},
+		{typ: "blocks", target: "C", note: "C", line: 12},
+		{typ: "follows", target: "D", note: "D", line: 13},
+	}
+	if len(rels) != len(want) {
+		t.Fatalf("Parse gave %d relations, want %d: %+v", len(rels), len(want), rels)
+	}
+	for i, w := range want {
+		r := rels[i]
+		if r.Type != w.typ || r.Target != w.target || r.Note() != w.note || r.Line != w.line {
+			t.Errorf("relation %d = %+v (note %q), want %+v", i, r, r.Note(), w)
+		}
+	}
+
+	wantProblems := []Problem{
+		{InvalidType, 7, `unknown relation type "inspird_by", suggest "inspired_by"`},
+		{Malformed, 8, `"- see also Deep Work" is not a relation; expected "- relation_type [[Target]]"`},
+		{TooMany, 13, "relation 6 of 6 exceeds the limit of 5; replace an existing relation or drop this one"},
+	}
+	if !reflect.DeepEqual(problems, wantProblems) {
+		t.Errorf("problems = %+v, want %+v", problems, wantProblems)
+	}
+
+	if _, _, found := Parse("# Note\n\n## Notes\n"); found {
+		t.Error("Parse found a Relations section in a note without one")
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := map[string]string{
+		"part_of":     "part_of",
+		"Part-Of":     "part_of",
+		"evolvd_from": "evolved_from",
+		"contradict":  "contradicts",
+		"reminds_me":  "relates_to",
+		"see_also":    "relates_to",
+	}
+	for in, want := range tests {
+		if got := Suggest(in); got != want {
+			t.Errorf("Suggest(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// notes resolves a target when it names one of its keys.
+type notes map[string]string
+
+func (n notes) Resolve(from, target string) (string, bool) {
+	p, ok := n[target]
+	return p, ok
+}
+
+func TestLedger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", LedgerFile)
+	l, err := LoadLedger(path)
+	if err != nil || len(l.Pending) != 0 {
+		t.Fatalf("LoadLedger of a missing file = %+v, %v", l, err)
+	}
+
+	plan := Pending{From: "a.md", Line: 3, Type: "part_of", Target: "Plan"}
+	idea := Pending{From: "a.md", Line: 4, Type: "informs", Target: "Idea"}
+	gone := Pending{From: "b.md", Line: 1, Type: "blocks", Target: "Gone"}
+	if got := l.Update([]Pending{idea, gone, plan}, notes{}); got != nil {
+		t.Errorf("first Update resolved %+v", got)
+	}
+	if err := l.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if l, err = LoadLedger(path); err != nil {
+		t.Fatal(err)
+	}
+	if want := []Pending{plan, idea, gone}; !reflect.DeepEqual(l.Pending, want) {
+		t.Errorf("saved ledger = %+v, want %+v", l.Pending, want)
+	}
+
+	// Plan now exists, Idea moved down a line and Gone's relation was deleted.
+	moved := idea
+	moved.Line = 5
+	got := l.Update([]Pending{moved}, notes{"Plan": "Domains/Work/02_PAGES/Plan.md"})
+	if want := []Resolved{{Pending: plan, Path: "Domains/Work/02_PAGES/Plan.md"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Update resolved %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(l.Pending, []Pending{moved}) {
+		t.Errorf("pending after Update = %+v", l.Pending)
+	}
+}
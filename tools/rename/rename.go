@@ -0,0 +1,482 @@
+// Package rename plans moving vault files and folders together with
+// rewriting the links that pointed at them, so a rename never leaves a
+// dangling [[wikilink]] or markdown link behind. Wikilinks keep their
+// heading, block and alias and are rewritten the way Obsidian would write
+// them; markdown links keep their style, relative to the linking file or
+// to the vault root. A Plan goes into a changeset.Set, which applies it.
+package rename
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/changeset"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/frontmatter"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/wikilink"
+)
+
+// Move renames one vault-relative file or folder.
+type Move struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Edit is one rewritten link. File is the linking file's path after the
+// moves and Line its 1-based line.
+type Edit struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+	// Protected is set for links below a note's "## Notes" heading, which
+	// are reported but never rewritten.
+	Protected bool `json:"protected,omitempty"`
+}
+
+// Plan is the full effect of a set of moves.
+type Plan struct {
+	Moves []Move
+	Edits []Edit
+
+	root   string
+	texts  map[string][]byte // final path → rewritten content
+	modes  map[string]fs.FileMode
+	origin map[string]string // final path → path before the moves
+}
+
+// Prepare works out every link that moves would break and how to rewrite
+// it, without touching the vault. Moves run in order, so a file renamed
+// before its folder is moved along with it: list deeper paths first.
+func Prepare(root string, moves []Move) (*Plan, error) {
+	files, dirs, err := Walk(root)
+	if err != nil {
+		return nil, err
+	}
+	exists := map[string]bool{}
+	for _, f := range append(files, dirs...) {
+		exists[f] = true
+	}
+	p := &Plan{
+		Moves:  moves,
+		root:   root,
+		texts:  map[string][]byte{},
+		modes:  map[string]fs.FileMode{},
+		origin: map[string]string{},
+	}
+	if err := p.check(append(files, dirs...)); err != nil {
+		return nil, err
+	}
+
+	final := make([]string, len(files))
+	for i, f := range files {
+		final[i] = p.Final(f)
+	}
+	oldR := wikilink.NewResolver(files)
+	newR := wikilink.NewResolver(final)
+
+	for i, f := range files {
+		if !strings.EqualFold(path.Ext(f), ".md") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(f)))
+		if err != nil {
+			return nil, err
+		}
+		nf := final[i]
+		lines := strings.SplitAfter(string(data), "\n")
+		protectedFrom := protectedLine(data)
+		changed := false
+
+		for _, l := range wikilink.Find(string(data)) {
+			res, ok := oldR.Lookup(f, l)
+			if !ok || l.Target == "" {
+				continue
+			}
+			target := p.Final(res.Path)
+			if now, ok := newR.Lookup(nf, l); ok && now.Path == target {
+				continue
+			}
+			nl := l
+			switch {
+			case strings.HasPrefix(l.Target, "./") || strings.HasPrefix(l.Target, "../"):
+				nl.Target = relative(path.Dir(nf), trimMD(target))
+				if !strings.HasPrefix(nl.Target, "../") {
+					nl.Target = "./" + nl.Target
+				}
+			case strings.Contains(l.Target, "/"):
+				nl.Target = partial(newR, nf, trimMD(target), strings.Count(l.Target, "/")+1)
+			default:
+				nl.Target = newR.Linktext(target)
+			}
+			if p.edit(lines, nf, l.Line, l.Col-1, l.Raw, nl.String(), protectedFrom) {
+				changed = true
+			}
+		}
+
+		for _, l := range markdown.Links(string(data)) {
+			if l.Target == "" {
+				continue // same-page anchor
+			}
+			target, rooted := ResolveLink(f, l.Target, exists)
+			if target == "" {
+				continue
+			}
+			nt := p.Final(target)
+			if nt == target && nf == f {
+				continue
+			}
+			var dest string
+			switch {
+			case rooted && strings.HasPrefix(l.Target, "/"):
+				dest = "/" + nt
+			case rooted:
+				dest = nt
+			default:
+				dest = relative(path.Dir(nf), nt)
+			}
+			if strings.HasSuffix(l.Target, "/") {
+				dest += "/" // folder link
+			}
+			if strings.Contains(l.Target, "%") {
+				dest = (&url.URL{Path: dest}).EscapedPath()
+			}
+			if dest == l.Target {
+				continue
+			}
+			newRaw := l.Raw[:l.TargetStart] + dest + l.Raw[l.TargetStart+len(l.Target):]
+			if p.edit(lines, nf, l.Line, l.Col, l.Raw, newRaw, protectedFrom) {
+				changed = true
+			}
+		}
+
+		if changed {
+			p.texts[nf] = []byte(strings.Join(lines, ""))
+			p.origin[nf] = f
+			if info, err := os.Stat(filepath.Join(root, filepath.FromSlash(f))); err == nil {
+				p.modes[nf] = info.Mode().Perm()
+			}
+		}
+	}
+	p.sortEdits()
+	return p, nil
+}
+
+// CollisionError lists moves that would put two files or folders on one
+// path, or a folder inside itself. Paths are compared ignoring case, as
+// macOS and Windows do, so "goals.md" and "project_goals.md" both renamed
+// to "PROJECT_GOALS.md" collide, and so does a rename onto "Notes.md" when
+// "notes.md" stays.
+type CollisionError struct {
+	Moves []Move
+}
+
+func (e *CollisionError) Error() string {
+	s := make([]string, len(e.Moves))
+	for i, m := range e.Moves {
+		s[i] = m.From + " -> " + m.To
+	}
+	return "moves collide with other paths: " + strings.Join(s, ", ")
+}
+
+// Check makes sure moves can run on the vault at root: every source
+// exists, and no two paths end up on one name. It returns a
+// *CollisionError for the moves that collide.
+func Check(root string, moves []Move) error {
+	files, dirs, err := Walk(root)
+	if err != nil {
+		return err
+	}
+	return (&Plan{Moves: moves, root: root}).check(append(files, dirs...))
+}
+
+// check validates p's moves against paths, every file and folder of the
+// vault.
+func (p *Plan) check(paths []string) error {
+	var bad []Move
+	final := make([]string, len(p.Moves)) // where each move's target ends up
+	for i, m := range p.Moves {
+		if _, err := os.Stat(filepath.Join(p.root, filepath.FromSlash(m.From))); err != nil {
+			return fmt.Errorf("cannot move %s: %w", m.From, err)
+		}
+		final[i] = m.To
+		for _, later := range p.Moves[i+1:] {
+			final[i] = moved(final[i], later)
+		}
+	}
+	collides := make([]bool, len(p.Moves))
+	for i, m := range p.Moves {
+		if strings.EqualFold(m.From, m.To) {
+			continue // case-only rename
+		}
+		if _, err := os.Stat(filepath.Join(p.root, filepath.FromSlash(m.To))); err == nil {
+			collides[i] = true
+		}
+		if strings.HasPrefix(strings.ToLower(m.To), strings.ToLower(m.From)+"/") {
+			collides[i] = true
+		}
+	}
+	owner := map[string]string{} // final path, lowercased → path before
+	for _, f := range paths {
+		k := strings.ToLower(p.Final(f))
+		if o, ok := owner[k]; !ok || o == f {
+			owner[k] = f
+			continue
+		}
+		for i, fin := range final {
+			if fin = strings.ToLower(fin); k == fin || strings.HasPrefix(k, fin+"/") {
+				collides[i] = true
+			}
+		}
+	}
+	for i, m := range p.Moves {
+		if collides[i] {
+			bad = append(bad, m)
+		}
+	}
+	if len(bad) > 0 {
+		return &CollisionError{Moves: bad}
+	}
+	return nil
+}
+
+func (p *Plan) sortEdits() {
+	sort.SliceStable(p.Edits, func(i, j int) bool {
+		if p.Edits[i].File != p.Edits[j].File {
+			return p.Edits[i].File < p.Edits[j].File
+		}
+		return p.Edits[i].Line < p.Edits[j].Line
+	})
+}
+
+// edit replaces old with new at byte col of the 1-based line, unless the
+// line is protected. It reports whether the text changed.
+func (p *Plan) edit(lines []string, file string, line, col int, old, new string, protectedFrom int) bool {
+	e := Edit{File: file, Line: line, Old: old, New: new, Protected: protectedFrom > 0 && line >= protectedFrom}
+	p.Edits = append(p.Edits, e)
+	if e.Protected {
+		return false
+	}
+	s := lines[line-1]
+	// Earlier edits on the same line may have shifted col; find old from
+	// there on, falling back to the first occurrence.
+	i := strings.Index(s[min(col, len(s)):], old)
+	if i >= 0 {
+		i += min(col, len(s))
+	} else if i = strings.Index(s, old); i < 0 {
+		return false
+	}
+	lines[line-1] = s[:i] + new + s[i+len(old):]
+	return true
+}
+
+// Final returns where the vault-relative path rel ends up after the moves.
+func (p *Plan) Final(rel string) string {
+	for _, m := range p.Moves {
+		rel = moved(rel, m)
+	}
+	return rel
+}
+
+// Text returns the content the file at the final path will have after
+// the moves: its rewritten links, or else what is on disk now.
+func (p *Plan) Text(final string) ([]byte, error) {
+	if t, ok := p.texts[final]; ok {
+		return t, nil
+	}
+	return os.ReadFile(filepath.Join(p.root, filepath.FromSlash(p.source(final))))
+}
+
+// Rewrite plans further edits to the file at the final path, on top of
+// its link rewrites, such as a renamed name in a table row. data must keep
+// the file's lines, so that each changed line is reported as an Edit;
+// lines below "## Notes" are reported but left as they were.
+func (p *Plan) Rewrite(final string, data []byte) error {
+	cur, err := p.Text(final)
+	if err != nil {
+		return err
+	}
+	old := strings.SplitAfter(string(cur), "\n")
+	lines := strings.SplitAfter(string(data), "\n")
+	if len(old) != len(lines) {
+		return fmt.Errorf("%s: a rewrite must keep the file's %d lines", final, len(old))
+	}
+	protectedFrom := protectedLine(cur)
+	changed := false
+	for i := range lines {
+		if lines[i] == old[i] {
+			continue
+		}
+		e := Edit{
+			File:      final,
+			Line:      i + 1,
+			Old:       strings.TrimRight(old[i], "\r\n"),
+			New:       strings.TrimRight(lines[i], "\r\n"),
+			Protected: protectedFrom > 0 && i+1 >= protectedFrom,
+		}
+		p.Edits = append(p.Edits, e)
+		if !e.Protected {
+			old[i] = lines[i]
+			changed = true
+		}
+	}
+	p.sortEdits()
+	if !changed {
+		return nil
+	}
+	src := p.source(final)
+	p.texts[final] = []byte(strings.Join(old, ""))
+	p.origin[final] = src
+	if info, err := os.Stat(filepath.Join(p.root, filepath.FromSlash(src))); err == nil {
+		p.modes[final] = info.Mode().Perm()
+	}
+	return nil
+}
+
+// source returns where the file at the final path is before the moves.
+func (p *Plan) source(final string) string {
+	for i := len(p.Moves) - 1; i >= 0; i-- {
+		final = moved(final, Move{From: p.Moves[i].To, To: p.Moves[i].From})
+	}
+	return final
+}
+
+func moved(rel string, m Move) string {
+	switch {
+	case rel == m.From:
+		return m.To
+	case strings.HasPrefix(rel, m.From+"/"):
+		return m.To + rel[len(m.From):]
+	}
+	return rel
+}
+
+// AddTo plans p in set: the moves, each for why, then the files whose
+// links it rewrites. Links below a note's "## Notes" heading become risks
+// of the plan, to update by hand.
+func (p *Plan) AddTo(set *changeset.Set, why string) error {
+	for _, m := range p.Moves {
+		if err := set.Move(m.From, m.To, why); err != nil {
+			return err
+		}
+	}
+	files := make([]string, 0, len(p.texts))
+	for f := range p.texts {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		if err := set.Modify(f, p.texts[f], "rewrite links to the moved paths"); err != nil {
+			return err
+		}
+	}
+	for _, ed := range p.Edits {
+		if ed.Protected {
+			set.Risk("%s:%d: %s is below ## Notes and stays as is; update it by hand to %s", ed.File, ed.Line, ed.Old, ed.New)
+		}
+	}
+	return nil
+}
+
+// Walk lists every file and folder under root except version-control and
+// editor state. Unlike vault.AllFiles it includes .claude, whose markdown
+// links point into the vault too.
+func Walk(root string) (files, dirs []string, err error) {
+	err = filepath.WalkDir(root, func(p string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := e.Name()
+		if p != root && strings.HasPrefix(name, ".") && name != ".claude" {
+			if e.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if e.IsDir() {
+			dirs = append(dirs, filepath.ToSlash(rel))
+		} else {
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return files, dirs, err
+}
+
+// protectedLine returns the 1-based line of the "## Notes" heading, or 0.
+func protectedLine(data []byte) int {
+	tail, ok := frontmatter.ProtectedTail(data)
+	if !ok {
+		return 0
+	}
+	return bytes.Count(data[:len(data)-len(tail)], []byte("\n")) + 1
+}
+
+// ResolveLink finds the file or folder a markdown link target in from
+// points to: a path relative to from's folder, or else relative to the
+// vault root. The bool reports the latter; exists holds every vault-relative
+// file and folder, as Walk lists them.
+func ResolveLink(from, target string, exists map[string]bool) (string, bool) {
+	t := target
+	if u, err := url.PathUnescape(t); err == nil {
+		t = u
+	}
+	if strings.HasPrefix(t, "/") {
+		if t = path.Clean(t[1:]); exists[t] {
+			return t, true
+		}
+		return "", false
+	}
+	if p := path.Join(path.Dir(from), t); exists[p] {
+		return p, false
+	}
+	if p := path.Clean(t); exists[p] {
+		return p, true
+	}
+	return "", false
+}
+
+// relative returns the path of target as seen from dir.
+func relative(dir, target string) string {
+	r, err := filepath.Rel(filepath.FromSlash(dir), filepath.FromSlash(target))
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(r)
+}
+
+// partial returns the last n elements of target when that still resolves
+// to target from file, keeping a link as short as it was written, and the
+// full path otherwise.
+func partial(r *wikilink.Resolver, file, target string, n int) string {
+	parts := strings.Split(target, "/")
+	if n >= len(parts) {
+		return target
+	}
+	short := strings.Join(parts[len(parts)-n:], "/")
+	if res, ok := r.Resolve(file, short); ok && trimMD(res) == target {
+		return short
+	}
+	return target
+}
+
+func trimMD(p string) string {
+	if strings.EqualFold(path.Ext(p), ".md") {
+		return p[:len(p)-len(path.Ext(p))]
+	}
+	return p
+}
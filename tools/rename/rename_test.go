@@ -0,0 +1,144 @@
+package rename
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func vault(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for p, data := range files {
+		abs := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(abs, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestPrepareRewritesLinks(t *testing.T) {
+	root := vault(t, map[string]string{
+		"Home.md": "[[Brand Notes]] [[Brand Notes#Logo|logo]] [notes](Work/Brand%20Notes.md)\n" +
+			"[[Work/Brand Notes]] [[Other]]\n\n## Notes\n\n[[Brand Notes]]\n",
+		"Work/Brand Notes.md": "[home](../Home.md) [[./Sub/Deep]]\n",
+		"Work/Sub/Deep.md":    "[up](/Work/Brand%20Notes.md)\n",
+		"Other.md":            "",
+	})
+	plan, err := Prepare(root, []Move{
+		{From: "Work/Brand Notes.md", To: "Work/brand_notes.md"},
+		{From: "Work", To: "Studio"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"Home.md": "[[brand_notes]] [[brand_notes#Logo|logo]] [notes](Studio/brand_notes.md)\n" +
+			"[[Studio/brand_notes]] [[Other]]\n\n## Notes\n\n[[Brand Notes]]\n",
+		"Studio/brand_notes.md": "[home](../Home.md) [[./Sub/Deep]]\n",
+		"Studio/Sub/Deep.md":    "[up](/Studio/brand_notes.md)\n",
+	}
+	for f, want := range tests {
+		got, err := plan.Text(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("Text(%s):\ngot  %q\nwant %q", f, got, want)
+		}
+	}
+	var protected []Edit
+	for _, ed := range plan.Edits {
+		if ed.Protected {
+			protected = append(protected, ed)
+		}
+	}
+	want := []Edit{{File: "Home.md", Line: 6, Old: "[[Brand Notes]]", New: "[[brand_notes]]", Protected: true}}
+	if !reflect.DeepEqual(protected, want) {
+		t.Errorf("protected edits = %+v, want %+v", protected, want)
+	}
+	if got := plan.Final("Work/Sub/Deep.md"); got != "Studio/Sub/Deep.md" {
+		t.Errorf("Final = %s", got)
+	}
+	if got := plan.source("Studio/brand_notes.md"); got != "Work/Brand Notes.md" {
+		t.Errorf("source = %s", got)
+	}
+}
+
+func TestPrepareCollisions(t *testing.T) {
+	root := vault(t, map[string]string{
+		"goals.md":         "",
+		"project_goals.md": "",
+		"notes.md":         "",
+		"Draft.md":         "",
+		"a/x.md":           "",
+		"b/x.md":           "",
+	})
+	tests := []struct {
+		name  string
+		moves []Move
+		bad   []Move // nil: no collision
+	}{
+		{
+			name: "two files onto one name, ignoring case",
+			moves: []Move{
+				{From: "goals.md", To: "PROJECT_GOALS.md"},
+				{From: "project_goals.md", To: "PROJECT_GOALS.md"},
+			},
+			bad: []Move{
+				{From: "goals.md", To: "PROJECT_GOALS.md"},
+				{From: "project_goals.md", To: "PROJECT_GOALS.md"},
+			},
+		},
+		{
+			name:  "onto a file that stays",
+			moves: []Move{{From: "Draft.md", To: "Notes.md"}},
+			bad:   []Move{{From: "Draft.md", To: "Notes.md"}},
+		},
+		{
+			name:  "a folder onto another's files",
+			moves: []Move{{From: "a", To: "B"}},
+			bad:   []Move{{From: "a", To: "B"}},
+		},
+		{
+			name:  "a folder into itself",
+			moves: []Move{{From: "a", To: "a/inner"}},
+			bad:   []Move{{From: "a", To: "a/inner"}},
+		},
+		{
+			name:  "case only",
+			moves: []Move{{From: "project_goals.md", To: "PROJECT_GOALS.md"}},
+		},
+		{
+			name: "a file renamed, then its folder",
+			moves: []Move{
+				{From: "a/x.md", To: "a/y.md"},
+				{From: "a", To: "c"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		_, err := Prepare(root, tt.moves)
+		var ce *CollisionError
+		switch {
+		case tt.bad == nil && err != nil:
+			t.Errorf("%s: %v", tt.name, err)
+		case tt.bad != nil && !errors.As(err, &ce):
+			t.Errorf("%s: err = %v, want a collision", tt.name, err)
+		case tt.bad != nil && !reflect.DeepEqual(ce.Moves, tt.bad):
+			t.Errorf("%s: colliding moves = %+v, want %+v", tt.name, ce.Moves, tt.bad)
+		}
+		if err := Check(root, tt.moves); (err != nil) != (tt.bad != nil) {
+			t.Errorf("%s: Check = %v", tt.name, err)
+		}
+	}
+	if _, err := Prepare(root, []Move{{From: "missing.md", To: "x.md"}}); err == nil {
+		t.Error("Prepare accepted a missing source")
+	}
+}
@@ -0,0 +1,302 @@
+// Package requirements parses the Given/When/Then requirement documents in a
+// domain's 06_REQUIREMENTS folder:
+//
+//	## 1.5 Skill: project-management
+//
+//	### 1.5.8 Tasks Use Checkbox Symbols for Status
+//
+//	**Given** a project file has tasks
+//	**When** the status changes
+//	**Then** the checkbox character changes
+//	**And then** the task moves section
+//
+//	Category: Functional
+//	Verification: Change a task status and confirm the checkbox
+//	Source: [update_plan.md](.claude/skills/project-management/workflows/update_plan.md)
+//
+// IDs are [DOCUMENT].[SECTION].[REQUIREMENT]. Check reports IDs that repeat,
+// go backwards, or sit outside their document or section, and requirements
+// missing a Category, Verification or Source line.
+package requirements
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+)
+
+// Folder is the domain folder that holds requirement documents.
+const Folder = "06_REQUIREMENTS"
+
+// Requirement is one "### N.N.N Title" block.
+type Requirement struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// Document is the file the requirement was read from and Line the
+	// 1-based line of its heading.
+	Document string `json:"document"`
+	Line     int    `json:"line"`
+	// Section is the enclosing "## N.N Title" heading text.
+	Section string `json:"section,omitempty"`
+
+	// Given, When and Then hold the clauses in order; "And given" joins
+	// Given and "And" / "And then" join Then. Until holds "Until" clauses.
+	Given []string `json:"given,omitempty"`
+	When  []string `json:"when,omitempty"`
+	Then  []string `json:"then,omitempty"`
+	Until []string `json:"until,omitempty"`
+
+	Category     string `json:"category,omitempty"`
+	Verification string `json:"verification,omitempty"`
+	Source       string `json:"source,omitempty"`
+	// SourcePath is the link target of Source when it is a markdown link.
+	SourcePath string `json:"source_path,omitempty"`
+}
+
+// Kind classifies a Problem.
+type Kind string
+
+const (
+	DuplicateID    Kind = "duplicate-id"
+	OutOfOrder     Kind = "out-of-order"
+	WrongPlace     Kind = "wrong-place"
+	MissingField   Kind = "missing-field"
+	RepeatedField  Kind = "repeated-field"
+	MissingClauses Kind = "missing-clauses"
+)
+
+// Problem is a structural error in a requirement document.
+type Problem struct {
+	Kind     Kind   `json:"kind"`
+	Document string `json:"document"`
+	Line     int    `json:"line"`
+	ID       string `json:"id,omitempty"`
+	Message  string `json:"message"`
+}
+
+var (
+	reqHeading     = regexp.MustCompile(`^(\d+(?:\.\d+)+)\s+(.+)$`)
+	sectionHeading = regexp.MustCompile(`^(\d+\.\d+)\s+(.+)$`)
+	clauseLine     = regexp.MustCompile(`^\*\*(Given|When|Then|Until|And(?: given| when| then)?)\*\*\s*(.*)$`)
+	fieldLine      = regexp.MustCompile(`^(Category|Verification|Source):\s*(.*)$`)
+	mdLink         = regexp.MustCompile(`^\[[^\]]*\]\(([^)\s]+)\)`)
+)
+
+// Parse reads the requirements of one document. doc is the path reported in
+// records and problems; its leading digits ("01_SKILLS.md" → 1) give the
+// ID prefix every requirement must carry. Parse checks each requirement on
+// its own; ID uniqueness and order are left to Check.
+func Parse(doc, text string) ([]Requirement, []Problem) {
+	prefix := docPrefix(doc)
+	lines := markdown.Lines(text)
+	fenced := markdown.InFence(lines)
+
+	var (
+		reqs     []Requirement
+		problems []Problem
+		cur      *Requirement
+		last     string // kind of the previous clause, for "And"
+		section  string
+		secID    string
+		fields   map[string]int
+	)
+	report := func(k Kind, line int, id, format string, args ...any) {
+		problems = append(problems, Problem{k, doc, line, id, fmt.Sprintf(format, args...)})
+	}
+	finish := func() {
+		if cur == nil {
+			return
+		}
+		for _, f := range []string{"Category", "Verification", "Source"} {
+			if fields[f] == 0 {
+				report(MissingField, cur.Line, cur.ID, "%s has no %s: line", cur.ID, f)
+			}
+		}
+		if len(cur.Given) == 0 || len(cur.When) == 0 || len(cur.Then) == 0 {
+			report(MissingClauses, cur.Line, cur.ID, "%s needs Given, When and Then clauses", cur.ID)
+		}
+		reqs = append(reqs, *cur)
+		cur = nil
+	}
+
+	for i, line := range lines {
+		n := i + 1
+		if fenced[i] {
+			continue
+		}
+		if h, ok := markdown.ParseHeading(line); ok && h.Level <= 3 {
+			finish()
+			if h.Level == 2 {
+				section, secID = "", ""
+				if m := sectionHeading.FindStringSubmatch(h.Text); m != nil {
+					section, secID = h.Text, m[1]
+				}
+				continue
+			}
+			m := reqHeading.FindStringSubmatch(h.Text)
+			if h.Level != 3 || m == nil {
+				continue
+			}
+			cur = &Requirement{ID: m[1], Title: strings.TrimSpace(m[2]), Document: doc, Line: n, Section: section}
+			fields = map[string]int{}
+			last = ""
+			switch {
+			case prefix != "" && !strings.HasPrefix(cur.ID+".", prefix+"."):
+				report(WrongPlace, n, cur.ID, "%s does not belong in %s, whose IDs start with %s.", cur.ID, path.Base(doc), prefix)
+			case secID == "":
+				report(WrongPlace, n, cur.ID, "%s is not under a numbered \"## N.N\" section", cur.ID)
+			case !strings.HasPrefix(cur.ID, secID+"."):
+				report(WrongPlace, n, cur.ID, "%s is under section %s", cur.ID, secID)
+			}
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		t := strings.TrimSpace(line)
+		if t == "---" {
+			finish()
+			continue
+		}
+		if m := clauseLine.FindStringSubmatch(t); m != nil {
+			kind := m[1]
+			switch kind {
+			case "And":
+				kind = last
+				if kind == "" {
+					kind = "Then"
+				}
+			case "And given":
+				kind = "Given"
+			case "And when":
+				kind = "When"
+			case "And then":
+				kind = "Then"
+			}
+			last = kind
+			switch kind {
+			case "Given":
+				cur.Given = append(cur.Given, m[2])
+			case "When":
+				cur.When = append(cur.When, m[2])
+			case "Then":
+				cur.Then = append(cur.Then, m[2])
+			case "Until":
+				cur.Until = append(cur.Until, m[2])
+			}
+			continue
+		}
+		if m := fieldLine.FindStringSubmatch(t); m != nil {
+			if fields[m[1]]++; fields[m[1]] > 1 {
+				report(RepeatedField, n, cur.ID, "%s has more than one %s: line", cur.ID, m[1])
+				continue
+			}
+			v := strings.TrimSpace(m[2])
+			switch m[1] {
+			case "Category":
+				cur.Category = v
+			case "Verification":
+				cur.Verification = v
+			case "Source":
+				cur.Source = v
+				if l := mdLink.FindStringSubmatch(v); l != nil {
+					cur.SourcePath = l[1]
+				}
+			}
+		}
+	}
+	finish()
+	return reqs, problems
+}
+
+// Check parses the documents in order and adds the problems that need the
+// whole set: IDs used twice and IDs lower than the one before them. docs
+// maps each name in order to its text.
+func Check(order []string, docs map[string]string) ([]Requirement, []Problem) {
+	var (
+		all      []Requirement
+		problems []Problem
+	)
+	first := map[string]Requirement{}
+	for _, doc := range order {
+		reqs, probs := Parse(doc, docs[doc])
+		var prev []int
+		for _, r := range reqs {
+			key := ids(r.ID)
+			if f, dup := first[r.ID]; dup {
+				probs = append(probs, Problem{DuplicateID, doc, r.Line, r.ID,
+					fmt.Sprintf("%s is already used by %q at %s:%d", r.ID, f.Title, f.Document, f.Line)})
+			} else {
+				first[r.ID] = r
+				if prev != nil && compare(key, prev) <= 0 {
+					probs = append(probs, Problem{OutOfOrder, doc, r.Line, r.ID,
+						fmt.Sprintf("%s follows %s", r.ID, join(prev))})
+				}
+			}
+			if prev == nil || compare(key, prev) > 0 {
+				prev = key
+			}
+			all = append(all, r)
+		}
+		sort.SliceStable(probs, func(i, j int) bool { return probs[i].Line < probs[j].Line })
+		problems = append(problems, probs...)
+	}
+	return all, problems
+}
+
+// Counts returns the number of requirements per category.
+func Counts(reqs []Requirement) map[string]int {
+	out := map[string]int{}
+	for _, r := range reqs {
+		c := r.Category
+		if c == "" {
+			c = "(none)"
+		}
+		out[c]++
+	}
+	return out
+}
+
+func docPrefix(doc string) string {
+	base := path.Base(doc)
+	end := 0
+	for end < len(base) && base[end] >= '0' && base[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return ""
+	}
+	n, _ := strconv.Atoi(base[:end])
+	return strconv.Itoa(n)
+}
+
+func ids(id string) []int {
+	parts := strings.Split(id, ".")
+	out := make([]int, len(parts))
+	for i, p := range parts {
+		out[i], _ = strconv.Atoi(p)
+	}
+	return out
+}
+
+func compare(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return len(a) - len(b)
+}
+
+func join(id []int) string {
+	s := make([]string, len(id))
+	for i, n := range id {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ".")
+}
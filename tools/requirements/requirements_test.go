@@ -0,0 +1,140 @@
+package requirements
+
+import (
+	"reflect"
+	"testing"
+)
+
+const skills = `# Skills
+
+## 1.5 Skill: project-management
+
+### 1.5.8 Tasks Use Checkbox Symbols
+
+**Given** a project file has tasks
+**And given** the file is open
+**When** the status changes
+**Then** the checkbox changes
+**And** the task moves section
+**Until** the task is archived
+
+Category: Functional
+Verification: Change a task status
+Source: [update_plan.md](.claude/skills/pm/update_plan.md)
+
+---
+
+### 1.5.9 Missing Parts
+
+**Given** something
+**Then** something else
+
+Category: Functional
+Category: Again
+
+` + "```" + `
+### 1.5.1 Fenced example
+` + "```" + `
+
+### 2.1.1 Elsewhere
+
+### 1.6.1 Wrong section
+`
+
+func TestParse(t *testing.T) {
+	reqs, problems := Parse("Domains/PAL/06_REQUIREMENTS/01_SKILLS.md", skills)
+	want := Requirement{
+		ID:           "1.5.8",
+		Title:        "Tasks Use Checkbox Symbols",
+		Document:     "Domains/PAL/06_REQUIREMENTS/01_SKILLS.md",
+		Line:         5,
+		Section:      "1.5 Skill: project-management",
+		Given:        []string{"a project file has tasks", "the file is open"},
+		When:         []string{"the status changes"},
+		Then:         []string{"the checkbox changes", "the task moves section"},
+		Until:        []string{"the task is archived"},
+		Category:     "Functional",
+		Verification: "Change a task status",
+		Source:       "[update_plan.md](.claude/skills/pm/update_plan.md)",
+		SourcePath:   ".claude/skills/pm/update_plan.md",
+	}
+	if len(reqs) != 4 || !reflect.DeepEqual(reqs[0], want) {
+		t.Fatalf("Parse = %+v", reqs)
+	}
+	if r := reqs[1]; r.ID != "1.5.9" || r.Category != "Functional" || len(r.When) != 0 {
+		t.Errorf("second requirement = %+v", r)
+	}
+
+	tests := []struct {
+		kind Kind
+		line int
+		id   string
+	}{
+		{RepeatedField, 26, "1.5.9"},
+		{MissingField, 20, "1.5.9"},
+		{MissingField, 20, "1.5.9"},
+		{MissingClauses, 20, "1.5.9"},
+		{WrongPlace, 32, "2.1.1"},
+		{MissingField, 32, "2.1.1"},
+		{MissingField, 32, "2.1.1"},
+		{MissingField, 32, "2.1.1"},
+		{MissingClauses, 32, "2.1.1"},
+		{WrongPlace, 34, "1.6.1"},
+	}
+	var got []Problem
+	for _, p := range problems {
+		if p.ID != "1.6.1" || p.Kind == WrongPlace {
+			got = append(got, p)
+		}
+	}
+	if len(got) != len(tests) {
+		t.Fatalf("Parse gave %d problems, want %d: %+v", len(got), len(tests), got)
+	}
+	for i, tt := range tests {
+		if p := got[i]; p.Kind != tt.kind || p.Line != tt.line || p.ID != tt.id {
+			t.Errorf("problem %d = %+v, want %s at line %d for %s", i, p, tt.kind, tt.line, tt.id)
+		}
+	}
+	if got[0].Message != "1.5.9 has more than one Category: line" ||
+		got[4].Message != "2.1.1 does not belong in 01_SKILLS.md, whose IDs start with 1." ||
+		got[9].Message != "1.6.1 is under section 1.5" {
+		t.Errorf("messages: %q, %q, %q", got[0].Message, got[4].Message, got[9].Message)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	req := func(id string) string {
+		return "### " + id + " R\n\n**Given** a\n**When** b\n**Then** c\n\nCategory: Functional\nVerification: v\nSource: s\n\n"
+	}
+	docs := map[string]string{
+		"01_A.md": "## 1.1 A\n\n" + req("1.1.1") + req("1.1.3") + req("1.1.2") + req("1.1.1"),
+		"02_B.md": "## 2.1 B\n\n" + req("2.1.1"),
+	}
+	reqs, problems := Check([]string{"01_A.md", "02_B.md"}, docs)
+	if len(reqs) != 5 {
+		t.Errorf("Check gave %d requirements, want 5", len(reqs))
+	}
+	want := []Problem{
+		{OutOfOrder, "01_A.md", 23, "1.1.2", "1.1.2 follows 1.1.3"},
+		{DuplicateID, "01_A.md", 33, "1.1.1", `1.1.1 is already used by "R" at 01_A.md:3`},
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("Check problems = %+v, want %+v", problems, want)
+	}
+	if c := Counts(append(reqs, Requirement{})); c["Functional"] != 5 || c["(none)"] != 1 {
+		t.Errorf("Counts = %v", c)
+	}
+}
+
+func TestDocPrefix(t *testing.T) {
+	tests := map[string]string{
+		"06_REQUIREMENTS/01_SKILLS.md": "1",
+		"12_AGENTS.md":                 "12",
+		"README.md":                    "",
+	}
+	for in, want := range tests {
+		if got := docPrefix(in); got != want {
+			t.Errorf("docPrefix(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package schema
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/frontmatter"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+)
+
+// Ext is the file name suffix of a schema file.
+const Ext = ".schema.json"
+
+// Builtin names the source of the schemas compiled into pal.
+const Builtin = "built-in"
+
+//go:embed builtin/*.schema.json
+var builtin embed.FS
+
+// Entry is one schema of a registry.
+type Entry struct {
+	// Name is the file name without .schema.json, e.g. "project".
+	Name string
+	// Source is the vault-relative schema file, or Builtin.
+	Source string
+	// Paths and Exclude are the schema's x-pal-paths and x-pal-exclude
+	// with layout keys expanded.
+	Paths   []string
+	Exclude []string
+	Schema  *Schema
+	// Data is the schema file as read.
+	Data []byte
+}
+
+// Registry holds every schema of a vault.
+type Registry struct {
+	Entries []*Entry
+}
+
+// Defaults returns the built-in schema files by name, for writing them
+// out where the user can edit them.
+func Defaults() map[string][]byte {
+	out := map[string][]byte{}
+	names, _ := fs.Glob(builtin, "builtin/*"+Ext)
+	for _, n := range names {
+		data, _ := builtin.ReadFile(n)
+		out[strings.TrimSuffix(path.Base(n), Ext)] = data
+	}
+	return out
+}
+
+// Load builds the registry of the vault at root: the built-in schemas,
+// then every *.schema.json in the layout's schemas folder. A file with the
+// name of a built-in schema replaces it, so users can extend the five
+// standard schemas as well as add their own.
+func Load(root string, l *layout.Layout) (*Registry, error) {
+	byName := map[string]*Entry{}
+	for name, data := range Defaults() {
+		e, err := entry(name, Builtin, data, l)
+		if err != nil {
+			return nil, err
+		}
+		byName[name] = e
+	}
+	dir := l.Path(layout.Schemas)
+	matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(dir), "*"+Ext))
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range matches {
+		data, err := os.ReadFile(m)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(m), Ext)
+		e, err := entry(name, path.Join(dir, filepath.Base(m)), data, l)
+		if err != nil {
+			return nil, err
+		}
+		byName[name] = e
+	}
+	r := &Registry{}
+	for _, e := range byName {
+		r.Entries = append(r.Entries, e)
+	}
+	sort.Slice(r.Entries, func(i, j int) bool { return r.Entries[i].Name < r.Entries[j].Name })
+	return r, nil
+}
+
+var layoutKey = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+func entry(name, source string, data []byte, l *layout.Layout) (*Entry, error) {
+	s, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	e := &Entry{Name: name, Source: source, Schema: s, Data: data}
+	if e.Paths, err = expand(s.Paths, l); err == nil {
+		e.Exclude, err = expand(s.Exclude, l)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	if len(e.Paths) == 0 {
+		return nil, fmt.Errorf("%s: no x-pal-paths; the schema would apply to nothing", source)
+	}
+	return e, nil
+}
+
+// expand replaces the {key} layout locations in globs.
+func expand(globs []string, l *layout.Layout) ([]string, error) {
+	var out []string
+	for _, g := range globs {
+		var bad string
+		g = layoutKey.ReplaceAllStringFunc(g, func(k string) string {
+			key := k[1 : len(k)-1]
+			if _, ok := l.Locations[key]; !ok {
+				bad = key
+				return k
+			}
+			return l.Path(key)
+		})
+		if bad != "" {
+			return nil, fmt.Errorf("unknown layout location %q in %q", bad, g)
+		}
+		out = append(out, g)
+	}
+	return out, nil
+}
+
+// For returns the entries whose paths match the vault-relative p.
+func (r *Registry) For(p string) []*Entry {
+	var out []*Entry
+	for _, e := range r.Entries {
+		if matchAny(e.Paths, p) && !matchAny(e.Exclude, p) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func matchAny(globs []string, p string) bool {
+	for _, g := range globs {
+		if Match(g, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether the vault-relative p matches glob, compared
+// case-insensitively. "*", "?" and "[...]" work within one path element
+// as in path.Match; a "**" element matches any number of elements.
+func Match(glob, p string) bool {
+	return matchParts(strings.Split(strings.ToLower(glob), "/"), strings.Split(strings.ToLower(p), "/"))
+}
+
+func matchParts(glob, parts []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchParts(glob[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(glob[0], parts[0]); !ok {
+			return false
+		}
+		glob, parts = glob[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// Issue is one problem found in a file.
+type Issue struct {
+	Path   string `json:"path"`
+	Schema string `json:"schema"`
+	Problem
+}
+
+// Check validates the vault-relative file rel under root against every
+// schema that matches it. Markdown files are checked by their frontmatter,
+// and a note without any fails every required field; other files, such as
+// .current-session, are YAML documents and are skipped when empty.
+func (r *Registry) Check(root, rel string) ([]Issue, error) {
+	entries := r.For(rel)
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	doc := data
+	if strings.EqualFold(path.Ext(rel), ".md") {
+		doc, _, _ = frontmatter.Split(data)
+	} else if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	var out []Issue
+	for _, e := range entries {
+		probs, err := e.Schema.ValidateYAML(doc)
+		if err != nil {
+			out = append(out, Issue{Path: rel, Schema: e.Name, Problem: Problem{Message: "invalid YAML: " + err.Error()}})
+			continue
+		}
+		for _, p := range probs {
+			out = append(out, Issue{Path: rel, Schema: e.Name, Problem: p})
+		}
+	}
+	return out, nil
+}
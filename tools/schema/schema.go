@@ -0,0 +1,358 @@
+// Package schema validates note frontmatter against JSON Schema files, so
+// the fields a note must carry live in data the user can edit instead of
+// in hook code (requirements 4.1.16 to 4.1.22).
+//
+// A schema is a JSON Schema document with two extensions: "x-pal-paths"
+// lists the vault-relative globs of the files it applies to, and
+// "x-pal-exclude" the globs of files it skips, such as a folder's
+// README.md. Globs match case-insensitively, "*" stays within one folder,
+// "**" spans any number, and "{key}" stands for a pal.layout.yaml
+// location:
+//
+//	"x-pal-paths": ["{domains}/*/01_PROJECTS/PROJECT_*.md"]
+//
+// Only the keywords frontmatter needs are implemented: type, enum, const,
+// required, properties, additionalProperties, items, minItems, maxItems,
+// minLength, maxLength, pattern and format (date, date-time). Other
+// keywords, such as title, description or default, are accepted and
+// ignored.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema is one parsed schema document, or a subschema of one.
+type Schema struct {
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Paths       []string `json:"x-pal-paths,omitempty"`
+	Exclude     []string `json:"x-pal-exclude,omitempty"`
+
+	Type                 types              `json:"type,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Const                *any               `json:"const,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *additional        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Format               string             `json:"format,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// types is the "type" keyword, a name or a list of names.
+type types []string
+
+func (t *types) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = types{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings")
+	}
+	*t = many
+	return nil
+}
+
+// additional is the "additionalProperties" keyword, false or a schema.
+type additional struct {
+	Forbid bool
+	Schema *Schema
+}
+
+func (a *additional) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		a.Forbid = !b
+		return nil
+	}
+	return json.Unmarshal(data, &a.Schema)
+}
+
+// Parse reads a schema document.
+func Parse(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *Schema) compile() error {
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	for _, t := range s.Type {
+		switch t {
+		case "object", "array", "string", "number", "integer", "boolean", "null":
+		default:
+			return fmt.Errorf("unknown type %q", t)
+		}
+	}
+	subs := []*Schema{s.Items}
+	if s.AdditionalProperties != nil {
+		subs = append(subs, s.AdditionalProperties.Schema)
+	}
+	for _, p := range s.Properties {
+		subs = append(subs, p)
+	}
+	for _, sub := range subs {
+		if sub == nil {
+			continue
+		}
+		if err := sub.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Problem is one way a value breaks its schema. Field is the dotted path
+// to the value, e.g. "loaded_paths[1]"; empty for the document itself.
+type Problem struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	if p.Field == "" {
+		return p.Message
+	}
+	return p.Field + ": " + p.Message
+}
+
+// ValidateYAML decodes the YAML document in data and validates it.
+func (s *Schema) ValidateYAML(data []byte) ([]Problem, error) {
+	var n yaml.Node
+	if err := yaml.Unmarshal(data, &n); err != nil {
+		return nil, err
+	}
+	var v any = map[string]any{}
+	if len(n.Content) > 0 {
+		v = value(n.Content[0])
+	}
+	return s.Validate(v), nil
+}
+
+// Validate checks v, a value as decoded from JSON or by ValidateYAML.
+func (s *Schema) Validate(v any) []Problem {
+	var out []Problem
+	s.check("", v, &out)
+	return out
+}
+
+func (s *Schema) check(field string, v any, out *[]Problem) {
+	add := func(format string, args ...any) {
+		*out = append(*out, Problem{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	if len(s.Type) > 0 && !s.Type.match(v) {
+		add("is %s, want %s", typeOf(v), strings.Join(s.Type, " or "))
+		return
+	}
+	if s.Const != nil && !equal(v, *s.Const) {
+		add("must be %s", show(*s.Const))
+	}
+	if len(s.Enum) > 0 {
+		ok := false
+		for _, e := range s.Enum {
+			ok = ok || equal(v, e)
+		}
+		if !ok {
+			shown := make([]string, len(s.Enum))
+			for i, e := range s.Enum {
+				shown[i] = show(e)
+			}
+			add("%s is not one of %s", show(v), strings.Join(shown, ", "))
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		for _, k := range s.Required {
+			if _, ok := v[k]; !ok {
+				*out = append(*out, Problem{Field: join(field, k), Message: "required field is missing"})
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if p, ok := s.Properties[k]; ok {
+				p.check(join(field, k), v[k], out)
+				continue
+			}
+			if a := s.AdditionalProperties; a != nil {
+				switch {
+				case a.Forbid:
+					*out = append(*out, Problem{Field: join(field, k), Message: "unknown field"})
+				case a.Schema != nil:
+					a.Schema.check(join(field, k), v[k], out)
+				}
+			}
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			add("has %d item(s), want at least %d", len(v), *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			add("has %d item(s), want at most %d", len(v), *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, it := range v {
+				s.Items.check(fmt.Sprintf("%s[%d]", field, i), it, out)
+			}
+		}
+	case string:
+		n := len([]rune(v))
+		if s.MinLength != nil && n < *s.MinLength {
+			if n == 0 {
+				add("must not be empty")
+			} else {
+				add("is %d character(s), want at least %d", n, *s.MinLength)
+			}
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			add("is %d character(s), want at most %d", n, *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			add("%q does not match %s", v, s.Pattern)
+		}
+		if msg, ok := checkFormat(s.Format, v); !ok {
+			add("%q %s", v, msg)
+		}
+	}
+}
+
+func (t types) match(v any) bool {
+	got := typeOf(v)
+	for _, want := range t {
+		if want == got || want == "number" && got == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+func typeOf(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func checkFormat(format, v string) (string, bool) {
+	switch format {
+	case "date":
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			return "is not a date (YYYY-MM-DD)", false
+		}
+	case "date-time":
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04"} {
+			if _, err := time.Parse(layout, v); err == nil {
+				return "", true
+			}
+		}
+		return "is not a date and time (RFC 3339)", false
+	}
+	return "", true
+}
+
+func equal(a, b any) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
+
+func show(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func join(field, key string) string {
+	if field == "" {
+		return key
+	}
+	return field + "." + key
+}
+
+// value converts a YAML node to the values JSON decoding produces.
+// Timestamps stay strings as written, so "created: 2026-02-13" is the
+// string a "date" format expects.
+func value(n *yaml.Node) any {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) > 0 {
+			return value(n.Content[0])
+		}
+		return nil
+	case yaml.AliasNode:
+		return value(n.Alias)
+	case yaml.MappingNode:
+		m := map[string]any{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			m[n.Content[i].Value] = value(n.Content[i+1])
+		}
+		return m
+	case yaml.SequenceNode:
+		s := make([]any, 0, len(n.Content))
+		for _, c := range n.Content {
+			s = append(s, value(c))
+		}
+		return s
+	}
+	switch n.ShortTag() {
+	case "!!null":
+		return nil
+	case "!!bool":
+		var b bool
+		if n.Decode(&b) == nil {
+			return b
+		}
+	case "!!int", "!!float":
+		var f float64
+		if n.Decode(&f) == nil {
+			return f
+		}
+	}
+	return n.Value
+}
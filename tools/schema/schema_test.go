@@ -0,0 +1,195 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+)
+
+const session = `{
+  "x-pal-paths": ["x"],
+  "type": "object",
+  "required": ["session_id", "agent"],
+  "additionalProperties": false,
+  "properties": {
+    "session_id": { "type": "string", "pattern": "^s-[0-9]+$" },
+    "agent": { "enum": ["pal-master", "helper"] },
+    "started": { "type": "string", "format": "date-time" },
+    "created": { "type": "string", "format": "date" },
+    "version": { "const": 1 },
+    "count": { "type": "integer" },
+    "ratio": { "type": ["number", "null"] },
+    "name": { "type": "string", "minLength": 1, "maxLength": 5 },
+    "loaded_paths": { "type": "array", "minItems": 1, "maxItems": 2, "items": { "type": "string", "minLength": 2 } },
+    "meta": { "type": "object", "additionalProperties": { "type": "boolean" } }
+  }
+}`
+
+func TestValidateYAML(t *testing.T) {
+	s, err := Parse([]byte(session))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, doc string
+		want      []string
+	}{
+		{
+			name: "valid",
+			doc: "session_id: s-1\nagent: helper\nstarted: 2026-10-18 09:30\ncreated: 2026-10-18\nversion: 1\ncount: 3\n" +
+				"ratio: 0.5\nname: abc\nloaded_paths: [ab, cd]\nmeta: {a: true}\n",
+		},
+		{name: "null and whole numbers", doc: "session_id: s-1\nagent: helper\nratio: ~\ncount: 4.0\n"},
+		{name: "empty document", doc: "", want: []string{"session_id: required field is missing", "agent: required field is missing"}},
+		{
+			name: "every keyword",
+			doc: "session_id: x-1\nagent: other\nstarted: yesterday\ncreated: 18/10/2026\nversion: 2\ncount: 1.5\n" +
+				"ratio: high\nname: ''\nloaded_paths: [a, b, c]\nmeta: {a: yes please}\nextra: 1\n",
+			want: []string{
+				`agent: "other" is not one of "pal-master", "helper"`,
+				"count: is number, want integer",
+				`created: "18/10/2026" is not a date (YYYY-MM-DD)`,
+				"extra: unknown field",
+				"loaded_paths: has 3 item(s), want at most 2",
+				"loaded_paths[0]: is 1 character(s), want at least 2",
+				"loaded_paths[1]: is 1 character(s), want at least 2",
+				"loaded_paths[2]: is 1 character(s), want at least 2",
+				"meta.a: is string, want boolean",
+				"name: must not be empty",
+				"ratio: is string, want number or null",
+				`session_id: "x-1" does not match ^s-[0-9]+$`,
+				`started: "yesterday" is not a date and time (RFC 3339)`,
+				"version: must be 1",
+			},
+		},
+		{name: "not an object", doc: "- a\n", want: []string{"is array, want object"}},
+	}
+	for _, tt := range tests {
+		probs, err := s.ValidateYAML([]byte(tt.doc))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(probs) != len(tt.want) {
+			t.Errorf("%s: %d problems, want %d: %v", tt.name, len(probs), len(tt.want), probs)
+			continue
+		}
+		for i, w := range tt.want {
+			if got := probs[i].String(); got != w {
+				t.Errorf("%s: problem %d = %q, want %q", tt.name, i, got, w)
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "text"}`: `unknown type "text"`,
+		`{"type": 3}`:      "type must be a string or a list of strings",
+		`{"properties": {"a": {"pattern": "(" }}}`: "pattern \"(\": error parsing regexp: missing closing ): `(`",
+		`{"items": {"type": ["string", "float"]}}`: `unknown type "float"`,
+	}
+	for in, want := range tests {
+		if _, err := Parse([]byte(in)); err == nil || err.Error() != want {
+			t.Errorf("Parse(%s) = %v, want %s", in, err, want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		glob, p string
+		want    bool
+	}{
+		{"Domains/*/01_PROJECTS/PROJECT_*.md", "domains/Work/01_projects/project_api.md", true},
+		{"Domains/*/01_PROJECTS/PROJECT_*.md", "Domains/Work/01_PROJECTS/sub/PROJECT_API.md", false},
+		{"**/README.md", "README.md", true},
+		{"**/README.md", "a/b/c/readme.md", true},
+		{"inbox/**", "inbox", true},
+		{"inbox/**/*.md", "inbox/Notes/x/a.md", true},
+		{"inbox/*.md", "inbox/Notes/a.md", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.glob, tt.p); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.glob, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, data string) {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dir := ".claude/schemas/frontmatter/"
+	write(dir+"project.schema.json", `{"x-pal-paths": ["{domains}/*/01_PROJECTS/*.md"], "x-pal-exclude": ["**/README.md"], "required": ["name", "owner"]}`)
+	write(dir+"meeting.schema.json", `{"x-pal-paths": ["{domains}/*/04_SESSIONS/*.md"], "properties": {"date": {"format": "date"}}}`)
+	write("Domains/Work/01_PROJECTS/PROJECT_API.md", "---\nname: API\n---\n")
+	write("Domains/Work/01_PROJECTS/README.md", "")
+	write("Domains/Work/04_SESSIONS/call.md", "---\ndate: [\n---\n")
+
+	r, err := Load(root, layout.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources := map[string]string{}
+	for _, e := range r.Entries {
+		sources[e.Name] = e.Source
+	}
+	if sources["project"] != dir+"project.schema.json" || sources["meeting"] != dir+"meeting.schema.json" || sources["domain-index"] != Builtin {
+		t.Errorf("sources = %v", sources)
+	}
+
+	tests := []struct {
+		rel  string
+		want []string // "schema: problem"
+	}{
+		{rel: "Domains/Work/01_PROJECTS/PROJECT_API.md", want: []string{"project: owner: required field is missing"}},
+		{rel: "Domains/Work/01_PROJECTS/README.md"},
+		{rel: "Domains/Work/01_PROJECTS/PROJECT_GONE.md"},
+		{rel: "Domains/Work/04_SESSIONS/call.md", want: []string{"meeting: invalid YAML: yaml: line 1: did not find expected node content"}},
+		{rel: "inbox/unmatched.md"},
+	}
+	for _, tt := range tests {
+		issues, err := r.Check(root, tt.rel)
+		if err != nil {
+			t.Errorf("%s: %v", tt.rel, err)
+			continue
+		}
+		if len(issues) != len(tt.want) {
+			t.Errorf("%s: %v, want %v", tt.rel, issues, tt.want)
+			continue
+		}
+		for i, w := range tt.want {
+			if got := issues[i].Schema + ": " + issues[i].Problem.String(); got != w || issues[i].Path != tt.rel {
+				t.Errorf("%s: issue %d = %q, want %q", tt.rel, i, got, w)
+			}
+		}
+	}
+
+	write(dir+"bad.schema.json", `{"x-pal-paths": ["{nowhere}/*.md"]}`)
+	if _, err := Load(root, layout.Default()); err == nil || err.Error() != dir+`bad.schema.json: unknown layout location "nowhere" in "{nowhere}/*.md"` {
+		t.Errorf("Load with an unknown location: %v", err)
+	}
+	write(dir+"bad.schema.json", `{"type": "object"}`)
+	if _, err := Load(root, layout.Default()); err == nil || err.Error() != dir+"bad.schema.json: no x-pal-paths; the schema would apply to nothing" {
+		t.Errorf("Load without paths: %v", err)
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	d := Defaults()
+	for _, name := range []string{"current-session", "domain-index", "domain-page", "inbox-note", "project"} {
+		if _, err := entry(name, Builtin, d[name], layout.Default()); err != nil {
+			t.Errorf("built-in %s: %v", name, err)
+		}
+	}
+}
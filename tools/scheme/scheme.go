@@ -0,0 +1,163 @@
+// Package scheme tells which folder scheme a domain was created with and
+// plans its migration to the current one. Two schemes exist:
+//
+//	V1 (requirement 0.2.1)       Current (requirement 1.2.1)
+//	02_SESSIONS/                 04_SESSIONS/
+//	03_ASSETS/                   02_PAGES/
+//	04_OUTPUTS/                  03_OUTPUT/
+//
+// 00_CONTEXT, 01_PROJECTS and 05_ARCHIVE are the same in both. A domain
+// with any V1 folder is V1, even if some current folders exist beside it;
+// migration then merges the V1 folder's entries into the current one.
+package scheme
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/changeset"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/rename"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+// Version identifies a domain folder scheme.
+type Version int
+
+const (
+	Current Version = 2
+	V1      Version = 1
+)
+
+func (v Version) String() string {
+	if v == V1 {
+		return "v1"
+	}
+	return "current"
+}
+
+// Renames maps each V1 folder to its current name, in numbered order.
+var Renames = []rename.Move{
+	{From: "02_SESSIONS", To: vault.SessionsDir},
+	{From: "03_ASSETS", To: vault.PagesDir},
+	{From: "04_OUTPUTS", To: vault.OutputDir},
+}
+
+// Detect returns the scheme of the domain folder dir (absolute) and the V1
+// folders found in it.
+func Detect(dir string) (Version, []string, error) {
+	var old []string
+	for _, r := range Renames {
+		info, err := os.Stat(filepath.Join(dir, r.From))
+		if err == nil && info.IsDir() {
+			old = append(old, r.From)
+		} else if err != nil && !os.IsNotExist(err) {
+			return 0, nil, err
+		}
+	}
+	if len(old) > 0 {
+		return V1, old, nil
+	}
+	return Current, nil, nil
+}
+
+// Migration is the work needed to bring one domain to the current scheme.
+type Migration struct {
+	// Domain is the vault-relative domain folder.
+	Domain string
+	From   Version
+	// Moves are vault-relative. A V1 folder whose current name is free
+	// moves as a whole; otherwise each of its entries moves on its own.
+	Moves []rename.Move
+	// Emptied are the V1 folders that are left behind, holding at most
+	// placeholder files, once the entry moves have run.
+	Emptied []string
+}
+
+// placeholders are files that only keep an empty folder in git. They are
+// dropped rather than merged when both folders have one.
+var placeholders = map[string]bool{".gitkeep": true, ".keep": true}
+
+// Plan works out the moves for the domain at the vault-relative path
+// domain. It fails when a V1 folder and its current counterpart hold an
+// entry with the same name, which would need a person to merge.
+func Plan(root, domain string) (*Migration, error) {
+	dir := filepath.Join(root, filepath.FromSlash(domain))
+	ver, _, err := Detect(dir)
+	if err != nil {
+		return nil, err
+	}
+	m := &Migration{Domain: domain, From: ver}
+	if ver == Current {
+		return m, nil
+	}
+	for _, r := range Renames {
+		from := domain + "/" + r.From
+		to := domain + "/" + r.To
+		src, err := os.ReadDir(filepath.Join(dir, r.From))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		dst, err := os.ReadDir(filepath.Join(dir, r.To))
+		if os.IsNotExist(err) {
+			m.Moves = append(m.Moves, rename.Move{From: from, To: to})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		taken := map[string]bool{}
+		for _, e := range dst {
+			taken[strings.ToLower(e.Name())] = true
+		}
+		var clash []string
+		for _, e := range src {
+			switch {
+			case placeholders[e.Name()] && taken[strings.ToLower(e.Name())]:
+				// Dropped with the emptied folder.
+			case taken[strings.ToLower(e.Name())]:
+				clash = append(clash, e.Name())
+			default:
+				m.Moves = append(m.Moves, rename.Move{From: from + "/" + e.Name(), To: to + "/" + e.Name()})
+			}
+		}
+		if len(clash) > 0 {
+			sort.Strings(clash)
+			return nil, fmt.Errorf("%s: %s and %s both contain %s; merge them by hand first", domain, r.From, r.To, strings.Join(clash, ", "))
+		}
+		m.Emptied = append(m.Emptied, from)
+	}
+	return m, nil
+}
+
+// RemoveEmptied plans, in set, deleting the V1 folders Plan leaves empty
+// once the moves have run, with the placeholder files that did not move.
+// Apply fails on a folder that holds anything else by then.
+func (m *Migration) RemoveEmptied(root string, set *changeset.Set) error {
+	moved := map[string]bool{}
+	for _, mv := range m.Moves {
+		moved[mv.From] = true
+	}
+	for _, rel := range m.Emptied {
+		entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if p := rel + "/" + e.Name(); placeholders[e.Name()] && !moved[p] {
+				if err := set.Delete(p, "placeholder of an emptied v1 folder"); err != nil {
+					return err
+				}
+			}
+		}
+		if err := set.DeleteDir(rel, "emptied v1 folder"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package scheme
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/changeset"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/rename"
+)
+
+// tree writes files, keyed by vault-relative path, into a fresh vault root.
+func tree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for p, data := range files {
+		abs := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(abs, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		from    Version
+		moves   []rename.Move
+		emptied []string
+		err     string
+	}{
+		{
+			name:  "current",
+			files: map[string]string{"D/04_SESSIONS/a.md": "", "D/02_PAGES/b.md": ""},
+			from:  Current,
+		},
+		{
+			name:  "v1 folders move whole",
+			files: map[string]string{"D/02_SESSIONS/a.md": "", "D/04_OUTPUTS/b.md": "", "D/01_PROJECTS/c.md": ""},
+			from:  V1,
+			moves: []rename.Move{{From: "D/02_SESSIONS", To: "D/04_SESSIONS"}, {From: "D/04_OUTPUTS", To: "D/03_OUTPUT"}},
+		},
+		{
+			name: "v1 entries merge into an existing folder",
+			files: map[string]string{
+				"D/03_ASSETS/a.md": "", "D/03_ASSETS/img/x.png": "", "D/03_ASSETS/.keep": "",
+				"D/02_PAGES/b.md": "", "D/02_PAGES/.keep": "",
+			},
+			from: V1,
+			moves: []rename.Move{
+				{From: "D/03_ASSETS/a.md", To: "D/02_PAGES/a.md"},
+				{From: "D/03_ASSETS/img", To: "D/02_PAGES/img"},
+			},
+			emptied: []string{"D/03_ASSETS"},
+		},
+		{
+			name:  "clashing entries",
+			files: map[string]string{"D/03_ASSETS/A.md": "", "D/03_ASSETS/b.md": "", "D/02_PAGES/a.md": ""},
+			err:   "D: 03_ASSETS and 02_PAGES both contain A.md; merge them by hand first",
+		},
+	}
+	for _, tt := range tests {
+		m, err := Plan(tree(t, tt.files), "D")
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: err = %v, want %s", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if m.From != tt.from || !reflect.DeepEqual(m.Moves, tt.moves) || !reflect.DeepEqual(m.Emptied, tt.emptied) {
+			t.Errorf("%s: Plan = %+v", tt.name, m)
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	root := tree(t, map[string]string{
+		"Domains/D/02_SESSIONS/2026-01-01-call.md": "See [[guide]].\n",
+		"Domains/D/03_ASSETS/guide.md":             "[call](../02_SESSIONS/2026-01-01-call.md)\n",
+		"Domains/D/03_ASSETS/.gitkeep":             "",
+		"Domains/D/02_PAGES/.gitkeep":              "",
+		"Domains/D/02_PAGES/page.md":               "",
+	})
+	m, err := Plan(root, "Domains/D")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := rename.Prepare(root, m.Moves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := changeset.New(root, "Migrate Domains/D")
+	if err := plan.AddTo(set, "v1 folder scheme"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RemoveEmptied(root, set); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Apply(); err != nil {
+		t.Fatal(err)
+	}
+
+	if ver, old, err := Detect(filepath.Join(root, "Domains", "D")); ver != Current || old != nil || err != nil {
+		t.Errorf("Detect after migration = %s, %v, %v", ver, old, err)
+	}
+	for _, p := range []string{"02_SESSIONS", "03_ASSETS"} {
+		if _, err := os.Stat(filepath.Join(root, "Domains", "D", p)); !os.IsNotExist(err) {
+			t.Errorf("%s still exists: %v", p, err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(root, "Domains", "D", "02_PAGES", "guide.md"))
+	if err != nil || string(data) != "[call](../04_SESSIONS/2026-01-01-call.md)\n" {
+		t.Errorf("guide.md = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,236 @@
+package tasks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+// StaleDays is how long a project may go untouched before the dashboard
+// lists it as stale.
+const StaleDays = 14
+
+// projectStatuses are the project statuses requirement 1.5.3 groups by,
+// in order. Other statuses follow alphabetically, then projects with none.
+var projectStatuses = []string{"planning", "in-progress", "review", "completed"}
+
+// closedStatuses are the project statuses that are never stale.
+var closedStatuses = []string{"completed", "done", "archived", "cancelled"}
+
+// Touched is when a project file last changed.
+type Touched struct {
+	Time time.Time
+	// Source is "git" for the date of the last commit touching the file,
+	// "mtime" for its modification time.
+	Source string
+}
+
+// LastTouched returns when each project file last changed, by path: the
+// date of the last commit touching it, or its modification time when git
+// is not available, does not track the file or the file has uncommitted
+// changes.
+func LastTouched(v *vault.Vault, projects []*Project) (map[string]Touched, error) {
+	out := map[string]Touched{}
+	useGit := exec.Command("git", "-C", v.Root, "rev-parse", "--is-inside-work-tree").Run() == nil
+	for _, p := range projects {
+		if useGit {
+			if t, ok := gitTouched(v.Root, p.Path); ok {
+				out[p.Path] = Touched{t, "git"}
+				continue
+			}
+		}
+		info, err := os.Stat(v.Abs(p.Path))
+		if err != nil {
+			return nil, err
+		}
+		out[p.Path] = Touched{info.ModTime(), "mtime"}
+	}
+	return out, nil
+}
+
+// gitTouched returns the commit date of the last commit touching the
+// vault-relative rel. ok is false when the file is untracked or has
+// uncommitted changes.
+func gitTouched(root, rel string) (time.Time, bool) {
+	status, err := exec.Command("git", "-C", root, "status", "--porcelain", "--", rel).Output()
+	if err != nil || len(strings.TrimSpace(string(status))) > 0 {
+		return time.Time{}, false
+	}
+	date, err := exec.Command("git", "-C", root, "log", "-1", "--format=%cI", "--", rel).Output()
+	if err != nil {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(date)))
+	return t, err == nil
+}
+
+// Progress is the task tally of one project.
+type Progress struct {
+	Total, Done, Blocked, NotDoing int
+}
+
+// ProjectProgress counts p's tasks.
+func ProjectProgress(p *Project) Progress {
+	c := Counts(p.Tasks)
+	return Progress{Total: len(p.Tasks), Done: c[Done], Blocked: c[Blocked], NotDoing: c[NotDoing]}
+}
+
+// Ratio is the share of the tasks done, leaving out those marked not
+// doing; ok is false when that leaves none.
+func (g Progress) Ratio() (r float64, ok bool) {
+	n := g.Total - g.NotDoing
+	if n <= 0 {
+		return 0, false
+	}
+	return float64(g.Done) / float64(n), true
+}
+
+// Dashboard renders DASHBOARD.md (requirement 1.5.3): a summary, the
+// projects untouched for more than staleDays, then every project grouped
+// by its frontmatter status, with its completion ratio, blocked tasks and
+// last-touched date. It is plain markdown, so it reads the same in
+// Obsidian with or without plugins.
+func Dashboard(projects []*Project, touched map[string]Touched, now time.Time, staleDays int) []byte {
+	var b strings.Builder
+	b.WriteString("---\ntype: dashboard\ngenerated_by: pal dashboard\n---\n\n# Project Dashboard\n\n")
+	fmt.Fprintf(&b, "> [!NOTE]\n> Generated by `pal dashboard` on %s from every `PROJECT_*.md`. Last touched is the date of the file's last commit, or its modification time while it has uncommitted changes.\n\n", now.Format("2006-01-02"))
+
+	var total Progress
+	var stale []*Project
+	for _, p := range projects {
+		g := ProjectProgress(p)
+		total.Total += g.Total
+		total.Done += g.Done
+		total.Blocked += g.Blocked
+		total.NotDoing += g.NotDoing
+		if t, ok := touched[p.Path]; ok && !closed(p.Status) && daysSince(t.Time, now) > staleDays {
+			stale = append(stale, p)
+		}
+	}
+
+	b.WriteString("## Summary\n\n")
+	if len(projects) == 0 {
+		b.WriteString("No projects found.\n")
+		return []byte(b.String())
+	}
+	fmt.Fprintf(&b, "- **Projects:** %d, %d stale\n", len(projects), len(stale))
+	fmt.Fprintf(&b, "- **Tasks done:** %d of %d (%s)\n", total.Done, total.Total-total.NotDoing, percent(total))
+	fmt.Fprintf(&b, "- **Blocked tasks:** %d\n", total.Blocked)
+
+	fmt.Fprintf(&b, "\n## Stale (>%d days)\n\n", staleDays)
+	if len(stale) == 0 {
+		b.WriteString("_None._\n")
+	} else {
+		sort.SliceStable(stale, func(i, j int) bool {
+			return touched[stale[i].Path].Time.Before(touched[stale[j].Path].Time)
+		})
+		b.WriteString("| Project | Domain | Status | Last touched | Days |\n|---------|--------|--------|--------------|---:|\n")
+		for _, p := range stale {
+			t := touched[p.Path]
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %d |\n", projectLink(p), p.Domain, statusTitle(p.Status), touchedCell(t), daysSince(t.Time, now))
+		}
+	}
+
+	groups := map[string][]*Project{}
+	for _, p := range projects {
+		k := statusKey(p.Status)
+		groups[k] = append(groups[k], p)
+	}
+	for _, k := range statusOrder(groups) {
+		fmt.Fprintf(&b, "\n## %s (%d)\n\n", statusTitle(k), len(groups[k]))
+		b.WriteString("| Project | Domain | Progress | Done | Blocked | Last touched |\n|---------|--------|----------|---:|---:|--------------|\n")
+		for _, p := range groups[k] {
+			g := ProjectProgress(p)
+			last := "—"
+			if t, ok := touched[p.Path]; ok {
+				last = touchedCell(t)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %d/%d | %d | %s |\n", projectLink(p), p.Domain, bar(g), g.Done, g.Total-g.NotDoing, g.Blocked, last)
+		}
+	}
+	return []byte(b.String())
+}
+
+// statusKey normalises a project status: "In Progress", "in_progress" and
+// "in-progress" are one group.
+func statusKey(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return r == ' ' || r == '_' || r == '-' }), "-")
+}
+
+// statusTitle is the heading of a status group: "in-progress" gives
+// "In Progress".
+func statusTitle(s string) string {
+	words := strings.Split(statusKey(s), "-")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	if t := strings.Join(words, " "); t != "" {
+		return t
+	}
+	return "No Status"
+}
+
+// statusOrder returns the keys of groups: the statuses of requirement
+// 1.5.3 first, then the others alphabetically, then no status.
+func statusOrder(groups map[string][]*Project) []string {
+	var out, other []string
+	for _, k := range projectStatuses {
+		if _, ok := groups[k]; ok {
+			out = append(out, k)
+		}
+	}
+	for k := range groups {
+		if k != "" && !contains(projectStatuses, k) {
+			other = append(other, k)
+		}
+	}
+	sort.Strings(other)
+	out = append(out, other...)
+	if _, ok := groups[""]; ok {
+		out = append(out, "")
+	}
+	return out
+}
+
+func closed(status string) bool {
+	return contains(closedStatuses, statusKey(status))
+}
+
+func projectLink(p *Project) string {
+	return fmt.Sprintf("[[%s\\|%s]]", p.Link, cell(p.Name))
+}
+
+func touchedCell(t Touched) string {
+	return t.Time.Format("2006-01-02") + " (" + t.Source + ")"
+}
+
+// daysSince counts the calendar days from t to now.
+func daysSince(t, now time.Time) int {
+	day := func(x time.Time) time.Time { return time.Date(x.Year(), x.Month(), x.Day(), 0, 0, 0, 0, time.UTC) }
+	return int(day(now.Local()).Sub(day(t.Local())).Hours() / 24)
+}
+
+// bar draws the completion ratio as ten blocks and a percentage.
+func bar(g Progress) string {
+	r, ok := g.Ratio()
+	if !ok {
+		return "—"
+	}
+	n := int(r*10 + 0.5)
+	return strings.Repeat("█", n) + strings.Repeat("░", 10-n) + " " + percent(g)
+}
+
+func percent(g Progress) string {
+	r, ok := g.Ratio()
+	if !ok {
+		return "—"
+	}
+	return fmt.Sprintf("%d%%", int(r*100+0.5))
+}
@@ -0,0 +1,187 @@
+package tasks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+func TestProgress(t *testing.T) {
+	p := project(t, "PROJECT_API.md", "## Active\n\n- [x] Spec\n- [ ] Client\n  - [x] Retry\n- [!] Logo\n\n## Inactive\n\n- [-] Dropped\n")
+	g := ProjectProgress(p)
+	if g != (Progress{Total: 5, Done: 2, Blocked: 1, NotDoing: 1}) {
+		t.Errorf("ProjectProgress = %+v", g)
+	}
+	tests := []struct {
+		g   Progress
+		r   float64
+		ok  bool
+		bar string
+		pct string
+	}{
+		{g: g, r: 0.5, ok: true, bar: "█████░░░░░ 50%", pct: "50%"},
+		{g: Progress{Total: 3, Done: 1}, r: 1.0 / 3, ok: true, bar: "███░░░░░░░ 33%", pct: "33%"},
+		{g: Progress{Total: 3, Done: 2}, r: 2.0 / 3, ok: true, bar: "███████░░░ 67%", pct: "67%"},
+		{g: Progress{Total: 2, Done: 2}, r: 1, ok: true, bar: "██████████ 100%", pct: "100%"},
+		{g: Progress{Total: 2, NotDoing: 2}, bar: "—", pct: "—"},
+		{g: Progress{}, bar: "—", pct: "—"},
+	}
+	for _, tt := range tests {
+		r, ok := tt.g.Ratio()
+		if r != tt.r || ok != tt.ok {
+			t.Errorf("%+v.Ratio() = %v, %v; want %v, %v", tt.g, r, ok, tt.r, tt.ok)
+		}
+		if got := bar(tt.g); got != tt.bar {
+			t.Errorf("bar(%+v) = %q, want %q", tt.g, got, tt.bar)
+		}
+		if got := percent(tt.g); got != tt.pct {
+			t.Errorf("percent(%+v) = %q, want %q", tt.g, got, tt.pct)
+		}
+	}
+}
+
+func TestStatusTitle(t *testing.T) {
+	tests := map[string]string{
+		"in-progress": "In Progress",
+		"In Progress": "In Progress",
+		"in_progress": "In Progress",
+		"REVIEW":      "Review",
+		"":            "No Status",
+		" - ":         "No Status",
+	}
+	for in, want := range tests {
+		if got := statusTitle(in); got != want {
+			t.Errorf("statusTitle(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 12, 0, 0, 0, time.UTC) }
+	now := day(10, 18)
+	withStatus := func(file, status, tasks string) *Project {
+		return project(t, file, "---\nstatus: "+status+"\n---\n\n## Active\n\n"+tasks)
+	}
+	api := withStatus("PROJECT_API.md", "In Progress", "- [x] Spec\n- [ ] Client\n")
+	web := withStatus("PROJECT_WEB.md", "on_hold", "- [!] Logo\n")
+	old := withStatus("PROJECT_OLD.md", "completed", "- [x] Ship\n")
+	plan := withStatus("PROJECT_PLAN.md", "planning", "")
+	bare := project(t, "PROJECT_BARE.md", "## Active\n\n- [-] Dropped\n")
+	touched := map[string]Touched{
+		api.Path:  {day(10, 1), "git"},
+		web.Path:  {day(9, 1), "mtime"},
+		old.Path:  {day(1, 1), "git"},
+		plan.Path: {day(10, 4), "git"},
+	}
+
+	got := string(Dashboard([]*Project{api, web, old, plan, bare}, touched, now, StaleDays))
+	for _, want := range []string{
+		"Generated by `pal dashboard` on 2026-10-18 ",
+		"## Summary\n\n- **Projects:** 5, 2 stale\n- **Tasks done:** 2 of 4 (50%)\n- **Blocked tasks:** 1\n",
+		// Oldest first; completed projects are never stale, and 14 days is
+		// not more than StaleDays.
+		"## Stale (>14 days)\n\n| Project | Domain | Status | Last touched | Days |\n|---------|--------|--------|--------------|---:|\n" +
+			"| [[PROJECT_WEB\\|PROJECT_WEB]] | Work | On Hold | 2026-09-01 (mtime) | 47 |\n" +
+			"| [[PROJECT_API\\|PROJECT_API]] | Work | In Progress | 2026-10-01 (git) | 17 |\n\n",
+		"## Planning (1)\n\n| Project | Domain | Progress | Done | Blocked | Last touched |\n|---------|--------|----------|---:|---:|--------------|\n" +
+			"| [[PROJECT_PLAN\\|PROJECT_PLAN]] | Work | — | 0/0 | 0 | 2026-10-04 (git) |\n\n" +
+			"## In Progress (1)\n\n",
+		"| [[PROJECT_API\\|PROJECT_API]] | Work | █████░░░░░ 50% | 1/2 | 0 | 2026-10-01 (git) |\n\n## Completed (1)\n\n",
+		"| [[PROJECT_OLD\\|PROJECT_OLD]] | Work | ██████████ 100% | 1/1 | 0 | 2026-01-01 (git) |\n\n## On Hold (1)\n\n",
+		"| [[PROJECT_WEB\\|PROJECT_WEB]] | Work | ░░░░░░░░░░ 0% | 0/1 | 1 | 2026-09-01 (mtime) |\n\n## No Status (1)\n\n",
+		"| [[PROJECT_BARE\\|PROJECT_BARE]] | Work | — | 0/0 | 0 | — |\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Dashboard has no %q in:\n%s", want, got)
+		}
+	}
+
+	fresh := string(Dashboard([]*Project{api}, touched, day(10, 15), StaleDays))
+	if !strings.Contains(fresh, "- **Projects:** 1, 0 stale\n") || !strings.Contains(fresh, "## Stale (>14 days)\n\n_None._\n") {
+		t.Errorf("Dashboard without stale projects:\n%s", fresh)
+	}
+	if empty := string(Dashboard(nil, nil, now, StaleDays)); !strings.HasSuffix(empty, "## Summary\n\nNo projects found.\n") {
+		t.Errorf("Dashboard without projects:\n%s", empty)
+	}
+}
+
+func TestLastTouched(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]time.Time{
+		"Domains/Work/01_PROJECTS/PROJECT_API.md": time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC),
+		"Domains/Work/01_PROJECTS/PROJECT_WEB.md": time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+	}
+	var projects []*Project
+	for rel, mtime := range files {
+		abs := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(abs, []byte("## Active\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(abs, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		projects = append(projects, &Project{Path: rel})
+	}
+	v, err := vault.Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(name string, want map[string]Touched) {
+		t.Helper()
+		got, err := LastTouched(v, projects)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for rel, w := range want {
+			if g := got[rel]; !g.Time.Equal(w.Time) || g.Source != w.Source {
+				t.Errorf("%s: %s touched %s (%s), want %s (%s)", name, rel, g.Time, g.Source, w.Time, w.Source)
+			}
+		}
+	}
+	const api, web = "Domains/Work/01_PROJECTS/PROJECT_API.md", "Domains/Work/01_PROJECTS/PROJECT_WEB.md"
+	mtimes := map[string]Touched{api: {files[api], "mtime"}, web: {files[web], "mtime"}}
+	if exec.Command("git", "-C", root, "rev-parse").Run() != nil {
+		check("outside git", mtimes)
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	commit := time.Date(2026, 10, 10, 8, 0, 0, 0, time.UTC)
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root, "-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+commit.Format(time.RFC3339), "GIT_AUTHOR_DATE="+commit.Format(time.RFC3339))
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", api)
+	git("commit", "-q", "-m", "api")
+	// API is committed; WEB is untracked and falls back to its mtime.
+	check("in git", map[string]Touched{api: {commit, "git"}, web: mtimes[web]})
+
+	edited := time.Date(2026, 10, 12, 12, 0, 0, 0, time.UTC)
+	abs := filepath.Join(root, filepath.FromSlash(api))
+	if err := os.WriteFile(abs, []byte("## Active\n\n- [ ] New\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(abs, edited, edited); err != nil {
+		t.Fatal(err)
+	}
+	check("uncommitted", map[string]Touched{api: {edited, "mtime"}})
+
+	if _, err := LastTouched(v, []*Project{{Path: "Domains/Work/01_PROJECTS/PROJECT_GONE.md"}}); err == nil {
+		t.Error("LastTouched of a missing file did not fail")
+	}
+}
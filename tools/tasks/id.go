@@ -0,0 +1,131 @@
+package tasks
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+)
+
+// IDPrefix starts every task ID pal assigns.
+const IDPrefix = "t-"
+
+// IDs is the set of task IDs in use in the vault, to draw new ones from.
+type IDs map[string]bool
+
+// TakenIDs returns the IDs of every task of projects.
+func TakenIDs(projects []*Project) IDs {
+	ids := IDs{}
+	for _, p := range projects {
+		for _, t := range p.Tasks {
+			if t.ID != "" {
+				ids[t.ID] = true
+			}
+		}
+	}
+	return ids
+}
+
+// New returns a random ID no task uses yet, "t-" and four hex digits, and
+// marks it taken.
+func (ids IDs) New() string {
+	for {
+		var b [2]byte
+		rand.Read(b[:])
+		id := fmt.Sprintf("%s%x", IDPrefix, b)
+		if !ids[id] {
+			ids[id] = true
+			return id
+		}
+	}
+}
+
+// Duplicate is a task carrying an ID an earlier task already has, most
+// often a line copied with its block ID.
+type Duplicate struct {
+	ID      string
+	Project *Project
+	Task    Task
+	// First and FirstLine locate the task that keeps the ID.
+	First     *Project
+	FirstLine int
+}
+
+// Duplicates returns the tasks of projects whose ID an earlier task, in
+// project and file order, already has.
+func Duplicates(projects []*Project) []Duplicate {
+	type ref struct {
+		p    *Project
+		line int
+	}
+	first := map[string]ref{}
+	var out []Duplicate
+	for _, p := range projects {
+		for _, t := range p.Tasks {
+			if t.ID == "" {
+				continue
+			}
+			if f, ok := first[t.ID]; ok {
+				out = append(out, Duplicate{ID: t.ID, Project: p, Task: t, First: f.p, FirstLine: f.line})
+				continue
+			}
+			first[t.ID] = ref{p, t.Line}
+		}
+	}
+	return out
+}
+
+// AssignIDs returns p with a new ID from ids on every task that has none,
+// and the number of IDs assigned.
+func (p *Project) AssignIDs(ids IDs) (*Project, int, error) {
+	set := map[int]string{}
+	for _, t := range p.Tasks {
+		if t.ID == "" {
+			set[t.Line] = ids.New()
+		}
+	}
+	if len(set) == 0 {
+		return p, 0, nil
+	}
+	np, err := p.setIDs(set)
+	return np, len(set), err
+}
+
+// ReID returns p with a new ID from ids on the tasks at lines, keyed by
+// line to the ID each gets.
+func (p *Project) ReID(lines []int, ids IDs) (*Project, map[int]string, error) {
+	set := map[int]string{}
+	for _, l := range lines {
+		set[l] = ids.New()
+	}
+	np, err := p.setIDs(set)
+	return np, set, err
+}
+
+// setIDs rewrites the block ID of the task lines in set, keeping the rest
+// of each line as written.
+func (p *Project) setIDs(set map[int]string) (*Project, error) {
+	nl := "\n"
+	if strings.Contains(p.text, "\r\n") {
+		nl = "\r\n"
+	}
+	lines := markdown.Lines(p.text)
+	for l, id := range set {
+		lines[l-1] = WithID(lines[l-1], id)
+	}
+	out := strings.Join(lines, nl)
+	if strings.HasSuffix(p.text, "\n") {
+		out += nl
+	}
+	return p.WithText([]byte(out))
+}
+
+// WithID returns the task line with its block ID set to id, replacing the
+// one it has.
+func WithID(line, id string) string {
+	if m := blockID.FindStringIndex(line); m != nil {
+		line = line[:m[0]]
+	}
+	return strings.TrimRight(line, " \t") + " ^" + id
+}
@@ -0,0 +1,131 @@
+package tasks
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestWithID(t *testing.T) {
+	tests := []struct {
+		line, want string
+	}{
+		{"- [ ] Write the spec", "- [ ] Write the spec ^t-00aa"},
+		{"- [ ] Write the spec   ", "- [ ] Write the spec ^t-00aa"},
+		{"- [ ] Write the spec ^t-0001", "- [ ] Write the spec ^t-00aa"},
+		{"  - [x] Ship (from: [[Kickoff]]) ^old-id \t", "  - [x] Ship (from: [[Kickoff]]) ^t-00aa"},
+		{"- [ ] Mention a^b", "- [ ] Mention a^b ^t-00aa"},
+	}
+	for _, tt := range tests {
+		if got := WithID(tt.line, "t-00aa"); got != tt.want {
+			t.Errorf("WithID(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestIDs(t *testing.T) {
+	api := project(t, "PROJECT_API.md", "## Active\n\n- [ ] Spec ^t-0001\n- [ ] Client\n")
+	web := project(t, "PROJECT_WEB.md", "## Active\n\n- [ ] Launch ^t-0101\n")
+	ids := TakenIDs([]*Project{api, web})
+	if len(ids) != 2 || !ids["t-0001"] || !ids["t-0101"] {
+		t.Errorf("TakenIDs = %v", ids)
+	}
+
+	form := regexp.MustCompile(`^t-[0-9a-f]{4}$`)
+	for i := 0; i < 50; i++ {
+		id := ids.New()
+		if !form.MatchString(id) {
+			t.Fatalf("New() = %q, want t- and four hex digits", id)
+		}
+		if len(ids) != 3+i {
+			t.Fatalf("New() = %q, which was taken", id)
+		}
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	api := project(t, "PROJECT_API.md", "## Active\n\n- [ ] Spec ^t-0001\n- [ ] Spec copy ^t-0001\n- [ ] Client\n")
+	web := project(t, "PROJECT_WEB.md", "## Active\n\n- [ ] Launch ^t-0101\n- [ ] Moved spec ^t-0001\n")
+	got := Duplicates([]*Project{api, web})
+	tests := []struct {
+		id        string
+		project   *Project
+		line      int
+		first     *Project
+		firstLine int
+	}{
+		{"t-0001", api, 4, api, 3},
+		{"t-0001", web, 4, api, 3},
+	}
+	if len(got) != len(tests) {
+		t.Fatalf("Duplicates = %+v", got)
+	}
+	for i, tt := range tests {
+		d := got[i]
+		if d.ID != tt.id || d.Project != tt.project || d.Task.Line != tt.line || d.First != tt.first || d.FirstLine != tt.firstLine {
+			t.Errorf("duplicate %d = %s %s:%d (first %s:%d), want %s %s:%d (first %s:%d)", i,
+				d.ID, d.Project.Path, d.Task.Line, d.First.Path, d.FirstLine,
+				tt.id, tt.project.Path, tt.line, tt.first.Path, tt.firstLine)
+		}
+	}
+	if d := Duplicates([]*Project{web}); d != nil {
+		t.Errorf("Duplicates without any = %+v", d)
+	}
+}
+
+func TestAssignIDs(t *testing.T) {
+	tests := []struct {
+		name, text string
+		n          int
+	}{
+		{name: "missing", text: "## Active\n\n- [ ] Spec ^t-0001\n- [ ] Client\n  - [ ] Retry\n", n: 2},
+		{name: "crlf", text: "## Active\r\n\r\n- [ ] Client\r\n", n: 1},
+		{name: "no final newline", text: "## Active\n\n- [ ] Client", n: 1},
+		{name: "all set", text: "## Active\n\n- [ ] Spec ^t-0001\n", n: 0},
+	}
+	for _, tt := range tests {
+		p := project(t, "PROJECT_API.md", tt.text)
+		ids := TakenIDs([]*Project{p})
+		np, n, err := p.AssignIDs(ids)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if n != tt.n || len(ids) != len(p.Tasks) {
+			t.Errorf("%s: assigned %d IDs, %d taken; want %d, %d", tt.name, n, len(ids), tt.n, len(p.Tasks))
+		}
+		// Only the block IDs change: the same file with them stripped.
+		if got, want := stripIDs(string(np.Bytes())), stripIDs(tt.text); got != want {
+			t.Errorf("%s: text = %q, want %q with IDs", tt.name, np.Bytes(), tt.text)
+		}
+		for _, tk := range np.Tasks {
+			if tk.ID == "" {
+				t.Errorf("%s: line %d has no ID", tt.name, tk.Line)
+			}
+		}
+	}
+}
+
+func TestReID(t *testing.T) {
+	p := project(t, "PROJECT_API.md", "## Active\n\n- [ ] Spec ^t-0001\n- [ ] Spec copy ^t-0001\n")
+	ids := TakenIDs([]*Project{p})
+	np, set, err := p.ReID([]int{4}, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := set[4]
+	if len(set) != 1 || id == "" || id == "t-0001" || !ids[id] {
+		t.Fatalf("ReID set = %v", set)
+	}
+	if want := "## Active\n\n- [ ] Spec ^t-0001\n- [ ] Spec copy ^" + id + "\n"; string(np.Bytes()) != want {
+		t.Errorf("ReID text = %q, want %q", np.Bytes(), want)
+	}
+	if d := Duplicates([]*Project{np}); d != nil {
+		t.Errorf("duplicates after ReID: %+v", d)
+	}
+}
+
+var idSuffix = regexp.MustCompile(` \^t-[0-9a-f]{4}(\r?\n|$)`)
+
+func stripIDs(s string) string {
+	return idSuffix.ReplaceAllString(s, "$1")
+}
@@ -0,0 +1,334 @@
+package tasks
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/wikilink"
+)
+
+// ProjectPrefix starts the name of every project file.
+const ProjectPrefix = "PROJECT_"
+
+// Project is one PROJECT_*.md file and the tasks pulled from it.
+type Project struct {
+	// Path is the vault-relative file and Domain the folder name of its
+	// domain.
+	Path   string
+	Domain string
+	// Name and Status come from the frontmatter, Name falling back to the
+	// file name.
+	Name   string
+	Status string
+	// Tag is the #ProjectName tag, without '#', that marks the project's
+	// tasks in MASTER.md, and Link the wikilink target of the file.
+	Tag  string
+	Link string
+	// Tasks are the project's tasks above its "## Notes" section, in file
+	// order.
+	Tasks []Task
+	// Hash is the SHA-256 of the file as read, to tell whether it changed
+	// since a pull.
+	Hash string
+
+	text string
+}
+
+// IsProjectFile reports whether the vault-relative p is a PROJECT_*.md
+// file.
+func IsProjectFile(p string) bool {
+	base := path.Base(p)
+	return len(base) > len(ProjectPrefix) && strings.EqualFold(base[:len(ProjectPrefix)], ProjectPrefix) &&
+		strings.EqualFold(path.Ext(base), ".md")
+}
+
+// ReadProjects reads every PROJECT_*.md in the domains' 01_PROJECTS
+// folders, in domain and path order (requirement 1.5.2). Each project
+// gets a tag unique in the vault: the file name in PascalCase, prefixed
+// with the domain when two domains have a project of that name.
+func ReadProjects(v *vault.Vault) ([]*Project, error) {
+	all, err := v.AllFiles()
+	if err != nil {
+		return nil, err
+	}
+	links := wikilink.NewResolver(all)
+	var out []*Project
+	for _, d := range v.Domains {
+		files, err := v.Files(d, vault.ProjectsDir)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+		for _, f := range files {
+			if !IsProjectFile(f) {
+				continue
+			}
+			data, err := os.ReadFile(v.Abs(f))
+			if err != nil {
+				return nil, err
+			}
+			p := &Project{Path: f, Domain: d.Name, Tag: ProjectTag(f), Link: links.Linktext(f)}
+			if p, err = p.WithText(data); err != nil {
+				return nil, err
+			}
+			out = append(out, p)
+		}
+	}
+	byTag := map[string]int{}
+	for _, p := range out {
+		byTag[strings.ToLower(p.Tag)]++
+	}
+	for _, p := range out {
+		if byTag[strings.ToLower(p.Tag)] > 1 {
+			p.Tag = p.Domain + "/" + p.Tag
+		}
+	}
+	return out, nil
+}
+
+// WithText returns a copy of p read from data, the project file's
+// content: as on disk, or as a sync is about to write it.
+func (p *Project) WithText(data []byte) (*Project, error) {
+	n, err := vault.ParseNote(p.Path, data)
+	if n == nil {
+		return nil, err
+	}
+	c := *p
+	c.Name, c.Status = n.Field("name"), n.Field("status")
+	if c.Name == "" {
+		c.Name = n.Stem()
+	}
+	c.Tasks = ProjectTasks(string(data))
+	c.Hash = fmt.Sprintf("%x", sha256.Sum256(data))
+	c.text = string(data)
+	return &c, nil
+}
+
+// Bytes returns the project file as read, or as rewritten by the method
+// that returned p.
+func (p *Project) Bytes() []byte {
+	return []byte(p.text)
+}
+
+// ProjectTag returns the #ProjectName tag of a project file, without
+// '#': PROJECT_WEBSITE_REDESIGN.md gives "WebsiteRedesign".
+func ProjectTag(p string) string {
+	stem := strings.TrimSuffix(path.Base(p), path.Ext(p))
+	if len(stem) > len(ProjectPrefix) && strings.EqualFold(stem[:len(ProjectPrefix)], ProjectPrefix) {
+		stem = stem[len(ProjectPrefix):]
+	}
+	var b strings.Builder
+	for _, w := range strings.FieldsFunc(stem, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		rs := []rune(strings.ToLower(w))
+		rs[0] = unicode.ToUpper(rs[0])
+		b.WriteString(string(rs))
+	}
+	if b.Len() == 0 {
+		return "Project"
+	}
+	return b.String()
+}
+
+// ProjectTasks returns the tasks of a project file, leaving out the
+// protected "## Notes" section.
+func ProjectTasks(text string) []Task {
+	lines := markdown.Lines(text)
+	fenced := markdown.InFence(lines)
+	for i, line := range lines {
+		if fenced[i] {
+			continue
+		}
+		if h, ok := markdown.ParseHeading(line); ok && h.Level == 2 && strings.EqualFold(h.Text, "Notes") {
+			return ParseDocument(strings.Join(lines[:i], "\n"))
+		}
+	}
+	return ParseDocument(text)
+}
+
+// Counts returns the number of tasks in each status.
+func Counts(ts []Task) map[Status]int {
+	out := map[Status]int{}
+	for _, t := range ts {
+		out[t.Status]++
+	}
+	return out
+}
+
+// MasterLine renders t as its MASTER.md line: top level, followed by the
+// project's tag, a link to the project file, the task's Tasks metadata and
+// its block ID. The metadata stays last so the Tasks plugin reads it.
+func (p *Project) MasterLine(t Task) string {
+	id, meta := t.ID, t.Meta
+	t.Indent, t.Marker, t.Legacy, t.ID, t.Meta = "", "-", "", "", Meta{}
+	s := t.String()
+	if !t.HasTag(p.Tag) {
+		s += " #" + p.Tag
+	}
+	s += " [[" + p.Link + "]]" + meta.String()
+	if id != "" {
+		s += " ^" + id
+	}
+	return s
+}
+
+// Order is how Master sorts the tasks of each group.
+type Order string
+
+const (
+	// ByFile keeps the order of the project file.
+	ByFile Order = ""
+	// ByDue puts the earliest due date first and tasks without one last,
+	// then the highest priority first.
+	ByDue Order = "due"
+	// ByPriority puts the highest priority first, then the earliest due
+	// date.
+	ByPriority Order = "priority"
+)
+
+// ParseOrder reads an order by name: "file", "due" or "priority". "" is
+// ByFile.
+func ParseOrder(s string) (Order, error) {
+	switch o := Order(strings.ToLower(strings.TrimSpace(s))); o {
+	case ByFile, "file":
+		return ByFile, nil
+	case ByDue, ByPriority:
+		return o, nil
+	}
+	return ByFile, fmt.Errorf("unknown task order %q; want file, due or priority", s)
+}
+
+// Sort orders ts in place, keeping file order among equals.
+func (o Order) Sort(ts []Task) {
+	if o == ByFile {
+		return
+	}
+	due := func(t Task) string {
+		if t.Due == "" {
+			return "9999-99-99"
+		}
+		return t.Due
+	}
+	sort.SliceStable(ts, func(i, j int) bool {
+		a, b := ts[i], ts[j]
+		if o == ByPriority && a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if da, db := due(a), due(b); da != db {
+			return da < db
+		}
+		return a.Priority > b.Priority
+	})
+}
+
+// Master renders MASTER.md from projects (requirements 1.5.2, 1.5.4 and
+// 1.5.10): a summary table of task counts by status, then one section per
+// project with its tasks under Active, Inactive and Done, sorted by order.
+// Subtasks follow their parent task, indented, in file order.
+// An order other than ByFile is kept in the frontmatter as "sort". The
+// output depends only on the projects and order, so pulling twice from the
+// same files gives the same bytes.
+func Master(projects []*Project, order Order) []byte {
+	var b strings.Builder
+	b.WriteString("---\ntype: tasks\ngenerated_by: pal tasks pull\n")
+	if order != ByFile {
+		fmt.Fprintf(&b, "sort: %s\n", order)
+	}
+	b.WriteString("---\n\n# Master Task List\n\n")
+	b.WriteString("> [!NOTE]\n> Generated from every `PROJECT_*.md` by `pal tasks pull`. Each task carries its project's tag and a link to the project file.\n\n")
+
+	b.WriteString("## Summary\n\n")
+	if len(projects) == 0 {
+		b.WriteString("No projects found.\n")
+		return []byte(b.String())
+	}
+	b.WriteString("| Project | Domain |")
+	for _, s := range Statuses {
+		b.WriteString(" " + s.String() + " |")
+	}
+	b.WriteString(" Total |\n|---------|--------|")
+	for range Statuses {
+		b.WriteString("---:|")
+	}
+	b.WriteString("---:|\n")
+	total := map[Status]int{}
+	all := 0
+	for _, p := range projects {
+		counts := Counts(p.Tasks)
+		fmt.Fprintf(&b, "| [[%s\\|%s]] | %s |", p.Link, cell(p.Name), p.Domain)
+		for _, s := range Statuses {
+			fmt.Fprintf(&b, " %d |", counts[s])
+			total[s] += counts[s]
+		}
+		fmt.Fprintf(&b, " %d |\n", len(p.Tasks))
+		all += len(p.Tasks)
+	}
+	b.WriteString("| **Total** | |")
+	for _, s := range Statuses {
+		fmt.Fprintf(&b, " **%d** |", total[s])
+	}
+	fmt.Fprintf(&b, " **%d** |\n", all)
+
+	names := map[string]int{}
+	for _, p := range projects {
+		names[p.Name]++
+	}
+	for _, p := range projects {
+		if names[p.Name] > 1 {
+			fmt.Fprintf(&b, "\n## %s (%s)\n\n", p.Name, p.Domain)
+		} else {
+			fmt.Fprintf(&b, "\n## %s\n\n", p.Name)
+		}
+		fmt.Fprintf(&b, "#%s · %s · [[%s]]", p.Tag, p.Domain, p.Link)
+		if p.Status != "" {
+			fmt.Fprintf(&b, " · status: %s", p.Status)
+		}
+		b.WriteString("\n")
+		// Subtasks stay under their parent whatever their status, as in
+		// the project file, where a parent moves with them.
+		subtasks := map[int][]int{}
+		for i, t := range p.Tasks {
+			if t.Parent >= 0 {
+				subtasks[t.Parent] = append(subtasks[t.Parent], i)
+			}
+		}
+		var write func(i int, indent string)
+		write = func(i int, indent string) {
+			b.WriteString(indent + p.MasterLine(p.Tasks[i]) + "\n")
+			for _, j := range subtasks[i] {
+				write(j, indent+"  ")
+			}
+		}
+		index := map[int]int{}
+		for i, t := range p.Tasks {
+			index[t.Line] = i
+		}
+		for _, g := range Groups {
+			fmt.Fprintf(&b, "\n### %s\n\n", g)
+			var group []Task
+			for _, t := range p.Tasks {
+				if t.Parent < 0 && t.Status.Group() == g {
+					group = append(group, t)
+				}
+			}
+			order.Sort(group)
+			for _, t := range group {
+				write(index[t.Line], "")
+			}
+			if len(group) == 0 {
+				b.WriteString("_None._\n")
+			}
+		}
+	}
+	return []byte(b.String())
+}
+
+func cell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,91 @@
+package tasks
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaster(t *testing.T) {
+	api := project(t, "PROJECT_API.md", "---\nname: API\nstatus: active\n---\n\n## Active\n\n"+
+		"- [/] Build the client 📅 2026-11-01 ^t-0002\n  - [x] Retry on 503 ^t-0003\n    - [ ] Back off ^t-0005\n"+
+		"- [ ] Write the spec ⏫ 📅 2026-10-20 ^t-0001\n\n## Done\n\n- [x] Kickoff ^t-0004\n\n## Notes\n\n- [ ] not a task\n")
+	web := project(t, "PROJECT_WEB.md", "## Active\n\n- [ ] Launch #Web ^t-0101\n")
+
+	got := string(Master([]*Project{api, web}, ByFile))
+	for _, want := range []string{
+		"| [[PROJECT_API\\|API]] | Work | 2 | 1 | 0 | 0 | 0 | 0 | 2 | 5 |\n",
+		"| **Total** | | **3** | **1** | **0** | **0** | **0** | **0** | **2** | **6** |\n",
+		"## API\n\n#Api · Work · [[PROJECT_API]] · status: active\n",
+		"### Active\n\n" +
+			"- [/] Build the client #Api [[PROJECT_API]] 📅 2026-11-01 ^t-0002\n" +
+			"  - [x] Retry on 503 #Api [[PROJECT_API]] ^t-0003\n" +
+			"    - [ ] Back off #Api [[PROJECT_API]] ^t-0005\n" +
+			"- [ ] Write the spec #Api [[PROJECT_API]] ⏫ 📅 2026-10-20 ^t-0001\n\n### Inactive\n\n_None._\n\n" +
+			"### Done\n\n- [x] Kickoff #Api [[PROJECT_API]] ^t-0004\n",
+		"- [ ] Launch #Web [[PROJECT_WEB]] ^t-0101\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Master has no %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "not a task") {
+		t.Error("Master took a task from ## Notes")
+	}
+	if again := string(Master([]*Project{api, web}, ByFile)); again != got {
+		t.Error("Master is not deterministic")
+	}
+
+	byDue := string(Master([]*Project{api}, ByDue))
+	if !strings.Contains(byDue, "sort: due\n") ||
+		!strings.Contains(byDue, "- [ ] Write the spec #Api [[PROJECT_API]] ⏫ 📅 2026-10-20 ^t-0001\n- [/] Build the client") {
+		t.Errorf("Master by due date:\n%s", byDue)
+	}
+	if empty := string(Master(nil, ByFile)); !strings.HasSuffix(empty, "## Summary\n\nNo projects found.\n") {
+		t.Errorf("Master without projects:\n%s", empty)
+	}
+}
+
+func TestParseMaster(t *testing.T) {
+	api := project(t, "PROJECT_API.md", "## Active\n\n- [ ] Write the spec ^t-0001\n  - [ ] Outline ^t-0002\n")
+	web := project(t, "PROJECT_WEB.md", "## Active\n\n- [ ] Launch ^t-0101\n")
+	master := string(Master([]*Project{api, web}, ByFile)) + "\n## Notes\n\n- [ ] Call the bank\n"
+	// Tagged for Web in API's section, and untagged in Web's.
+	master = strings.Replace(master, "\n### Inactive", "- [x] Review copy #Web\n\n### Inactive", 1)
+	master = strings.Replace(master, "## PROJECT_WEB\n", "## PROJECT_WEB\n\n- [ ] Write tests\n", 1)
+
+	tests := []struct {
+		text, project, id string
+		indent            string
+	}{
+		{text: "Write the spec", project: "Api", id: "t-0001"},
+		{text: "Outline", project: "Api", id: "t-0002", indent: "  "},
+		{text: "Review copy", project: "Web"},
+		{text: "Write tests", project: "Web"},
+		{text: "Launch", project: "Web", id: "t-0101"},
+	}
+	got := ParseMaster(master, []*Project{api, web})
+	if len(got) != len(tests) {
+		t.Fatalf("ParseMaster gave %d tasks, want %d: %+v", len(got), len(tests), got)
+	}
+	for i, tt := range tests {
+		mt := got[i]
+		if mt.Text != tt.text || mt.Project == nil || mt.Project.Tag != tt.project || mt.ID != tt.id || mt.Indent != tt.indent {
+			t.Errorf("task %d = %q in %v, ID %q, indent %q; want %q in %s, ID %q, indent %q",
+				i, mt.Text, mt.Project, mt.ID, mt.Indent, tt.text, tt.project, tt.id, tt.indent)
+		}
+	}
+}
+
+func TestProjectTag(t *testing.T) {
+	tests := map[string]string{
+		"Domains/Work/01_PROJECTS/PROJECT_WEBSITE_REDESIGN.md": "WebsiteRedesign",
+		"PROJECT_api.md":     "Api",
+		"project_q3-plan.md": "Q3Plan",
+		"PROJECT_.md":        "Project",
+	}
+	for in, want := range tests {
+		if got := ProjectTag(in); got != want {
+			t.Errorf("ProjectTag(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package tasks
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Priority is the Obsidian Tasks priority of a task. Normal, the zero
+// value, has no emoji.
+type Priority int
+
+const (
+	Lowest Priority = iota - 2
+	Low
+	Normal
+	Medium
+	High
+	Highest
+)
+
+var priorityEmoji = map[Priority]string{
+	Highest: "🔺",
+	High:    "⏫",
+	Medium:  "🔼",
+	Low:     "🔽",
+	Lowest:  "⏬",
+}
+
+// String returns the name of p, e.g. "High".
+func (p Priority) String() string {
+	switch p {
+	case Highest:
+		return "Highest"
+	case High:
+		return "High"
+	case Medium:
+		return "Medium"
+	case Low:
+		return "Low"
+	case Lowest:
+		return "Lowest"
+	}
+	return "Normal"
+}
+
+// DateLayout is how the Tasks plugin writes dates.
+const DateLayout = "2006-01-02"
+
+// Meta is the metadata the Obsidian Tasks plugin writes as emoji at the
+// end of a task line, e.g. "⏫ 🔁 every month ⏳ 2026-10-18 📅 2026-10-20"
+// after the description. Dates are kept as written, YYYY-MM-DD; "" when
+// absent.
+type Meta struct {
+	Priority Priority `json:"priority,omitempty"`
+	// Recurrence is the rule after 🔁, e.g. "every week when done".
+	Recurrence string `json:"recurrence,omitempty"`
+	Created    string `json:"created,omitempty"`   // ➕
+	Start      string `json:"start,omitempty"`     // 🛫
+	Scheduled  string `json:"scheduled,omitempty"` // ⏳
+	Due        string `json:"due,omitempty"`       // 📅
+	Cancelled  string `json:"cancelled,omitempty"` // ❌
+	Completed  string `json:"completed,omitempty"` // ✅
+}
+
+// IsZero reports whether m holds no metadata.
+func (m Meta) IsZero() bool {
+	return m == Meta{}
+}
+
+// dateFields pairs each date emoji, in the order the Tasks plugin writes
+// them, with its field. The first emoji of each entry is the one written.
+func (m *Meta) dateFields() []struct {
+	emoji []string
+	field *string
+} {
+	return []struct {
+		emoji []string
+		field *string
+	}{
+		{[]string{"➕"}, &m.Created},
+		{[]string{"🛫"}, &m.Start},
+		{[]string{"⏳", "⌛"}, &m.Scheduled},
+		{[]string{"📅", "📆", "🗓"}, &m.Due},
+		{[]string{"❌"}, &m.Cancelled},
+		{[]string{"✅"}, &m.Completed},
+	}
+}
+
+// String renders m as the Tasks plugin does: priority, recurrence, then
+// the dates. It is "" for no metadata and starts with a space otherwise.
+func (m Meta) String() string {
+	var b strings.Builder
+	if e, ok := priorityEmoji[m.Priority]; ok {
+		b.WriteString(" " + e)
+	}
+	if m.Recurrence != "" {
+		b.WriteString(" 🔁 " + m.Recurrence)
+	}
+	for _, f := range m.dateFields() {
+		if *f.field != "" {
+			b.WriteString(" " + f.emoji[0] + " " + *f.field)
+		}
+	}
+	return b.String()
+}
+
+var (
+	metaDate  = regexp.MustCompile(`(➕|🛫|⏳|⌛|📅|📆|🗓|❌|✅)\x{FE0F}?[ \t]*(\d{4}-\d{2}-\d{2})[ \t]*$`)
+	metaPrio  = regexp.MustCompile(`(🔺|⏫|🔼|🔽|⏬)\x{FE0F}?[ \t]*$`)
+	metaRecur = regexp.MustCompile(`🔁\x{FE0F}?[ \t]*([^🔺⏫🔼🔽⏬➕🛫⏳⌛📅📆🗓❌✅🔁#]*[^🔺⏫🔼🔽⏬➕🛫⏳⌛📅📆🗓❌✅🔁#\s])[ \t]*$`)
+	trailTag  = regexp.MustCompile(`(?:^|[ \t])(#[\p{L}\p{N}_][\p{L}\p{N}_/-]*)[ \t]*$`)
+)
+
+// parseMeta takes the Tasks metadata off the end of text, as the plugin
+// reads it: emoji fields and hashtags in any order after the description.
+// The hashtags stay in the returned text, after the description; those
+// that no field precedes are part of the description and stay put.
+func parseMeta(text string) (string, Meta) {
+	var m Meta
+	var tags, pending []string
+	before := text // text with the pending tags still on
+	for {
+		text = strings.TrimRight(text, " \t")
+		if s := metaDate.FindStringSubmatchIndex(text); s != nil {
+			emoji, date := text[s[2]:s[3]], text[s[4]:s[5]]
+			for _, f := range m.dateFields() {
+				if contains(f.emoji, emoji) && *f.field == "" {
+					*f.field = date
+				}
+			}
+			text, tags, pending = text[:s[0]], append(pending, tags...), nil
+			continue
+		}
+		if s := metaPrio.FindStringSubmatchIndex(text); s != nil {
+			for p, e := range priorityEmoji {
+				if e == text[s[2]:s[3]] && m.Priority == Normal {
+					m.Priority = p
+				}
+			}
+			text, tags, pending = text[:s[0]], append(pending, tags...), nil
+			continue
+		}
+		if s := metaRecur.FindStringSubmatchIndex(text); s != nil {
+			if m.Recurrence == "" {
+				m.Recurrence = strings.TrimSpace(text[s[2]:s[3]])
+			}
+			text, tags, pending = text[:s[0]], append(pending, tags...), nil
+			continue
+		}
+		if s := trailTag.FindStringSubmatchIndex(text); s != nil {
+			if pending == nil {
+				before = text
+			}
+			pending = append([]string{text[s[2]:s[3]]}, pending...)
+			text = text[:s[0]]
+			continue
+		}
+		break
+	}
+	if pending != nil {
+		text = before
+	}
+	if len(tags) > 0 {
+		text += " " + strings.Join(tags, " ")
+	}
+	return text, m
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Recurs reports whether the task repeats.
+func (t *Task) Recurs() bool {
+	return t.Recurrence != ""
+}
+
+var (
+	everyUnit    = regexp.MustCompile(`^every(?: (\d+))? (day|week|month|year)s?$`)
+	dayName      = regexp.MustCompile(`\w+day`)
+	everyWeekday = regexp.MustCompile(`^every ((?:mon|tues|wednes|thurs|fri|satur|sun)day(?:(?:, ?| and )(?:mon|tues|wednes|thurs|fri|satur|sun)day)*)$`)
+	weekdays     = map[string]time.Weekday{
+		"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+		"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+	}
+)
+
+// Next returns the next occurrence of a recurring task completed on done:
+// the same task, to do, with its dates moved on by the rule. The rule
+// counts from the due date, else the scheduled date, else the start date;
+// from done itself with "when done" or when the task has none of those.
+// The other dates keep their distance to it.
+// Supported rules are "every [N] day|week|month|year[s]" and "every
+// Monday[, Friday...]"; ok is false for any other, and for a task that
+// does not recur.
+func (t Task) Next(done time.Time) (next Task, ok bool) {
+	rule := strings.ToLower(strings.Join(strings.Fields(t.Recurrence), " "))
+	whenDone := strings.HasSuffix(rule, " when done")
+	rule = strings.TrimSuffix(rule, " when done")
+	if rule == "" {
+		return Task{}, false
+	}
+
+	done = time.Date(done.Year(), done.Month(), done.Day(), 0, 0, 0, 0, time.UTC)
+	from, ref := done, done
+	for _, d := range []string{t.Due, t.Scheduled, t.Start} {
+		if r, err := time.Parse(DateLayout, d); err == nil {
+			from = r
+			if !whenDone {
+				ref = r
+			}
+			break
+		}
+	}
+
+	var to time.Time
+	if m := everyUnit.FindStringSubmatch(rule); m != nil {
+		n := 1
+		if m[1] != "" {
+			n, _ = strconv.Atoi(m[1])
+		}
+		switch m[2] {
+		case "day":
+			to = ref.AddDate(0, 0, n)
+		case "week":
+			to = ref.AddDate(0, 0, 7*n)
+		case "month":
+			to = addMonths(ref, n)
+		case "year":
+			to = addMonths(ref, 12*n)
+		}
+	} else if m := everyWeekday.FindStringSubmatch(rule); m != nil {
+		want := map[time.Weekday]bool{}
+		for _, name := range dayName.FindAllString(m[1], -1) {
+			want[weekdays[name]] = true
+		}
+		to = ref.AddDate(0, 0, 1)
+		for !want[to.Weekday()] {
+			to = to.AddDate(0, 0, 1)
+		}
+	} else {
+		return Task{}, false
+	}
+
+	next = t
+	next.Status, next.Legacy, next.ID, next.Raw = Todo, "", "", ""
+	next.Completed, next.Cancelled = "", ""
+	if next.Created != "" {
+		next.Created = done.Format(DateLayout)
+	}
+	shift := to.Sub(from)
+	for _, d := range []*string{&next.Due, &next.Scheduled, &next.Start} {
+		if v, err := time.Parse(DateLayout, *d); err == nil {
+			*d = v.Add(shift).Format(DateLayout)
+		}
+	}
+	return next, true
+}
+
+// addMonths adds n months to t, keeping the day of the month where the
+// target month has it and taking its last day otherwise, as the Tasks
+// plugin does: January 31 plus a month is February 28.
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), last)-1)
+}
+
+// Complete stamps each update that completes a task carrying Tasks
+// metadata with done as its ✅ date, and adds the next occurrence of each
+// recurring one, unless updates already add it, as the Tasks plugin does
+// when the task is ticked in Obsidian. It returns the recurring tasks
+// whose rule it cannot follow.
+func Complete(updates []Update, done time.Time) (out []Update, unknown []Task) {
+	added := map[string]bool{}
+	for _, u := range updates {
+		if u.Kind == AddTask && u.Task.Recurs() && u.Task.Status.Group() == Active {
+			added[u.Task.Text+"\x00"+u.Task.Recurrence] = true
+		}
+	}
+	for _, u := range updates {
+		if u.Kind != SetStatus || u.Status != Done || u.Task.Status == Done {
+			out = append(out, u)
+			continue
+		}
+		meta := u.Task.Meta
+		if u.Meta != nil {
+			meta = *u.Meta
+		}
+		if meta.IsZero() {
+			out = append(out, u)
+			continue
+		}
+		if meta.Completed == "" {
+			meta.Completed = done.Format(DateLayout)
+			u.Meta = &meta
+		}
+		out = append(out, u)
+		if meta.Recurrence == "" {
+			continue
+		}
+		t := u.Task
+		t.Meta = meta
+		if u.Text != "" {
+			t.Text = u.Text
+		}
+		if added[t.Text+"\x00"+t.Recurrence] {
+			continue
+		}
+		next, ok := t.Next(done)
+		if !ok {
+			unknown = append(unknown, t)
+			continue
+		}
+		next.Indent, next.Marker = "", "-"
+		out = append(out, Update{Kind: AddTask, Task: next, Status: next.Status})
+	}
+	return out, unknown
+}
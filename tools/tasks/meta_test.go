@@ -0,0 +1,149 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMeta(t *testing.T) {
+	tests := []struct {
+		line string
+		text string
+		meta Meta
+	}{
+		{line: "- [ ] Task 📅 2026-10-20", text: "Task", meta: Meta{Due: "2026-10-20"}},
+		{line: "- [ ] Task 📅 2026-10-20 #tag", text: "Task #tag", meta: Meta{Due: "2026-10-20"}},
+		{line: "- [ ] Task #a 📅 2026-10-20 #b", text: "Task #a #b", meta: Meta{Due: "2026-10-20"}},
+		{line: "- [ ] Task ⏫ #x 🔁 every week", text: "Task #x", meta: Meta{Priority: High, Recurrence: "every week"}},
+		{line: "- [ ] Task 🔁 every week #x ⏳ 2026-10-19 #y", text: "Task #x #y", meta: Meta{Recurrence: "every week", Scheduled: "2026-10-19"}},
+		{line: "- [ ] Task #a #b", text: "Task #a #b"},
+		{line: "- [ ] Task #a with words #b", text: "Task #a with words #b"},
+		{line: "- [ ] #a", text: "#a"},
+		{line: "- [ ] Task 📅 2026-10-20 not metadata #x", text: "Task 📅 2026-10-20 not metadata #x"},
+		{line: "- [x] Task 🔽 #x ✅ 2026-10-18 ^t-0001", text: "Task #x", meta: Meta{Priority: Low, Completed: "2026-10-18"}},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.line)
+		if !ok {
+			t.Errorf("Parse(%q) failed", tt.line)
+			continue
+		}
+		if got.Text != tt.text || got.Meta != tt.meta {
+			t.Errorf("Parse(%q) = text %q meta %+v, want %q %+v", tt.line, got.Text, got.Meta, tt.text, tt.meta)
+		}
+	}
+}
+
+func TestMetaRoundTrip(t *testing.T) {
+	for _, line := range []string{
+		"- [ ] Pay rent #home ⏫ 🔁 every month ⏳ 2026-10-25 📅 2026-11-01",
+		"- [x] Ship it 🔼 ➕ 2026-10-01 ✅ 2026-10-18 ^t-0001",
+		"- [-] Old idea ❌ 2026-10-02",
+	} {
+		tk, ok := Parse(line)
+		if !ok {
+			t.Fatalf("Parse(%q) failed", line)
+		}
+		if got := tk.String(); got != line {
+			t.Errorf("String() = %q, want %q", got, line)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	done := time.Date(2026, 10, 18, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		rule string
+		meta Meta // without the rule, which the test adds
+		want Meta
+		ok   bool
+	}{
+		{rule: "every week", meta: Meta{Due: "2026-10-20"}, want: Meta{Due: "2026-10-27"}, ok: true},
+		{rule: "every week when done", meta: Meta{Due: "2026-10-20"}, want: Meta{Due: "2026-10-25"}, ok: true},
+		{rule: "every 2 days", meta: Meta{Scheduled: "2026-10-18", Due: "2026-10-20"}, want: Meta{Scheduled: "2026-10-20", Due: "2026-10-22"}, ok: true},
+		{rule: "every month", meta: Meta{Due: "2026-01-31"}, want: Meta{Due: "2026-02-28"}, ok: true},
+		{rule: "every year", meta: Meta{Start: "2024-02-29"}, want: Meta{Start: "2025-02-28"}, ok: true},
+		{rule: "every Monday, Friday", meta: Meta{Due: "2026-10-20"}, want: Meta{Due: "2026-10-23"}, ok: true},
+		{rule: "every day", meta: Meta{Created: "2026-10-01", Completed: "2026-10-18"}, want: Meta{Created: "2026-10-18"}, ok: true},
+		{rule: "every full moon", meta: Meta{Due: "2026-10-20"}},
+		{rule: "", meta: Meta{Due: "2026-10-20"}},
+	}
+	for _, tt := range tests {
+		tk := Task{Marker: "-", Status: Done, Text: "Water plants", ID: "t-0001", Meta: tt.meta}
+		tk.Recurrence = tt.rule
+		next, ok := tk.Next(done)
+		if ok != tt.ok {
+			t.Errorf("Next(%q) ok = %v, want %v", tt.rule, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		tt.want.Recurrence = tt.rule
+		if next.Meta != tt.want || next.Status != Todo || next.ID != "" || next.Text != tk.Text {
+			t.Errorf("Next(%q) = %+v, want %+v, to do, no ID", tt.rule, next, tt.want)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	done := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	task := func(line string) Task {
+		tk, ok := Parse(line)
+		if !ok {
+			t.Fatalf("Parse(%q) failed", line)
+		}
+		return tk
+	}
+	weekly := task("- [ ] Water plants 🔁 every week 📅 2026-10-20 ^t-0001")
+	plain := task("- [ ] Write the spec ^t-0002")
+	dated := task("- [ ] Book flights 📅 2026-10-30 ^t-0003")
+	odd := task("- [ ] Call mum 🔁 every full moon ^t-0004")
+
+	out, unknown := Complete([]Update{
+		{Kind: SetStatus, Task: weekly, Status: Done},
+		{Kind: SetStatus, Task: plain, Status: Done},
+		{Kind: SetStatus, Task: dated, Status: Done},
+		{Kind: SetStatus, Task: odd, Status: Done},
+		{Kind: SetStatus, Task: plain, Status: InProgress},
+	}, done)
+
+	if len(unknown) != 1 || unknown[0].ID != "t-0004" {
+		t.Errorf("unknown = %+v, want the full moon task", unknown)
+	}
+	var got []string
+	for _, u := range out {
+		tk := u.Task
+		if u.Meta != nil {
+			tk.Meta = *u.Meta
+		}
+		tk.Status = u.Status
+		got = append(got, string(u.Kind)+" "+tk.String())
+	}
+	want := []string{
+		"status - [x] Water plants 🔁 every week 📅 2026-10-20 ✅ 2026-10-18 ^t-0001",
+		"add - [ ] Water plants 🔁 every week 📅 2026-10-27",
+		"status - [x] Write the spec ^t-0002",
+		"status - [x] Book flights 📅 2026-10-30 ✅ 2026-10-18 ^t-0003",
+		"status - [x] Call mum 🔁 every full moon ✅ 2026-10-18 ^t-0004",
+		"status - [/] Write the spec ^t-0002",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Complete:\ngot  %q\nwant %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Complete[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	// A next occurrence already added in MASTER.md is not added twice.
+	next := task("- [ ] Water plants 🔁 every week 📅 2026-10-27")
+	out, _ = Complete([]Update{
+		{Kind: SetStatus, Task: weekly, Status: Done},
+		{Kind: AddTask, Task: next, Status: Todo},
+	}, done)
+	if len(out) != 2 {
+		t.Errorf("Complete with the next occurrence added: %+v", out)
+	}
+}
@@ -0,0 +1,77 @@
+package tasks
+
+import (
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+)
+
+// Change is one rewritten line.
+type Change struct {
+	Line int
+	Old  string
+	New  string
+}
+
+// Migrate rewrites every legacy hashtag task in text into checkbox form:
+//
+//	legacy                                   checkbox
+//	- [ ] Ship the beta `#in-progress`   →   - [/] Ship the beta
+//	- Write the docs #open               →   - [ ] Write the docs
+//
+// The status hashtag counts only at the end of a line whose checkbox is
+// missing or empty, so "- [/] Fix the #done tag" is left as it is.
+// Only the checkbox and the status hashtag change. Fenced code blocks and
+// everything from the protected "## Notes" heading down are left alone.
+func Migrate(text string) (string, []Change) {
+	nl := "\n"
+	if strings.Contains(text, "\r\n") {
+		nl = "\r\n"
+	}
+	lines := markdown.Lines(text)
+	fenced := markdown.InFence(lines)
+	var changes []Change
+	for i, line := range lines {
+		if fenced[i] {
+			continue
+		}
+		if h, ok := markdown.ParseHeading(line); ok && h.Level == 2 && strings.EqualFold(h.Text, "Notes") {
+			break
+		}
+		t, ok := Parse(line)
+		if !ok || t.Legacy == "" {
+			continue
+		}
+		lines[i] = unlegacy(line, t)
+		changes = append(changes, Change{Line: i + 1, Old: line, New: lines[i]})
+	}
+	if len(changes) == 0 {
+		return text, nil
+	}
+	out := strings.Join(lines, nl)
+	if strings.HasSuffix(text, "\n") {
+		out += nl
+	}
+	return out, changes
+}
+
+// unlegacy rewrites line, a legacy hashtag task parsed as t: the checkbox,
+// added when missing, gets t's status and the hashtag goes. The rest of the
+// line stays as written.
+func unlegacy(line string, t Task) string {
+	var head, text string
+	if m := taskLine.FindStringSubmatchIndex(line); m != nil {
+		head = line[:m[6]-1] + t.Status.Checkbox() + line[m[7]+1:m[8]]
+		text = line[m[8]:]
+	} else {
+		m := listItem.FindStringSubmatchIndex(line)
+		head = line[:m[6]] + t.Status.Checkbox() + " "
+		text = line[m[6]:]
+	}
+	end := len(text)
+	if id := blockID.FindStringIndex(text); id != nil {
+		end = id[0]
+	}
+	l := legacyRe.FindStringIndex(text[:end])
+	return head + text[:l[0]] + text[end:]
+}
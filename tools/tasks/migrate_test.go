@@ -0,0 +1,60 @@
+package tasks
+
+import "testing"
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+		changes        int
+	}{
+		{
+			name:    "checkbox with hashtag",
+			in:      "- [ ] Ship the beta `#in-progress`\n- [x] Kickoff `#done`\n- [ ] Plan #open\n",
+			want:    "- [/] Ship the beta\n- [x] Kickoff\n- [ ] Plan\n",
+			changes: 3,
+		},
+		{
+			name:    "no checkbox",
+			in:      "  - Write the docs #done ^t-1a2b\n",
+			want:    "  - [x] Write the docs ^t-1a2b\n",
+			changes: 1,
+		},
+		{
+			name:    "the rest of the line stays as written",
+			in:      "- Write (from: [[X]]) the docs #open\n*   [ ]  Ship  it  `#done`   ^t-0001\n",
+			want:    "- [ ] Write (from: [[X]]) the docs\n*   [x]  Ship  it   ^t-0001\n",
+			changes: 2,
+		},
+		{
+			name: "hashtag inside the text",
+			in:   "- [/] Fix the #done tag handling\n- [ ] Fix the #done tag handling\n",
+			want: "- [/] Fix the #done tag handling\n- [ ] Fix the #done tag handling\n",
+		},
+		{
+			name: "checkbox status wins",
+			in:   "- [/] Review `#done`\n",
+			want: "- [/] Review `#done`\n",
+		},
+		{
+			name:    "fences and notes left alone",
+			in:      "- [ ] A #open\n```\n- [ ] B #done\n```\n\n## Notes\n\n- [ ] C #done\n",
+			want:    "- [ ] A\n```\n- [ ] B #done\n```\n\n## Notes\n\n- [ ] C #done\n",
+			changes: 1,
+		},
+		{
+			name:    "CRLF",
+			in:      "- [ ] A `#done`\r\n",
+			want:    "- [x] A\r\n",
+			changes: 1,
+		},
+	}
+	for _, tt := range tests {
+		got, changes := Migrate(tt.in)
+		if got != tt.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.name, got, tt.want)
+		}
+		if len(changes) != tt.changes {
+			t.Errorf("%s: %d change(s), want %d", tt.name, len(changes), tt.changes)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package tasks
+
+// Status is the character between a task's checkbox brackets.
+type Status byte
+
+// The seven task states of requirement 1.5.8.
+const (
+	Todo       Status = ' '
+	InProgress Status = '/'
+	Blocked    Status = '!'
+	Paused     Status = '?'
+	Backlog    Status = 'I'
+	NotDoing   Status = '-'
+	Done       Status = 'x'
+)
+
+// Statuses lists every valid status in display order.
+var Statuses = []Status{Todo, InProgress, Blocked, Paused, Backlog, NotDoing, Done}
+
+// Group is the project file section a status belongs in (requirement 1.5.4).
+type Group int
+
+const (
+	Active Group = iota
+	Inactive
+	Finished
+)
+
+// Groups lists the sections in the order they appear in a project file.
+var Groups = []Group{Active, Inactive, Finished}
+
+// String returns the section heading text: "Active", "Inactive" or "Done".
+func (g Group) String() string {
+	switch g {
+	case Active:
+		return "Active"
+	case Inactive:
+		return "Inactive"
+	case Finished:
+		return "Done"
+	}
+	return "Unknown"
+}
+
+// Valid reports whether s is one of the seven known states.
+func (s Status) Valid() bool {
+	for _, v := range Statuses {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Group returns the section s belongs in. Unknown states count as Active so
+// they stay visible.
+func (s Status) Group() Group {
+	switch s {
+	case Blocked, Paused, Backlog, NotDoing:
+		return Inactive
+	case Done:
+		return Finished
+	}
+	return Active
+}
+
+// Checkbox returns the "[x]" form of s.
+func (s Status) Checkbox() string {
+	return "[" + string(s) + "]"
+}
+
+// String returns the human name of s, e.g. "In Progress".
+func (s Status) String() string {
+	switch s {
+	case Todo:
+		return "To Do"
+	case InProgress:
+		return "In Progress"
+	case Blocked:
+		return "Blocked"
+	case Paused:
+		return "Paused"
+	case Backlog:
+		return "Backlog"
+	case NotDoing:
+		return "Not Doing"
+	case Done:
+		return "Done"
+	}
+	return "Unknown [" + string(s) + "]"
+}
+
+// legacyTags maps the hashtag statuses documented in FEATURES.md before the
+// checkbox states were introduced.
+var legacyTags = map[string]Status{
+	"open":        Todo,
+	"in-progress": InProgress,
+	"done":        Done,
+}
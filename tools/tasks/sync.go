@@ -0,0 +1,664 @@
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+)
+
+// SnapshotFile is where pull records what it pulled, relative to the
+// layout's state folder. Sync merges against it.
+const SnapshotFile = "tasks/last-pull.json"
+
+// HistoryFile is the log of the task changes sync pushed to the project
+// files, one JSON Event per line, relative to the layout's state folder.
+const HistoryFile = "tasks/history.jsonl"
+
+// Snapshot is the state of every project at the last pull: the common
+// ancestor of MASTER.md and the project files in a three-way merge.
+type Snapshot struct {
+	Projects []SnapshotProject `json:"projects"`
+}
+
+// SnapshotProject is one project as pulled.
+type SnapshotProject struct {
+	Path  string         `json:"path"`
+	Tag   string         `json:"tag"`
+	Hash  string         `json:"hash"`
+	Tasks []SnapshotTask `json:"tasks"`
+}
+
+// SnapshotTask is one task as pulled.
+type SnapshotTask struct {
+	ID     string `json:"id,omitempty"`
+	Status string `json:"status"`
+	Text   string `json:"text"`
+	Source string `json:"source,omitempty"`
+	Meta   *Meta  `json:"meta,omitempty"`
+}
+
+// NewSnapshot records projects as pulled.
+func NewSnapshot(projects []*Project) *Snapshot {
+	s := &Snapshot{Projects: []SnapshotProject{}}
+	for _, p := range projects {
+		sp := SnapshotProject{Path: p.Path, Tag: p.Tag, Hash: p.Hash, Tasks: []SnapshotTask{}}
+		for _, t := range p.Tasks {
+			st := SnapshotTask{ID: t.ID, Status: string(t.Status), Text: t.Text, Source: t.Source}
+			if !t.Meta.IsZero() {
+				m := t.Meta
+				st.Meta = &m
+			}
+			sp.Tasks = append(sp.Tasks, st)
+		}
+		s.Projects = append(s.Projects, sp)
+	}
+	return s
+}
+
+// Marshal returns s as indented JSON.
+func (s *Snapshot) Marshal() []byte {
+	data, _ := json.MarshalIndent(s, "", "  ")
+	return append(data, '\n')
+}
+
+// ReadSnapshot loads the snapshot at path. A missing file is an empty
+// snapshot: every task then counts as new on both sides.
+func ReadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.ToSlash(path), err)
+	}
+	return &s, nil
+}
+
+func (s *Snapshot) project(path string) *SnapshotProject {
+	for i := range s.Projects {
+		if s.Projects[i].Path == path {
+			return &s.Projects[i]
+		}
+	}
+	return nil
+}
+
+// MasterTask is one task line of MASTER.md.
+type MasterTask struct {
+	// Task has the project tag and the trailing project link removed from
+	// its Text.
+	Task
+	// Project is the project the line belongs to, by its tag or, for a
+	// line written without one, the "## Project" section it sits in; nil
+	// when neither names a known project.
+	Project *Project
+}
+
+// ParseMaster reads the task lines of MASTER.md, written by Master and
+// edited since, and attributes each to one of projects. The summary table
+// and a "## Notes" section are not tasks.
+func ParseMaster(text string, projects []*Project) []MasterTask {
+	lines := markdown.Lines(text)
+	fenced := markdown.InFence(lines)
+	byHeading := map[string]*Project{}
+	for _, p := range projects {
+		byHeading[strings.ToLower(p.Name)] = p
+		byHeading[strings.ToLower(p.Name+" ("+p.Domain+")")] = p
+	}
+	end := len(lines)
+	for i, line := range lines {
+		if h, ok := markdown.ParseHeading(line); ok && !fenced[i] && h.Level == 2 && strings.EqualFold(h.Text, "Notes") {
+			end = i
+			break
+		}
+	}
+
+	var out []MasterTask
+	var section *Project
+	for i := 0; i < end; i++ {
+		if fenced[i] {
+			continue
+		}
+		if h, ok := markdown.ParseHeading(lines[i]); ok {
+			if h.Level <= 2 {
+				section = byHeading[strings.ToLower(h.Text)]
+			}
+			continue
+		}
+		t, ok := Parse(lines[i])
+		if !ok {
+			continue
+		}
+		t.Line = i + 1
+		mt := MasterTask{Task: t, Project: section}
+		if m := trailingLink.FindStringSubmatchIndex(t.Text); m != nil {
+			target := t.Text[m[2]:m[3]]
+			for _, p := range projects {
+				if strings.EqualFold(p.Link, target) {
+					mt.Text = strings.TrimSpace(t.Text[:m[0]])
+					mt.Project = p
+				}
+			}
+		}
+		for _, p := range projects {
+			if t.HasTag(p.Tag) {
+				mt.Project = p
+				break
+			}
+		}
+		if mt.Project != nil {
+			mt.Text = withoutTag(mt.Text, mt.Project.Tag)
+		}
+		mt.Tags = Tags(mt.Text)
+		out = append(out, mt)
+	}
+	return out
+}
+
+var trailingLink = regexp.MustCompile(`[ \t]*\[\[([^\]|#]+)(?:[|#][^\]]*)?\]\][ \t]*$`)
+
+// withoutTag removes every #tag token from text.
+func withoutTag(text, tag string) string {
+	re := regexp.MustCompile(`(^|\s)#` + regexp.QuoteMeta(tag) + `(\s|$)`)
+	for {
+		out := re.ReplaceAllString(text, "$1$2")
+		if out == text {
+			return strings.Join(strings.Fields(out), " ")
+		}
+		text = out
+	}
+}
+
+// UpdateKind is what an Update does to a project file.
+type UpdateKind string
+
+const (
+	// SetStatus changes the task's checkbox to Status, moving it to the
+	// section of its new group (requirement 1.5.9), and its text and
+	// metadata to Text and Meta when those are set.
+	SetStatus UpdateKind = "status"
+	// AddTask appends a task written in MASTER.md, or moved there from
+	// another project, to the section of its status.
+	AddTask UpdateKind = "add"
+	// RemoveTask deletes the task and its subtasks: one moved to another
+	// project in MASTER.md, or a deletion the user confirmed.
+	RemoveTask UpdateKind = "remove"
+	// Conflict is a task whose status or text changed differently in
+	// MASTER.md and in the project file since the last pull (requirement
+	// 1.5.7). Status and Text are the MASTER.md side.
+	Conflict UpdateKind = "conflict"
+	// Tombstone is a task deleted from MASTER.md that the project file
+	// still has (requirement 1.5.8). It is never applied as it stands:
+	// the user archives, restores or deletes the task.
+	Tombstone UpdateKind = "tombstone"
+)
+
+// Update is one change to push to a project file.
+type Update struct {
+	Kind UpdateKind
+	// Task is the project's task, as now in the file; for AddTask, the
+	// task to add.
+	Task Task
+	// Status is the status MASTER.md gives the task, Text its text when
+	// reworded there, "" otherwise, and Meta its Tasks metadata when
+	// changed there or by Complete, nil otherwise.
+	Status Status
+	Text   string
+	Meta   *Meta
+	// Base is the status at the last pull.
+	Base Status
+}
+
+// Sync is the merge result of one project.
+type Sync struct {
+	Project *Project
+	// Changed is set when the project file changed since the last pull.
+	Changed bool
+	Updates []Update
+}
+
+// Merge three-way merges MASTER.md's tasks, the snapshot of the last pull
+// and the project files, and returns the updates each project needs
+// (requirements 1.5.6 to 1.5.9). Tasks match by block ID, so a task
+// reworded in MASTER.md or moved under another project's tag stays the
+// same task; tasks without an ID yet match by text and "(from: ...)"
+// source within their project. A MASTER.md line repeating an ID is a
+// copy: dropped when its text is the same, a new task otherwise. Changes
+// made only in a project file need no update; the next pull picks them
+// up. Master tasks no project claims come back separately.
+func Merge(base *Snapshot, master []MasterTask, projects []*Project) (syncs []*Sync, orphans []MasterTask) {
+	owner := map[string]*Project{}
+	for _, p := range projects {
+		for _, t := range p.Tasks {
+			if t.ID != "" && owner[t.ID] == nil {
+				owner[t.ID] = p
+			}
+		}
+	}
+	byProject := map[*Project][]MasterTask{}
+	// moved holds the tasks MASTER.md puts under another project than
+	// the one whose file has them.
+	moved := map[string]bool{}
+	seen := map[string]string{}
+	for _, mt := range master {
+		if mt.ID != "" {
+			if text, dup := seen[mt.ID]; dup {
+				if text == mt.Text {
+					continue
+				}
+				mt.ID = ""
+			} else {
+				seen[mt.ID] = mt.Text
+			}
+		}
+		if mt.Project == nil {
+			orphans = append(orphans, mt)
+			continue
+		}
+		if p := owner[mt.ID]; mt.ID != "" && p != nil && p != mt.Project {
+			moved[mt.ID] = true
+		}
+		byProject[mt.Project] = append(byProject[mt.Project], mt)
+	}
+
+	for _, p := range projects {
+		s := &Sync{Project: p}
+		var bt []SnapshotTask
+		if bp := base.project(p.Path); bp != nil {
+			s.Changed = bp.Hash != p.Hash
+			for _, t := range bp.Tasks {
+				if len(t.Status) == 1 {
+					bt = append(bt, t)
+				}
+			}
+		}
+		mts := byProject[p]
+		bx := newIndex(len(bt), func(i int) (string, string) { return bt[i].ID, key(bt[i].Text, bt[i].Source, p.Tag) })
+		mx := newIndex(len(mts), func(i int) (string, string) {
+			if moved[mts[i].ID] {
+				return "", "\x00moved" // never matched here: added below
+			}
+			return mts[i].ID, key(mts[i].Text, mts[i].Source, p.Tag)
+		})
+		for _, t := range p.Tasks {
+			k := key(t.Text, t.Source, p.Tag)
+			if moved[t.ID] && owner[t.ID] == p {
+				bx.match(t.ID, k)
+				s.Updates = append(s.Updates, Update{Kind: RemoveTask, Task: t, Status: t.Status})
+				continue
+			}
+			bi, inBase := bx.match(t.ID, k)
+			mi, inMaster := mx.match(t.ID, k)
+			if !inBase {
+				continue // added to the project since the last pull
+			}
+			b := bt[bi]
+			if !inMaster {
+				s.Updates = append(s.Updates, Update{Kind: Tombstone, Task: t, Status: t.Status, Base: Status(b.Status[0])})
+				continue
+			}
+			m := mts[mi]
+			status, statusChanged, statusClash := merge3(Status(b.Status[0]), m.Status, t.Status)
+			btext, mtext, ptext := withoutTag(b.Text, p.Tag), withoutTag(m.Text, p.Tag), withoutTag(t.Text, p.Tag)
+			_, textChanged, textClash := merge3(btext, mtext, ptext)
+			var bmeta Meta
+			if b.Meta != nil {
+				bmeta = *b.Meta
+			}
+			_, metaChanged, metaClash := merge3(bmeta, m.Meta, t.Meta)
+			u := Update{Kind: SetStatus, Task: t, Status: status, Base: Status(b.Status[0])}
+			if textChanged || textClash {
+				u.Text = m.Text
+			}
+			if metaChanged || metaClash {
+				meta := m.Meta
+				u.Meta = &meta
+			}
+			switch {
+			case statusClash || textClash || metaClash:
+				u.Kind, u.Status = Conflict, m.Status
+			case !statusChanged && !textChanged && !metaChanged:
+				continue
+			}
+			s.Updates = append(s.Updates, u)
+		}
+		// Master tasks beyond what the project has: new in MASTER.md,
+		// moved there from another project, or deleted from the project
+		// since the last pull.
+		for i, mt := range mts {
+			if mx.used[i] {
+				continue
+			}
+			if !moved[mt.ID] {
+				if _, inBase := bx.match(mt.ID, key(mt.Text, mt.Source, p.Tag)); inBase {
+					continue
+				}
+			}
+			t := mt.Task
+			t.Indent, t.Marker, t.Legacy = "", "-", ""
+			s.Updates = append(s.Updates, Update{Kind: AddTask, Task: t, Status: t.Status})
+		}
+		if len(s.Updates) > 0 {
+			syncs = append(syncs, s)
+		}
+	}
+	return syncs, orphans
+}
+
+// merge3 merges one value of a task: out is the value to write, changed
+// set when that is MASTER.md's change, clash when both sides changed it
+// differently.
+func merge3[T comparable](base, master, project T) (out T, changed, clash bool) {
+	switch {
+	case master == base || master == project:
+		return project, false, false
+	case project == base:
+		return master, true, false
+	}
+	return project, false, true
+}
+
+// index matches tasks by ID, falling back to text and source for the
+// tasks without one. Each task matches once.
+type index struct {
+	byID  map[string]int
+	byKey map[string][]int
+	used  []bool
+}
+
+func newIndex(n int, at func(int) (id, key string)) *index {
+	x := &index{byID: map[string]int{}, byKey: map[string][]int{}, used: make([]bool, n)}
+	for i := 0; i < n; i++ {
+		id, k := at(i)
+		if id == "" {
+			x.byKey[k] = append(x.byKey[k], i)
+		} else if _, dup := x.byID[id]; !dup {
+			x.byID[id] = i
+		}
+	}
+	return x
+}
+
+// match marks and returns the unmatched task with id, or else the first
+// unmatched task without an ID that has key.
+func (x *index) match(id, key string) (int, bool) {
+	if i, ok := x.byID[id]; ok && id != "" && !x.used[i] {
+		x.used[i] = true
+		return i, true
+	}
+	for _, i := range x.byKey[key] {
+		if !x.used[i] {
+			x.used[i] = true
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+func key(text, source, tag string) string {
+	return withoutTag(text, tag) + "\x00" + source
+}
+
+// Event is one line of the history log: a change pushed to a task,
+// which the task's ID follows across rewording and moves.
+type Event struct {
+	Time    string     `json:"time"`
+	ID      string     `json:"id,omitempty"`
+	Project string     `json:"project"`
+	Kind    UpdateKind `json:"kind"`
+	// From and To are the statuses before and after; Was is the text
+	// before a rewording. Meta is the task's Tasks metadata when the
+	// change set it.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	Text string `json:"text"`
+	Was  string `json:"was,omitempty"`
+	Meta *Meta  `json:"meta,omitempty"`
+}
+
+// History renders updates, applied to p at t, as history log lines.
+// Conflict and Tombstone updates left unresolved are not events.
+func History(p *Project, updates []Update, t time.Time) []byte {
+	var b strings.Builder
+	for _, u := range updates {
+		ev := Event{Time: t.Format(time.RFC3339), ID: u.Task.ID, Project: p.Path, Kind: u.Kind, Text: u.Task.Text}
+		switch u.Kind {
+		case SetStatus:
+			ev.From, ev.To = string(u.Task.Status), string(u.Status)
+			if u.Text != "" && u.Text != u.Task.Text {
+				ev.Text, ev.Was = u.Text, u.Task.Text
+			}
+			if u.Meta != nil && *u.Meta != u.Task.Meta {
+				ev.Meta = u.Meta
+			}
+		case AddTask:
+			ev.To = string(u.Status)
+			if !u.Task.Meta.IsZero() {
+				m := u.Task.Meta
+				ev.Meta = &m
+			}
+		case RemoveTask:
+			ev.From = string(u.Task.Status)
+		default:
+			continue
+		}
+		data, _ := json.Marshal(ev)
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
+
+// Apply returns the project file with updates applied. Conflict and
+// Tombstone updates are left out; resolve them into SetStatus, RemoveTask
+// or nothing first. A top-level task whose group changes moves, with its
+// subtasks, to the end of the Active, Inactive or Done section it now
+// belongs in; subtasks only change their checkbox. Sections that do not
+// exist yet are created.
+func (p *Project) Apply(updates []Update) []byte {
+	nl := "\n"
+	if strings.Contains(p.text, "\r\n") {
+		nl = "\r\n"
+	}
+	lines := markdown.Lines(p.text)
+	remove := map[int]bool{}
+	type insert struct {
+		group Group
+		lines []string
+	}
+	var inserts []insert
+	// Checkboxes and text change in place first, so a task moving to
+	// another section moves as updated.
+	for _, u := range updates {
+		i := u.Task.Line - 1
+		if u.Kind != SetStatus {
+			continue
+		}
+		if t, ok := Parse(lines[i]); ok && (u.Text != "" || u.Meta != nil) {
+			if u.Text != "" {
+				t.Text = u.Text
+			}
+			if u.Meta != nil {
+				t.Meta = *u.Meta
+			}
+			lines[i] = t.String()
+		}
+		lines[i] = setCheckbox(lines[i], u.Status)
+	}
+	for _, u := range updates {
+		i := u.Task.Line - 1
+		switch u.Kind {
+		case SetStatus:
+			from, ok := groupNamed(u.Task.Section)
+			if u.Task.Depth > 0 || !ok || from == u.Status.Group() {
+				continue
+			}
+			sub := subtree(lines, i)
+			var moved []string
+			for _, j := range sub {
+				moved = append(moved, strings.TrimPrefix(lines[j], u.Task.Indent))
+				remove[j] = true
+			}
+			inserts = append(inserts, insert{u.Status.Group(), moved})
+		case RemoveTask:
+			for _, j := range subtree(lines, i) {
+				remove[j] = true
+			}
+		case AddTask:
+			inserts = append(inserts, insert{u.Task.Status.Group(), []string{u.Task.String()}})
+		}
+	}
+	var kept []string
+	for i, l := range lines {
+		if !remove[i] {
+			kept = append(kept, l)
+		}
+	}
+	for _, in := range inserts {
+		kept = insertInSection(kept, in.group, in.lines)
+	}
+	out := strings.Join(kept, nl)
+	if strings.HasSuffix(p.text, "\n") {
+		out += nl
+	}
+	return []byte(out)
+}
+
+var checkbox = regexp.MustCompile(`^[ \t]*(?:[-*+]|\d+[.)])[ \t]+\[(.)\]`)
+
+// setCheckbox changes only the status character of a task line.
+func setCheckbox(line string, s Status) string {
+	if t, ok := Parse(line); ok && t.Legacy != "" {
+		// The hashtag would override the checkbox: write the task in
+		// checkbox form, as Migrate does.
+		t.Legacy, t.Status = "", s
+		return t.String()
+	}
+	m := checkbox.FindStringSubmatchIndex(line)
+	if m == nil {
+		return line
+	}
+	return line[:m[2]] + string(s) + line[m[3]:]
+}
+
+func groupNamed(heading string) (Group, bool) {
+	for _, g := range Groups {
+		if strings.EqualFold(heading, g.String()) {
+			return g, true
+		}
+	}
+	return 0, false
+}
+
+// subtree returns the indices of line i and of the lines indented below
+// it, up to the first line at or left of its indentation.
+func subtree(lines []string, i int) []int {
+	w := width(leading(lines[i]))
+	out := []int{i}
+	for j := i + 1; j < len(lines); j++ {
+		if strings.TrimSpace(lines[j]) == "" {
+			continue
+		}
+		if width(leading(lines[j])) <= w {
+			break
+		}
+		out = append(out, j)
+	}
+	// Blank lines inside the subtree go with it; trailing ones stay.
+	full := []int{}
+	for j := i; j <= out[len(out)-1]; j++ {
+		full = append(full, j)
+	}
+	return full
+}
+
+func leading(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
+
+// insertInSection appends add to the end of the section headed by group,
+// before its trailing blank lines, creating the section after the
+// previous group's section (or before "## Notes", or at the end) when the
+// file has none.
+func insertInSection(lines []string, g Group, add []string) []string {
+	fenced := markdown.InFence(lines)
+	type span struct{ start, end, level int }
+	sections := map[Group]span{}
+	notes := len(lines)
+	level := 3
+	for i := 0; i < len(lines); i++ {
+		h, ok := markdown.ParseHeading(lines[i])
+		if !ok || fenced[i] {
+			continue
+		}
+		if h.Level == 2 && strings.EqualFold(h.Text, "Notes") {
+			notes = i
+			break
+		}
+		gr, ok := groupNamed(h.Text)
+		if !ok {
+			continue
+		}
+		if _, dup := sections[gr]; dup {
+			continue
+		}
+		level = h.Level
+		end := len(lines)
+		for j := i + 1; j < len(lines); j++ {
+			if hj, ok := markdown.ParseHeading(lines[j]); ok && !fenced[j] && hj.Level <= h.Level {
+				end = j
+				break
+			}
+		}
+		sections[gr] = span{i, end, h.Level}
+	}
+
+	at := -1
+	if s, ok := sections[g]; ok {
+		at = s.end
+		for at > s.start+1 && strings.TrimSpace(lines[at-1]) == "" {
+			at--
+		}
+		return splice(lines, at, add)
+	}
+	// A new section: after the nearest earlier group, else before the
+	// nearest later one, else before ## Notes.
+	for gr := g - 1; gr >= Active && at < 0; gr-- {
+		if s, ok := sections[gr]; ok {
+			at = s.end
+		}
+	}
+	for gr := g + 1; gr <= Finished && at < 0; gr++ {
+		if s, ok := sections[gr]; ok {
+			at = s.start
+		}
+	}
+	if at < 0 {
+		at = notes
+	}
+	for at > 0 && strings.TrimSpace(lines[at-1]) == "" {
+		at--
+	}
+	block := []string{"", strings.Repeat("#", level) + " " + g.String(), ""}
+	block = append(block, add...)
+	if at < len(lines) && strings.TrimSpace(lines[at]) != "" {
+		block = append(block, "")
+	}
+	return splice(lines, at, block)
+}
+
+func splice(lines []string, at int, add []string) []string {
+	out := append([]string{}, lines[:at]...)
+	out = append(out, add...)
+	return append(out, lines[at:]...)
+}
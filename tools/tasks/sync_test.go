@@ -0,0 +1,185 @@
+package tasks
+
+import (
+	"strings"
+	"testing"
+)
+
+// project reads a project file of the Work domain from text.
+func project(t *testing.T, file, text string) *Project {
+	t.Helper()
+	p := &Project{Path: "Domains/Work/01_PROJECTS/" + file, Domain: "Work", Tag: ProjectTag(file), Link: strings.TrimSuffix(file, ".md")}
+	p, err := p.WithText([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+const apiProject = `---
+name: API
+---
+
+## Active
+
+- [ ] Write the spec ^t-0001
+- [/] Build the client ^t-0002
+  - [ ] Retry on 503 ^t-0003
+
+## Done
+
+- [x] Kickoff ^t-0004
+
+## Notes
+
+- [ ] not a task of the list
+`
+
+// editMaster pulls projects into MASTER.md, lets edit change it and
+// returns what Merge makes of it against the files now.
+func editMaster(t *testing.T, pulled []*Project, edit func(string) string, now []*Project) ([]*Sync, []MasterTask) {
+	t.Helper()
+	base := NewSnapshot(pulled)
+	master := edit(string(Master(pulled, ByFile)))
+	return Merge(base, ParseMaster(master, now), now)
+}
+
+func replace(old, new string) func(string) string {
+	return func(s string) string {
+		if !strings.Contains(s, old) {
+			panic("MASTER.md has no " + old)
+		}
+		return strings.Replace(s, old, new, 1)
+	}
+}
+
+func TestMergeAndApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(string) string
+		kinds []UpdateKind
+		want  string // the project file after Apply; "" when unchanged
+	}{
+		{
+			name: "nothing changed",
+			edit: func(s string) string { return s },
+		},
+		{
+			name:  "done moves to Done",
+			edit:  replace("- [ ] Write the spec", "- [x] Write the spec"),
+			kinds: []UpdateKind{SetStatus},
+			want: "---\nname: API\n---\n\n## Active\n\n- [/] Build the client ^t-0002\n  - [ ] Retry on 503 ^t-0003\n\n" +
+				"## Done\n\n- [x] Kickoff ^t-0004\n- [x] Write the spec ^t-0001\n\n## Notes\n\n- [ ] not a task of the list\n",
+		},
+		{
+			name:  "a parent moves with its subtasks",
+			edit:  replace("- [/] Build the client", "- [-] Build the client"),
+			kinds: []UpdateKind{SetStatus},
+			want: "---\nname: API\n---\n\n## Active\n\n- [ ] Write the spec ^t-0001\n\n" +
+				"## Inactive\n\n- [-] Build the client ^t-0002\n  - [ ] Retry on 503 ^t-0003\n\n" +
+				"## Done\n\n- [x] Kickoff ^t-0004\n\n## Notes\n\n- [ ] not a task of the list\n",
+		},
+		{
+			name:  "a subtask only changes its checkbox",
+			edit:  replace("- [ ] Retry on 503", "- [x] Retry on 503"),
+			kinds: []UpdateKind{SetStatus},
+			want:  strings.Replace(apiProject, "  - [ ] Retry", "  - [x] Retry", 1),
+		},
+		{
+			name:  "reworded by ID",
+			edit:  replace("Write the spec", "Write the API spec"),
+			kinds: []UpdateKind{SetStatus},
+			want:  strings.Replace(apiProject, "Write the spec", "Write the API spec", 1),
+		},
+		{
+			name:  "added in MASTER.md",
+			edit:  replace("### Inactive", "- [ ] Review the docs #Api\n\n### Inactive"),
+			kinds: []UpdateKind{AddTask},
+			want:  strings.Replace(apiProject, "^t-0003\n", "^t-0003\n- [ ] Review the docs\n", 1),
+		},
+		{
+			name: "deleted from MASTER.md",
+			edit: func(s string) string {
+				i := strings.Index(s, "- [ ] Write the spec")
+				return s[:i] + s[i+strings.Index(s[i:], "\n")+1:]
+			},
+			kinds: []UpdateKind{Tombstone},
+		},
+	}
+	for _, tt := range tests {
+		p := project(t, "PROJECT_API.md", apiProject)
+		syncs, orphans := editMaster(t, []*Project{p}, tt.edit, []*Project{p})
+		if len(orphans) > 0 {
+			t.Errorf("%s: orphans %+v", tt.name, orphans)
+		}
+		var kinds []UpdateKind
+		var updates []Update
+		for _, s := range syncs {
+			for _, u := range s.Updates {
+				kinds = append(kinds, u.Kind)
+				updates = append(updates, u)
+			}
+		}
+		if strings.Join(kindStrings(kinds), ",") != strings.Join(kindStrings(tt.kinds), ",") {
+			t.Errorf("%s: updates %v, want %v", tt.name, kinds, tt.kinds)
+			continue
+		}
+		if tt.want == "" {
+			continue
+		}
+		if got := string(p.Apply(updates)); got != tt.want {
+			t.Errorf("%s: Apply:\ngot  %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func kindStrings(ks []UpdateKind) []string {
+	out := make([]string, len(ks))
+	for i, k := range ks {
+		out[i] = string(k)
+	}
+	return out
+}
+
+func TestMergeConflictsAndMoves(t *testing.T) {
+	pulled := project(t, "PROJECT_API.md", apiProject)
+	web := project(t, "PROJECT_WEB.md", "## Active\n\n- [ ] Launch ^t-0101\n")
+
+	// Both sides changed the spec's status, differently.
+	now := project(t, "PROJECT_API.md", strings.Replace(apiProject, "- [ ] Write the spec", "- [!] Write the spec", 1))
+	syncs, _ := editMaster(t, []*Project{pulled, web}, replace("- [ ] Write the spec", "- [x] Write the spec"), []*Project{now, web})
+	if len(syncs) != 1 || len(syncs[0].Updates) != 1 {
+		t.Fatalf("conflict: syncs %+v", syncs)
+	}
+	if u := syncs[0].Updates[0]; u.Kind != Conflict || u.Status != Done || u.Base != Todo || !syncs[0].Changed {
+		t.Errorf("conflict: update %+v, changed %v", u, syncs[0].Changed)
+	}
+
+	// Only the project changed it: the next pull takes it, no update.
+	syncs, _ = editMaster(t, []*Project{pulled, web}, func(s string) string { return s }, []*Project{now, web})
+	if len(syncs) != 0 {
+		t.Errorf("project-only change: syncs %+v", syncs)
+	}
+
+	// Retagged in MASTER.md: removed from API, added to Web with its ID.
+	syncs, _ = editMaster(t, []*Project{pulled, web}, replace("Write the spec #Api", "Write the spec #Web"), []*Project{pulled, web})
+	got := map[string]UpdateKind{}
+	for _, s := range syncs {
+		for _, u := range s.Updates {
+			got[s.Project.Tag+" "+u.Task.ID] = u.Kind
+		}
+	}
+	if len(got) != 2 || got["Api t-0001"] != RemoveTask || got["Web t-0001"] != AddTask {
+		t.Errorf("retag: updates %v", got)
+	}
+
+	// A copied line with the same text is dropped; a line nobody claims
+	// is an orphan.
+	syncs, orphans := editMaster(t, []*Project{pulled}, func(s string) string {
+		line := pulled.MasterLine(pulled.Tasks[0])
+		return strings.Replace(s, line, line+"\n"+line, 1) + "\n## Elsewhere\n\n- [ ] Nobody's task\n"
+	}, []*Project{pulled})
+	if len(syncs) != 0 || len(orphans) != 1 || orphans[0].Text != "Nobody's task" {
+		t.Errorf("copies and orphans: syncs %+v, orphans %+v", syncs, orphans)
+	}
+}
@@ -0,0 +1,238 @@
+// Package tasks parses and rewrites the task lines of PAL project files.
+//
+// A task is a markdown list item with a checkbox, as under a project file's
+// Active heading:
+//
+//	## Active
+//	- [/] Draft the onboarding email #writing (from: [[Client Kickoff]]) ^t-7f3a
+//
+// The checkbox character is the task's Status (requirement 1.5.8). Tags are
+// Obsidian hashtags anywhere in the text, and "(from: [[Note]])" records the
+// note a task was extracted from by distribute_notes (requirement 1.4.21).
+// Tasks nest by indentation like any markdown list. The Obsidian Tasks
+// plugin's emoji fields, such as "📅 2026-10-20" or "🔁 every week", are
+// its Meta. A block ID at the end of the line, "^t-7f3a", identifies the
+// task for sync (see IDs).
+//
+// Older vaults mark status with a hashtag instead (`#open`,
+// `#in-progress`, `#done`); Parse recognises that form and Migrate rewrites
+// it into checkbox form.
+//
+// ReadProjects collects the tasks of every PROJECT_*.md file and Master
+// renders them as the vault's MASTER.md, where each task carries its
+// project's #ProjectName tag and a link back to the project file. Each pull
+// saves a Snapshot; Merge compares MASTER.md, the snapshot and the project
+// files to find the edits made in MASTER.md since, and Project.Apply writes
+// them back.
+package tasks
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+)
+
+// Task is one checkbox list item.
+type Task struct {
+	// Indent is the leading whitespace and Marker the list marker ("-",
+	// "*", "+" or "1.").
+	Indent string
+	Marker string
+	Status Status
+	// Text is everything after the checkbox, minus the "(from: ...)"
+	// backlink, the Tasks metadata, the block ID and any legacy status
+	// hashtag.
+	Text string
+	// Tags are the hashtags in Text, without the leading '#', in order.
+	Tags []string
+	// Source is the target of a "(from: [[Source Note]])" backlink.
+	Source string
+	// Meta is the Obsidian Tasks metadata: priority, recurrence and dates.
+	Meta
+	// ID is the Obsidian block ID ending the line, without '^': the task's
+	// identity across rewording and moves, e.g. "t-7f3a".
+	ID string
+	// Legacy is the hashtag status ("open", "in-progress", "done") when the
+	// line used the pre-checkbox form: the hashtag, backticks optional, ends
+	// a list item that has no checkbox or an empty one ("[x]" with #done).
+	// Status already reflects it.
+	Legacy string
+
+	// Line is the 1-based line number, Depth the nesting level (0 for top
+	// level) and Parent the index of the enclosing task in the slice
+	// returned by ParseDocument, or -1.
+	Line   int
+	Depth  int
+	Parent int
+	// Section is the text of the nearest heading above the task, e.g.
+	// "Active".
+	Section string
+	// Raw is the line as read.
+	Raw string
+}
+
+var (
+	taskLine  = regexp.MustCompile(`^([ \t]*)([-*+]|\d+[.)])[ \t]+\[(.)\][ \t]?(.*)$`)
+	listItem  = regexp.MustCompile(`^([ \t]*)([-*+]|\d+[.)])[ \t]+(.*)$`)
+	tagRe     = regexp.MustCompile(`(^|[\s(])#([\p{L}\p{N}_][\p{L}\p{N}_/-]*)`)
+	blockID   = regexp.MustCompile(`(?:^|[ \t]+)\^([A-Za-z0-9-]+)[ \t]*$`)
+	sourceRe  = regexp.MustCompile(`[ \t]*\(from:[ \t]*\[\[([^\]]+)\]\][ \t]*\)`)
+	legacyRe  = regexp.MustCompile("(?:^|[ \t]+)(?:`#(open|in-progress|done)`|#(open|in-progress|done))[ \t]*$")
+	allDigits = regexp.MustCompile(`^\p{N}+$`)
+)
+
+// Parse parses a single line. ok is false when the line is not a task: a
+// list item with a checkbox, or ending in a legacy status hashtag.
+func Parse(line string) (t Task, ok bool) {
+	line = strings.TrimSuffix(line, "\r")
+	var text string
+	checkbox := true
+	if m := taskLine.FindStringSubmatch(line); m != nil {
+		t = Task{Indent: m[1], Marker: m[2], Status: Status(m[3][0]), Raw: line, Parent: -1}
+		text = m[4]
+	} else if m := listItem.FindStringSubmatch(line); m != nil {
+		t = Task{Indent: m[1], Marker: m[2], Status: Todo, Raw: line, Parent: -1}
+		text, checkbox = m[3], false
+	} else {
+		return Task{}, false
+	}
+	if t.Status == 'X' {
+		t.Status = Done
+	}
+
+	if id := blockID.FindStringSubmatchIndex(text); id != nil {
+		t.ID = text[id[2]:id[3]]
+		text = text[:id[0]]
+	}
+	if l := legacyRe.FindStringSubmatchIndex(text); l != nil {
+		var tag string
+		if l[2] >= 0 {
+			tag = text[l[2]:l[3]]
+		} else {
+			tag = text[l[4]:l[5]]
+		}
+		// A checkbox of its own wins over the hashtag, which is then
+		// just text.
+		if !checkbox || t.Status == Todo || t.Status == legacyTags[tag] {
+			t.Legacy, t.Status = tag, legacyTags[tag]
+			text = text[:l[0]]
+		}
+	}
+	if !checkbox && t.Legacy == "" {
+		return Task{}, false
+	}
+	text, t.Meta = parseMeta(text)
+	if s := sourceRe.FindStringSubmatch(text); s != nil {
+		t.Source = s[1]
+		text = sourceRe.ReplaceAllString(text, "")
+	}
+	t.Text = strings.TrimSpace(text)
+	t.Tags = Tags(t.Text)
+	return t, true
+}
+
+// Tags returns the hashtags in text, without '#', skipping purely numeric
+// ones the way Obsidian does.
+func Tags(text string) []string {
+	var tags []string
+	for _, m := range tagRe.FindAllStringSubmatch(text, -1) {
+		if !allDigits.MatchString(m[2]) {
+			tags = append(tags, m[2])
+		}
+	}
+	return tags
+}
+
+// HasTag reports whether t carries tag, ignoring case.
+func (t *Task) HasTag(tag string) bool {
+	tag = strings.TrimPrefix(tag, "#")
+	for _, x := range t.Tags {
+		if strings.EqualFold(x, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders t in checkbox form.
+func (t Task) String() string {
+	var b strings.Builder
+	b.WriteString(t.Indent)
+	b.WriteString(t.Marker)
+	b.WriteByte(' ')
+	b.WriteString(t.Status.Checkbox())
+	if t.Text != "" {
+		b.WriteByte(' ')
+		b.WriteString(t.Text)
+	}
+	if t.Source != "" {
+		b.WriteString(" (from: [[")
+		b.WriteString(t.Source)
+		b.WriteString("]])")
+	}
+	b.WriteString(t.Meta.String())
+	if t.ID != "" {
+		b.WriteString(" ^")
+		b.WriteString(t.ID)
+	}
+	return b.String()
+}
+
+// ParseDocument returns every task in text, skipping fenced code blocks, with
+// line numbers, nesting and section headings filled in.
+func ParseDocument(text string) []Task {
+	lines := markdown.Lines(text)
+	fenced := markdown.InFence(lines)
+	var out []Task
+	section := ""
+	// stack holds indices into out of the open ancestors.
+	var stack []int
+	for i, line := range lines {
+		if fenced[i] {
+			continue
+		}
+		if h, ok := markdown.ParseHeading(line); ok {
+			section = h.Text
+			stack = stack[:0]
+			continue
+		}
+		t, ok := Parse(line)
+		if !ok {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			// Any other unindented line ends the current list.
+			if line[0] != ' ' && line[0] != '\t' {
+				stack = stack[:0]
+			}
+			continue
+		}
+		t.Line = i + 1
+		t.Section = section
+		w := width(t.Indent)
+		for len(stack) > 0 && width(out[stack[len(stack)-1]].Indent) >= w {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) > 0 {
+			t.Parent = stack[len(stack)-1]
+			t.Depth = out[t.Parent].Depth + 1
+		}
+		stack = append(stack, len(out))
+		out = append(out, t)
+	}
+	return out
+}
+
+// width is the visual width of indentation, counting a tab as four columns.
+func width(indent string) int {
+	n := 0
+	for _, c := range indent {
+		if c == '\t' {
+			n += 4
+		} else {
+			n++
+		}
+	}
+	return n
+}
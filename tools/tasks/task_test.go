@@ -0,0 +1,102 @@
+package tasks
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		line   string
+		ok     bool
+		status Status
+		text   string
+		legacy string
+		source string
+		id     string
+		tags   []string
+	}{
+		{line: "- [ ] Write the spec", ok: true, status: Todo, text: "Write the spec"},
+		{line: "  * [/] Draft #writing", ok: true, status: InProgress, text: "Draft #writing", tags: []string{"writing"}},
+		{line: "1. [X] Ship it", ok: true, status: Done, text: "Ship it"},
+		{line: "- [!] Wait for logo (from: [[Kickoff]]) ^t-7f3a", ok: true, status: Blocked, text: "Wait for logo", source: "Kickoff", id: "t-7f3a"},
+		{line: "- [ ] Ticket #123 and #ops", ok: true, status: Todo, text: "Ticket #123 and #ops", tags: []string{"ops"}},
+		{line: "- [ ]", ok: true, status: Todo},
+		{line: "- plain item", ok: false},
+		{line: "Not a list [ ] line", ok: false},
+
+		// Legacy hashtag statuses count only at the end of a line whose
+		// checkbox is missing or empty.
+		{line: "- [ ] Ship the beta `#in-progress`", ok: true, status: InProgress, text: "Ship the beta", legacy: "in-progress"},
+		{line: "- [ ] Ship the beta #in-progress", ok: true, status: InProgress, text: "Ship the beta", legacy: "in-progress"},
+		{line: "- [x] Kickoff `#done`", ok: true, status: Done, text: "Kickoff", legacy: "done"},
+		{line: "- Write the docs #open", ok: true, status: Todo, text: "Write the docs", legacy: "open"},
+		{line: "- Write the docs `#done` ^t-1a2b", ok: true, status: Done, text: "Write the docs", legacy: "done", id: "t-1a2b"},
+		{line: "- [/] Fix the #done tag handling", ok: true, status: InProgress, text: "Fix the #done tag handling", tags: []string{"done"}},
+		{line: "- [ ] Fix the #done tag handling", ok: true, status: Todo, text: "Fix the #done tag handling", tags: []string{"done"}},
+		{line: "- [/] Review `#done`", ok: true, status: InProgress, text: "Review `#done`"},
+		{line: "- [x] Ship `#open`", ok: true, status: Done, text: "Ship `#open`"},
+		{line: "- Talk about #done things", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.line)
+		if ok != tt.ok {
+			t.Errorf("Parse(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if got.Status != tt.status || got.Text != tt.text || got.Legacy != tt.legacy || got.Source != tt.source || got.ID != tt.id {
+			t.Errorf("Parse(%q) = status %q text %q legacy %q source %q id %q, want %q %q %q %q %q",
+				tt.line, got.Status, got.Text, got.Legacy, got.Source, got.ID, tt.status, tt.text, tt.legacy, tt.source, tt.id)
+		}
+		if !reflect.DeepEqual(got.Tags, tt.tags) {
+			t.Errorf("Parse(%q) tags = %q, want %q", tt.line, got.Tags, tt.tags)
+		}
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	for _, line := range []string{
+		"- [ ] Write the spec",
+		"  - [/] Draft #writing (from: [[Kickoff]]) ^t-7f3a",
+		"1. [x] Ship it ^t-0001",
+		"- [!] Wait for logo (from: [[Brand Notes]])",
+	} {
+		tk, ok := Parse(line)
+		if !ok {
+			t.Fatalf("Parse(%q) failed", line)
+		}
+		if got := tk.String(); got != line {
+			t.Errorf("String() = %q, want %q", got, line)
+		}
+	}
+}
+
+func TestParseDocument(t *testing.T) {
+	text := "## Active\n- [ ] Parent\n  - [/] Child\n    - [ ] Grandchild\n- [ ] Sibling\n```\n- [ ] fenced\n```\n## Done\n- [x] Finished\n"
+	ts := ParseDocument(text)
+	type row struct {
+		text        string
+		line, depth int
+		parent      int
+		section     string
+	}
+	want := []row{
+		{"Parent", 2, 0, -1, "Active"},
+		{"Child", 3, 1, 0, "Active"},
+		{"Grandchild", 4, 2, 1, "Active"},
+		{"Sibling", 5, 0, -1, "Active"},
+		{"Finished", 10, 0, -1, "Done"},
+	}
+	if len(ts) != len(want) {
+		t.Fatalf("got %d tasks, want %d", len(ts), len(want))
+	}
+	for i, w := range want {
+		g := row{ts[i].Text, ts[i].Line, ts[i].Depth, ts[i].Parent, ts[i].Section}
+		if g != w {
+			t.Errorf("task %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Package validate checks one PAL component at a time against the rules
+// its create workflow writes it by, the deterministic half of the
+// validate_agent and validate_skill workflows (requirements 1.1.5, 2.0.3
+// to 2.0.6, 1.0.2 to 1.0.4 and 1.3.1 to 1.3.5).
+// Problems come back as audit findings, so they print and serialise the
+// way every other pal check does.
+package validate
+
+import (
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/agent"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/audit"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+// Labels reported by Agent.
+const (
+	BadHeader       = "BAD HEADER"
+	MissingDomain   = "MISSING DOMAIN"
+	MissingSection  = "MISSING SECTION"
+	BadCapability   = "BAD CAPABILITY"
+	MissingLocation = "MISSING LOCATION"
+	NotRouted       = "NOT ROUTED"
+	NoDelegate      = "NO DELEGATE"
+)
+
+// AgentFields are the only frontmatter keys an agent has (requirement
+// 2.0.3).
+var AgentFields = []string{"name", "description", "version", "domain"}
+
+// AgentSections are the titles of the eight numbered sections, in order
+// (requirement 2.0.4).
+var AgentSections = []string{
+	"Identity & Persona",
+	"Activation Protocol",
+	"Command Menu",
+	"How I Work",
+	"My Capabilities",
+	"Session State Model",
+	"Error Handling & Recovery",
+	"Operational Rules",
+}
+
+// commandMenuSection is the number of the section that must offer
+// *delegate (requirement 2.0.6).
+const commandMenuSection = 3
+
+var (
+	kebab  = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	semver = regexp.MustCompile(`^v?\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?$`)
+)
+
+// Agent validates the agent file at the vault-relative rel.
+func Agent(v *vault.Vault, rel string) ([]audit.Finding, error) {
+	data, err := os.ReadFile(v.Abs(rel))
+	if err != nil {
+		return nil, err
+	}
+	var out []audit.Finding
+	add := func(kind string, line int, msg string) {
+		out = append(out, audit.Finding{Audit: "agent", Kind: kind, Path: rel, Line: line, Message: msg})
+	}
+	a, err := agent.Parse(rel, data)
+	if a == nil {
+		return nil, err
+	}
+	if err != nil {
+		add(BadHeader, 1, "frontmatter does not parse: "+err.Error())
+	}
+
+	// Header: exactly the four fields, well formed.
+	if a.Frontmatter == nil && err == nil {
+		add(BadHeader, 1, "no YAML frontmatter; want "+strings.Join(AgentFields, ", "))
+	}
+	if a.Frontmatter != nil {
+		for _, k := range AgentFields {
+			if _, ok := a.Frontmatter[k]; !ok {
+				add(BadHeader, 1, "missing field "+k)
+			}
+		}
+		var extra []string
+		for k := range a.Frontmatter {
+			if !contains(AgentFields, k) {
+				extra = append(extra, k)
+			}
+		}
+		sort.Strings(extra)
+		if len(extra) > 0 {
+			add(BadHeader, 1, "unexpected field(s) "+strings.Join(extra, ", ")+"; an agent has exactly "+strings.Join(AgentFields, ", "))
+		}
+		if name := a.Field("name"); name != "" {
+			if !kebab.MatchString(name) {
+				add(BadHeader, 1, "name \""+name+"\" is not lower-kebab-case")
+			}
+			if name != a.Stem() {
+				add(BadHeader, 1, "name \""+name+"\" does not match the file name "+path.Base(rel))
+			}
+		}
+		if ver := a.Field("version"); ver != "" && !semver.MatchString(ver) {
+			add(BadHeader, 1, "version \""+ver+"\" is not a semantic version (1.0.0)")
+		}
+		if dom := a.Field("domain"); dom != "" {
+			switch d := findDomain(v, dom); {
+			case d == nil:
+				add(MissingDomain, 1, "domain \""+dom+"\" is not a folder under "+v.Layout.Path(layout.Domains)+"/")
+			case d.Index == nil:
+				add(MissingDomain, 1, "domain \""+dom+"\" has no "+vault.IndexFile)
+			}
+		}
+	}
+
+	// The eight numbered sections.
+	for i, title := range AgentSections {
+		if _, ok := a.Section(i + 1); !ok {
+			add(MissingSection, 0, "no \"## "+strconv.Itoa(i+1)+". "+title+"\" section")
+		}
+	}
+
+	// Section 5 capabilities, and that each location exists.
+	if s, ok := a.Section(agent.CapabilitiesSection); ok {
+		if len(a.Capabilities) == 0 {
+			add(BadCapability, s.Line, "Section 5 lists no capabilities with name, location and use_when")
+		}
+		for _, c := range a.Capabilities {
+			var missing []string
+			if c.Location == "" {
+				missing = append(missing, "location")
+			}
+			if c.UseWhen == "" {
+				missing = append(missing, "use_when")
+			}
+			if len(missing) > 0 {
+				add(BadCapability, c.Line, "capability \""+c.Name+"\" has no "+strings.Join(missing, " or "))
+			}
+			if c.Location == "" {
+				continue
+			}
+			loc := strings.TrimPrefix(strings.TrimPrefix(c.Location, "./"), "/")
+			if _, err := os.Stat(v.Abs(loc)); err != nil {
+				add(MissingLocation, c.Line, "capability \""+c.Name+"\" points to "+c.Location+", which does not exist")
+			}
+		}
+	}
+
+	// *delegate in the Command Menu.
+	if s, ok := a.Section(commandMenuSection); ok && !strings.Contains(sectionText(string(data), a, s), "*delegate") {
+		add(NoDelegate, s.Line, "the Command Menu does not offer *delegate")
+	}
+
+	// A ROUTING_TABLE.md entry.
+	table := path.Base(v.Layout.Path(layout.RoutingTable))
+	entries, ok, err := audit.LoadEntries(v, layout.RoutingTable, []string{a.Stem()})
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case !ok:
+		add(NotRouted, 0, table+" not found; the agent cannot be routed to")
+	case !audit.Listed(audit.Item{Kind: audit.AgentItem, Name: a.Stem(), Path: rel}, entries):
+		add(NotRouted, 0, "agent \""+a.Stem()+"\" has no "+table+" entry")
+	}
+
+	sortByLine(out)
+	return out, nil
+}
+
+// findDomain looks a domain up by folder name or by the name in its
+// INDEX.md, so "PALBuilder" and "pal-builder" both find Domains/PALBuilder.
+func findDomain(v *vault.Vault, name string) *vault.Domain {
+	if d := v.Domain(name); d != nil {
+		return d
+	}
+	for _, d := range v.Domains {
+		if agent.Key(d.Name) == agent.Key(name) || (d.Index != nil && agent.Key(d.Index.Name) == agent.Key(name)) {
+			return d
+		}
+	}
+	return nil
+}
+
+// sectionText returns the file lines of section s, up to the next section.
+func sectionText(data string, a *agent.Agent, s agent.Section) string {
+	lines := markdown.Lines(data)
+	end := len(lines)
+	for _, o := range a.Sections {
+		if o.Line > s.Line && o.Line-1 < end {
+			end = o.Line - 1
+		}
+	}
+	if s.Line > end {
+		return ""
+	}
+	return strings.Join(lines[s.Line:end], "\n")
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sortByLine keeps findings in file order, header first.
+func sortByLine(fs []audit.Finding) {
+	sort.SliceStable(fs, func(i, j int) bool {
+		if fs[i].Path != fs[j].Path {
+			return fs[i].Path < fs[j].Path
+		}
+		return fs[i].Line < fs[j].Line
+	})
+}
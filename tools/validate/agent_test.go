@@ -0,0 +1,135 @@
+package validate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/audit"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+// load writes files, keyed by vault-relative path, into a fresh vault and
+// loads it.
+func load(t *testing.T, files map[string]string) *vault.Vault {
+	t.Helper()
+	root := t.TempDir()
+	for p, data := range files {
+		abs := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(abs, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	v, err := vault.Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// want is the part of a finding a test table checks.
+type want struct {
+	kind string
+	line int
+	msg  string
+}
+
+func check(t *testing.T, name string, got []audit.Finding, wants []want) {
+	t.Helper()
+	if len(got) != len(wants) {
+		var b strings.Builder
+		for _, f := range got {
+			b.WriteString(f.Kind + " " + f.Path + ": " + f.Message + "\n")
+		}
+		t.Errorf("%s gave %d findings, want %d:\n%s", name, len(got), len(wants), b.String())
+		return
+	}
+	for i, w := range wants {
+		if f := got[i]; f.Kind != w.kind || f.Line != w.line || f.Message != w.msg {
+			t.Errorf("%s finding %d = %s:%d %q, want %s:%d %q", name, i, f.Kind, f.Line, f.Message, w.kind, w.line, w.msg)
+		}
+	}
+}
+
+// agentFile returns an agent file with the given header and all eight
+// sections, menu filling the Command Menu and capabilities section 5.
+func agentFile(header, menu, capabilities string) string {
+	var b strings.Builder
+	b.WriteString("---\n" + header + "---\n")
+	for i, title := range AgentSections {
+		b.WriteString("\n## " + string(rune('1'+i)) + ". " + title + "\n\n")
+		switch i + 1 {
+		case commandMenuSection:
+			b.WriteString(menu)
+		case 5:
+			b.WriteString(capabilities)
+		}
+	}
+	return b.String()
+}
+
+func TestAgent(t *testing.T) {
+	good := agentFile("name: pal-builder\ndescription: Builds\nversion: 1.2.0\ndomain: pal-builder\n",
+		"- *delegate — hand off\n",
+		"- name: note-taking\n  location: .claude/skills/note-taking/SKILL.md\n  use_when: Capturing notes\n")
+	v := load(t, map[string]string{
+		".claude/agents/pal-builder.md": good,
+		".claude/agents/Bad_Agent.md": agentFile("name: Bad_Agent\ndescription: x\nversion: one\ndomain: Nowhere\nmodel: big\n", "- *help\n",
+			"- name: gone\n  location: .claude/skills/gone/SKILL.md\n  use_when: never\n- name: vague\n"),
+		".claude/agents/empty.md":              "---\nname: other\ndescription: x\nversion: 1.0.0\ndomain: Work\n---\n\n## 3. Command Menu\n\n## 5. My Capabilities\n",
+		".claude/agents/raw.md":                "# No header\n",
+		".claude/skills/note-taking/SKILL.md":  "",
+		".claude/core/system/ROUTING_TABLE.md": "| Agent | Location |\n|---|---|\n| pal-builder | `.claude/agents/pal-builder.md` |\n| Bad Agent | x |\n",
+		"Domains/PALBuilder/INDEX.md":          "---\nname: PAL Builder\n---\n",
+		"Domains/Work/02_PAGES/a.md":           "",
+	})
+
+	tests := []struct {
+		rel  string
+		want []want
+	}{
+		{rel: ".claude/agents/pal-builder.md"},
+		{rel: ".claude/agents/Bad_Agent.md", want: []want{
+			{BadHeader, 1, "unexpected field(s) model; an agent has exactly name, description, version, domain"},
+			{BadHeader, 1, `name "Bad_Agent" is not lower-kebab-case`},
+			{BadHeader, 1, `version "one" is not a semantic version (1.0.0)`},
+			{MissingDomain, 1, `domain "Nowhere" is not a folder under Domains/`},
+			{NoDelegate, 15, "the Command Menu does not offer *delegate"},
+			{MissingLocation, 24, `capability "gone" points to .claude/skills/gone/SKILL.md, which does not exist`},
+			{BadCapability, 27, `capability "vague" has no location or use_when`},
+		}},
+		{rel: ".claude/agents/empty.md", want: []want{
+			{MissingSection, 0, `no "## 1. Identity & Persona" section`},
+			{MissingSection, 0, `no "## 2. Activation Protocol" section`},
+			{MissingSection, 0, `no "## 4. How I Work" section`},
+			{MissingSection, 0, `no "## 6. Session State Model" section`},
+			{MissingSection, 0, `no "## 7. Error Handling & Recovery" section`},
+			{MissingSection, 0, `no "## 8. Operational Rules" section`},
+			{NotRouted, 0, `agent "empty" has no ROUTING_TABLE.md entry`},
+			{BadHeader, 1, `name "other" does not match the file name empty.md`},
+			{MissingDomain, 1, `domain "Work" has no INDEX.md`},
+			{NoDelegate, 8, "the Command Menu does not offer *delegate"},
+			{BadCapability, 10, "Section 5 lists no capabilities with name, location and use_when"},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := Agent(v, tt.rel)
+		if err != nil {
+			t.Errorf("%s: %v", tt.rel, err)
+			continue
+		}
+		check(t, tt.rel, got, tt.want)
+	}
+
+	raw, err := Agent(v, ".claude/agents/raw.md")
+	if err != nil || len(raw) != 10 || raw[9].Kind != BadHeader || raw[9].Message != "no YAML frontmatter; want name, description, version, domain" {
+		t.Errorf("agent without a header: %v, %v", raw, err)
+	}
+	if _, err := Agent(v, ".claude/agents/missing.md"); err == nil {
+		t.Error("Agent of a missing file did not fail")
+	}
+}
@@ -0,0 +1,294 @@
+package validate
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/audit"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/naming"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/rename"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/vault"
+)
+
+// Labels reported by Skill, besides BadHeader.
+const (
+	BadName      = "BAD NAME"
+	BadStructure = "BAD STRUCTURE"
+	MissingTools = "MISSING TOOLS"
+	NoUseWhen    = "NO USE WHEN"
+)
+
+// The fixed names inside a skill folder (requirements 1.0.2 and 1.3.1).
+const (
+	SkillFile    = "SKILL.md"
+	WorkflowsDir = "workflows"
+	ToolsDir     = "tools"
+)
+
+// misplaced are the subfolders requirement 1.0.3 names: their files
+// belong in the skill root.
+var misplaced = []string{"context", "docs"}
+
+// skillTree is what Skill and Canonical read of one skill folder.
+type skillTree struct {
+	v        *vault.Vault
+	dir      string // vault-relative
+	findings []audit.Finding
+	moves    []rename.Move
+	// skillFile is the vault-relative SKILL.md as spelled on disk, and
+	// tools whether a tools/ folder exists; name is the canonical skill
+	// name.
+	skillFile string
+	tools     bool
+	name      string
+}
+
+// Skill validates the skill folder at the vault-relative dir: its layout
+// (requirements 1.0.2 to 1.0.4 and 1.3.1), the names of the folder, its
+// workflows and context files (1.3.3 and 1.3.4), and the SKILL.md
+// frontmatter and USE WHEN clause (1.3.2). Every finding carries a fix
+// (1.3.5).
+func Skill(v *vault.Vault, dir string) ([]audit.Finding, error) {
+	t, err := readSkill(v, dir)
+	if err != nil {
+		return nil, err
+	}
+	return t.findings, nil
+}
+
+// SkillFix is what canonicalizing a skill folder changes.
+type SkillFix struct {
+	// Name is the canonical, lower-kebab-case skill name, which the folder
+	// and the SKILL.md name field should carry.
+	Name string
+	// SkillFile is the vault-relative SKILL.md as spelled on disk, "" when
+	// there is none.
+	SkillFile string
+	// Moves are the renames to canonical names, deepest paths first so
+	// they can run in order. Names whose canonical form is taken by
+	// another file are left out; Skill reports them.
+	Moves []rename.Move
+	// NoTools is set when the skill has no tools/ folder.
+	NoTools bool
+}
+
+// Canonical works out the SkillFix of the skill folder at dir.
+func Canonical(v *vault.Vault, dir string) (*SkillFix, error) {
+	t, err := readSkill(v, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &SkillFix{Name: t.name, SkillFile: t.skillFile, Moves: t.moves, NoTools: !t.tools}, nil
+}
+
+func readSkill(v *vault.Vault, dir string) (*skillTree, error) {
+	dir = path.Clean(dir)
+	info, err := os.Stat(v.Abs(dir))
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s: not a skill folder", dir)
+	}
+	t := &skillTree{v: v, dir: dir}
+
+	// The folder name: lower-kebab-case (1.3.3).
+	t.name = path.Base(dir)
+	if !naming.Valid(naming.Folder, t.name) {
+		t.name = naming.Suggest(naming.Folder, t.name, true)
+		t.rename(dir, t.name, true, "skill folder", naming.Folder)
+		if t.name == "" {
+			t.name = path.Base(dir)
+		}
+	}
+
+	entries, err := os.ReadDir(v.Abs(dir))
+	if err != nil {
+		return nil, err
+	}
+	var workflows string
+	for _, e := range entries {
+		name := e.Name()
+		rel := path.Join(dir, name)
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if !e.IsDir() {
+			switch {
+			case strings.EqualFold(name, SkillFile):
+				t.skillFile = rel
+				if name != SkillFile {
+					t.renameTo(rel, SkillFile, false, "the main skill file is always "+SkillFile)
+				}
+			case strings.EqualFold(name, "README.md"), !strings.EqualFold(path.Ext(name), ".md"):
+			default:
+				t.conform(rel, "context file", naming.ContextFile)
+			}
+			continue
+		}
+		switch lower := strings.ToLower(name); {
+		case lower == WorkflowsDir:
+			workflows = rel
+			if name != WorkflowsDir {
+				t.renameTo(rel, WorkflowsDir, true, "the workflows folder is always "+WorkflowsDir+"/")
+			}
+			if err := t.readWorkflows(rel); err != nil {
+				return nil, err
+			}
+		case lower == ToolsDir:
+			t.tools = true
+			if name != ToolsDir {
+				t.renameTo(rel, ToolsDir, true, "the tools folder is always "+ToolsDir+"/")
+			}
+			if err := t.flat(rel); err != nil {
+				return nil, err
+			}
+		case contains(misplaced, lower):
+			t.add(BadStructure, rel, 0, name+"/ holds context files, which live in the skill root (requirement 1.0.3)",
+				"move its files to "+dir+"/ and delete "+name+"/")
+		default:
+			t.add(BadStructure, rel, 0, "unexpected folder "+name+"/; a skill has only "+WorkflowsDir+"/ and "+ToolsDir+"/ below its root (requirement 1.0.2)",
+				"move context files to "+dir+"/, workflows to "+WorkflowsDir+"/ and tools to "+ToolsDir+"/, then delete "+name+"/")
+		}
+	}
+
+	if t.skillFile == "" {
+		t.add(BadStructure, dir, 0, "no "+SkillFile,
+			"create "+path.Join(dir, SkillFile)+" with name and description frontmatter")
+	} else if err := t.readHeader(); err != nil {
+		return nil, err
+	}
+	if workflows == "" {
+		t.add(BadStructure, dir, 0, "no "+WorkflowsDir+"/ folder (requirement 1.3.1)",
+			"create "+path.Join(dir, WorkflowsDir)+"/ for the skill's workflow files")
+	}
+	if !t.tools {
+		t.add(MissingTools, dir, 0, "no "+ToolsDir+"/ folder; every skill has one, even if empty (requirement 1.0.4)",
+			"create "+path.Join(dir, ToolsDir)+"/ with a .gitkeep")
+	}
+
+	sort.SliceStable(t.moves, func(i, j int) bool {
+		return strings.Count(t.moves[i].From, "/") > strings.Count(t.moves[j].From, "/")
+	})
+	sortByLine(t.findings)
+	return t, nil
+}
+
+// readWorkflows checks the workflow files: lower_snake_case markdown, no
+// subfolders (1.3.4).
+func (t *skillTree) readWorkflows(dir string) error {
+	entries, err := os.ReadDir(t.v.Abs(dir))
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		rel := path.Join(dir, e.Name())
+		switch {
+		case strings.HasPrefix(e.Name(), "."):
+		case e.IsDir():
+			t.nested(rel)
+		case !strings.EqualFold(path.Ext(e.Name()), ".md"):
+			t.add(BadStructure, rel, 0, WorkflowsDir+"/ holds workflow files only",
+				"move "+e.Name()+" to "+path.Join(t.dir, ToolsDir)+"/ if it is a tool, or to the skill root")
+		default:
+			t.conform(rel, "workflow file", naming.ContextFile)
+		}
+	}
+	return nil
+}
+
+// flat reports the subfolders of dir, which would nest the skill deeper
+// than two levels.
+func (t *skillTree) flat(dir string) error {
+	entries, err := os.ReadDir(t.v.Abs(dir))
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			t.nested(path.Join(dir, e.Name()))
+		}
+	}
+	return nil
+}
+
+func (t *skillTree) nested(rel string) {
+	t.add(BadStructure, rel, 0, "folder nests the skill deeper than two levels (requirement 1.0.2)",
+		"move its files up to "+path.Dir(rel)+"/ and delete "+path.Base(rel)+"/")
+}
+
+// readHeader checks the SKILL.md frontmatter: a name matching the folder
+// and a description with a USE WHEN clause.
+func (t *skillTree) readHeader() error {
+	n, err := vault.ReadNote(t.v.Root, t.skillFile)
+	if n == nil {
+		return err
+	}
+	rel := t.skillFile
+	if err != nil {
+		t.add(BadHeader, rel, 1, "frontmatter does not parse: "+err.Error(), "fix the YAML between the --- lines")
+		return nil
+	}
+	if n.Frontmatter == nil {
+		t.add(BadHeader, rel, 1, "no YAML frontmatter; want name and description",
+			"start the file with ---, name: "+t.name+", description: ... USE WHEN ..., ---")
+		return nil
+	}
+	switch name := n.Field("name"); {
+	case name == "":
+		t.add(BadHeader, rel, 1, "missing field name", "add name: "+t.name)
+	case name != t.name:
+		t.add(BadHeader, rel, 1, "name \""+name+"\" does not match the lower-kebab-case skill folder \""+t.name+"\"", "set name: "+t.name)
+	}
+	desc := n.Field("description")
+	switch {
+	case desc == "":
+		t.add(BadHeader, rel, 1, "missing field description",
+			"add a description ending in USE WHEN and the phrases that activate the skill")
+	case strings.Contains(desc, "USE WHEN"):
+	case strings.Contains(strings.ToUpper(desc), "USE WHEN"):
+		t.add(NoUseWhen, rel, 1, "the description's use when clause is not in capitals", "write it as USE WHEN")
+	default:
+		t.add(NoUseWhen, rel, 1, "the description has no USE WHEN clause (requirement 1.3.2)",
+			"append \"USE WHEN the user mentions ...\" listing the phrases that activate the skill")
+	}
+	return nil
+}
+
+// conform reports rel when its name breaks category c and plans the
+// rename.
+func (t *skillTree) conform(rel, what string, c naming.Category) {
+	name := path.Base(rel)
+	if naming.Valid(c, name) {
+		return
+	}
+	t.rename(rel, naming.Suggest(c, name, false), false, what, c)
+}
+
+// rename reports the name of rel as breaking category c and plans the
+// rename to want; "" when no name can be derived.
+func (t *skillTree) rename(rel, want string, dir bool, what string, c naming.Category) {
+	msg := what + " \"" + path.Base(rel) + "\" is not " + naming.Pattern(c)
+	if want == "" {
+		t.add(BadName, rel, 0, msg, "rename it by hand to "+naming.Pattern(c))
+		return
+	}
+	t.renameTo(rel, want, dir, msg)
+}
+
+func (t *skillTree) renameTo(rel, want string, dir bool, msg string) {
+	to := path.Join(path.Dir(rel), want)
+	if _, err := os.Stat(t.v.Abs(to)); err == nil && !strings.EqualFold(to, rel) {
+		t.add(BadName, rel, 0, msg, "rename it to "+want+" by hand; a file of that name exists")
+		return
+	}
+	t.add(BadName, rel, 0, msg, "rename it to "+want)
+	t.moves = append(t.moves, rename.Move{From: rel, To: to})
+}
+
+func (t *skillTree) add(kind, p string, line int, msg, fix string) {
+	t.findings = append(t.findings, audit.Finding{Audit: "skill", Kind: kind, Path: p, Line: line, Message: msg, Fix: fix})
+}
@@ -0,0 +1,121 @@
+package validate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/rename"
+)
+
+func TestSkill(t *testing.T) {
+	v := load(t, map[string]string{
+		".claude/skills/note-taking/SKILL.md":             "---\nname: note-taking\ndescription: Takes notes. USE WHEN the user says note.\n---\n",
+		".claude/skills/note-taking/style_guide.md":       "",
+		".claude/skills/note-taking/README.md":            "",
+		".claude/skills/note-taking/workflows/capture.md": "",
+		".claude/skills/note-taking/tools/.gitkeep":       "",
+		".claude/skills/Deep_Work/skill.md":               "---\nname: deep\ndescription: Focus. use when asked\n---\n",
+		".claude/skills/Deep_Work/Focus Notes.md":         "",
+		".claude/skills/Deep_Work/Workflows/Plan Day.md":  "",
+		".claude/skills/Deep_Work/Workflows/plan_day.md":  "",
+		".claude/skills/Deep_Work/Workflows/run.sh":       "",
+		".claude/skills/Deep_Work/Workflows/old/a.md":     "",
+		".claude/skills/Deep_Work/docs/a.md":              "",
+		".claude/skills/Deep_Work/extra/b.md":             "",
+		".claude/skills/bare/SKILL.md":                    "No header\n",
+		".claude/skills/quiet/SKILL.md":                   "---\nname: quiet\ndescription: Stays quiet.\n---\n",
+		".claude/skills/quiet/workflows/.gitkeep":         "",
+		".claude/skills/quiet/tools/helper/x.py":          "",
+	})
+
+	const deep = ".claude/skills/Deep_Work"
+	tests := []struct {
+		dir  string
+		want []struct{ kind, path, msg string }
+	}{
+		{dir: ".claude/skills/note-taking"},
+		{dir: deep, want: []struct{ kind, path, msg string }{
+			{BadName, deep, `skill folder "Deep_Work" is not lower-kebab-case`},
+			{MissingTools, deep, "no tools/ folder; every skill has one, even if empty (requirement 1.0.4)"},
+			{BadName, deep + "/Focus Notes.md", `context file "Focus Notes.md" is not lower_snake_case.md`},
+			{BadName, deep + "/Workflows", "the workflows folder is always workflows/"},
+			{BadName, deep + "/Workflows/Plan Day.md", `workflow file "Plan Day.md" is not lower_snake_case.md`},
+			{BadStructure, deep + "/Workflows/old", "folder nests the skill deeper than two levels (requirement 1.0.2)"},
+			{BadStructure, deep + "/Workflows/run.sh", "workflows/ holds workflow files only"},
+			{BadStructure, deep + "/docs", "docs/ holds context files, which live in the skill root (requirement 1.0.3)"},
+			{BadStructure, deep + "/extra", "unexpected folder extra/; a skill has only workflows/ and tools/ below its root (requirement 1.0.2)"},
+			{BadName, deep + "/skill.md", "the main skill file is always SKILL.md"},
+			{BadHeader, deep + "/skill.md", `name "deep" does not match the lower-kebab-case skill folder "deep-work"`},
+			{NoUseWhen, deep + "/skill.md", "the description's use when clause is not in capitals"},
+		}},
+		{dir: ".claude/skills/bare/", want: []struct{ kind, path, msg string }{
+			{BadStructure, ".claude/skills/bare", "no workflows/ folder (requirement 1.3.1)"},
+			{MissingTools, ".claude/skills/bare", "no tools/ folder; every skill has one, even if empty (requirement 1.0.4)"},
+			{BadHeader, ".claude/skills/bare/SKILL.md", "no YAML frontmatter; want name and description"},
+		}},
+		{dir: ".claude/skills/quiet", want: []struct{ kind, path, msg string }{
+			{NoUseWhen, ".claude/skills/quiet/SKILL.md", "the description has no USE WHEN clause (requirement 1.3.2)"},
+			{BadStructure, ".claude/skills/quiet/tools/helper", "folder nests the skill deeper than two levels (requirement 1.0.2)"},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := Skill(v, tt.dir)
+		if err != nil {
+			t.Errorf("Skill(%q): %v", tt.dir, err)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("Skill(%q) gave %d findings, want %d: %+v", tt.dir, len(got), len(tt.want), got)
+			continue
+		}
+		for i, w := range tt.want {
+			if f := got[i]; f.Kind != w.kind || f.Path != w.path || f.Message != w.msg || f.Fix == "" {
+				t.Errorf("Skill(%q) finding %d = %s %s %q (fix %q), want %s %s %q", tt.dir, i, f.Kind, f.Path, f.Message, f.Fix, w.kind, w.path, w.msg)
+			}
+		}
+	}
+
+	if _, err := Skill(v, ".claude/skills/gone"); err == nil {
+		t.Error("Skill of a missing folder did not fail")
+	}
+	if _, err := Skill(v, ".claude/skills/bare/SKILL.md"); err == nil || err.Error() != ".claude/skills/bare/SKILL.md: not a skill folder" {
+		t.Errorf("Skill of a file: %v", err)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	v := load(t, map[string]string{
+		".claude/skills/Deep_Work/skill.md":              "---\nname: deep-work\ndescription: USE WHEN focusing\n---\n",
+		".claude/skills/Deep_Work/Focus Notes.md":        "",
+		".claude/skills/Deep_Work/Workflows/Plan Day.md": "",
+		".claude/skills/Deep_Work/Workflows/plan_day.md": "",
+		".claude/skills/done/SKILL.md":                   "",
+		".claude/skills/done/tools/.gitkeep":             "",
+	})
+	const deep = ".claude/skills/Deep_Work"
+	fix, err := Canonical(v, deep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Plan Day.md is left out: plan_day.md exists. The moves run deepest
+	// first, then in folder order.
+	want := &SkillFix{
+		Name:      "deep-work",
+		SkillFile: deep + "/skill.md",
+		Moves: []rename.Move{
+			{From: deep + "/Focus Notes.md", To: deep + "/focus_notes.md"},
+			{From: deep + "/Workflows", To: deep + "/workflows"},
+			{From: deep + "/skill.md", To: deep + "/SKILL.md"},
+			{From: deep, To: ".claude/skills/deep-work"},
+		},
+		NoTools: true,
+	}
+	if !reflect.DeepEqual(fix, want) {
+		t.Errorf("Canonical(%q) = %+v, want %+v", deep, fix, want)
+	}
+
+	done, err := Canonical(v, ".claude/skills/done")
+	if err != nil || done.Name != "done" || done.SkillFile != ".claude/skills/done/SKILL.md" || done.Moves != nil || done.NoTools {
+		t.Errorf("Canonical of a canonical skill = %+v, %v", done, err)
+	}
+}
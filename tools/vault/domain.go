@@ -0,0 +1,302 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/connections"
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+)
+
+// Domain is one workspace under Domains/.
+type Domain struct {
+	// Name is the folder name, e.g. "PALBuilder".
+	Name string
+	// Path is the domain folder relative to the vault root.
+	Path string
+
+	// Index is the parsed INDEX.md, or nil when the file is missing.
+	Index *Index
+	// Connections are the entries of CONNECTIONS.yaml. HasConnections is
+	// false when the file does not exist.
+	Connections    []connections.Connection
+	HasConnections bool
+
+	// Folders records which of the standard folders exist on disk.
+	Folders map[string]bool
+	// Extra lists any other top-level folders, e.g. 06_REQUIREMENTS.
+	Extra []string
+
+	Projects []*Project
+	Pages    []*Page
+	Sessions []*Session
+
+	// Problems collects errors met while loading this domain.
+	Problems []error
+}
+
+// Index is a domain's INDEX.md.
+type Index struct {
+	Note
+	Name        string
+	Description string
+	Status      string
+	Created     string
+	Updated     string
+	DomainAgent string
+
+	// ActiveWork holds the rows of the "## Active Work" table.
+	// HasActiveWork is true when the table exists, even if it is empty.
+	ActiveWork    []ActiveWork
+	HasActiveWork bool
+}
+
+// ActiveWork is one row of an INDEX.md Active Work table.
+type ActiveWork struct {
+	Project     string `json:"project"`
+	Status      string `json:"status"`
+	LastUpdated string `json:"last_updated"`
+	// Line is the 1-based line number of the row within INDEX.md.
+	Line int `json:"line"`
+}
+
+// Project is an entry directly under 01_PROJECTS/: a markdown file or a
+// project folder.
+type Project struct {
+	Note
+	// Name is the frontmatter name, falling back to the file or folder name.
+	Name   string
+	Status string
+	// Dir is true for folder projects; such projects have no Note content.
+	Dir bool
+}
+
+// Page is a markdown file under 02_PAGES/.
+type Page struct {
+	Note
+	Type     string
+	Status   string
+	Category string
+}
+
+// Session is a markdown file under 04_SESSIONS/.
+type Session struct {
+	Note
+	// Date comes from a YYYY-MM-DD file name prefix, or the frontmatter
+	// date/created field. It is zero when neither is present.
+	Date time.Time
+}
+
+var sessionDate = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})`)
+
+// Folder returns the vault-relative path of one of the domain's folders.
+func (d *Domain) Folder(name string) string {
+	return d.Path + "/" + name
+}
+
+func loadDomain(root, domains, name string) *Domain {
+	d := &Domain{
+		Name:    name,
+		Path:    domains + "/" + name,
+		Folders: map[string]bool{},
+	}
+	dir := filepath.Join(root, filepath.FromSlash(d.Path))
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		d.Problems = append(d.Problems, err)
+		return d
+	}
+	standard := map[string]bool{}
+	for _, f := range Folders {
+		standard[f] = true
+	}
+	for _, e := range entries {
+		switch {
+		case e.IsDir() && standard[e.Name()]:
+			d.Folders[e.Name()] = true
+		case e.IsDir() && !strings.HasPrefix(e.Name(), "."):
+			d.Extra = append(d.Extra, e.Name())
+		}
+	}
+
+	if n, err := ReadNote(root, d.Path+"/"+IndexFile); err == nil {
+		d.Index = newIndex(n)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		d.Problems = append(d.Problems, err)
+		if n != nil {
+			d.Index = newIndex(n)
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, ConnectionsFile)); err == nil {
+		d.HasConnections = true
+		if set, err := connections.Parse(data); err == nil {
+			d.Connections = set.Connections
+		} else {
+			d.Problems = append(d.Problems, fmt.Errorf("%s/%s: %w", d.Path, ConnectionsFile, err))
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		d.HasConnections = true
+		d.Problems = append(d.Problems, err)
+	}
+
+	d.loadProjects(root)
+	for _, n := range d.notes(root, PagesDir) {
+		d.Pages = append(d.Pages, &Page{
+			Note:     *n,
+			Type:     n.Field("type"),
+			Status:   n.Field("status"),
+			Category: n.Field("category"),
+		})
+	}
+	for _, n := range d.notes(root, SessionsDir) {
+		d.Sessions = append(d.Sessions, &Session{Note: *n, Date: SessionDate(n)})
+	}
+	return d
+}
+
+func newIndex(n *Note) *Index {
+	idx := &Index{
+		Note:        *n,
+		Name:        n.Field("name"),
+		Description: n.Field("description"),
+		Status:      n.Field("status"),
+		Created:     n.Field("created"),
+		Updated:     n.Field("updated"),
+		DomainAgent: n.Field("domain-agent"),
+	}
+	idx.ActiveWork, idx.HasActiveWork = parseActiveWork(n.Body, n.BodyLine-1)
+	return idx
+}
+
+// parseActiveWork reads the first table under the "Active Work" heading.
+// offset is the number of file lines preceding the body. ok is false when
+// there is no such table.
+func parseActiveWork(body string, offset int) (rows []ActiveWork, ok bool) {
+	lines, start, ok := markdown.Section(body, "Active Work")
+	if !ok {
+		return nil, false
+	}
+	tables := markdown.Tables(lines, start+offset)
+	if len(tables) == 0 {
+		return nil, false
+	}
+	t := tables[0]
+	for _, r := range t.Rows {
+		name := t.Get(r, "Project")
+		if name == "" && len(r.Cells) > 0 {
+			name = r.Cells[0]
+		}
+		if name == "" {
+			continue
+		}
+		rows = append(rows, ActiveWork{
+			Project:     name,
+			Status:      t.Get(r, "Status"),
+			LastUpdated: t.Get(r, "Last Updated"),
+			Line:        r.Line,
+		})
+	}
+	return rows, true
+}
+
+func (d *Domain) loadProjects(root string) {
+	dir := filepath.Join(root, filepath.FromSlash(d.Folder(ProjectsDir)))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			d.Problems = append(d.Problems, err)
+		}
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") || strings.EqualFold(name, "README.md") {
+			continue
+		}
+		p := d.Folder(ProjectsDir) + "/" + name
+		if e.IsDir() {
+			d.Projects = append(d.Projects, &Project{Note: Note{Path: p}, Name: name, Dir: true})
+			continue
+		}
+		if !strings.EqualFold(filepath.Ext(name), ".md") {
+			continue
+		}
+		n, err := ReadNote(root, p)
+		if err != nil {
+			d.Problems = append(d.Problems, err)
+			if n == nil {
+				continue
+			}
+		}
+		proj := &Project{Note: *n, Name: n.Field("name"), Status: n.Field("status")}
+		if proj.Name == "" {
+			proj.Name = n.Stem()
+		}
+		d.Projects = append(d.Projects, proj)
+	}
+}
+
+// notes reads every markdown file below one of the domain's folders, skipping
+// README.md files that only describe the folder itself.
+func (d *Domain) notes(root, folder string) []*Note {
+	dir := filepath.Join(root, filepath.FromSlash(d.Folder(folder)))
+	var out []*Note
+	err := filepath.WalkDir(dir, func(p string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if e.IsDir() {
+			if p != dir && strings.HasPrefix(e.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.EqualFold(filepath.Ext(p), ".md") || strings.EqualFold(e.Name(), "README.md") {
+			return nil
+		}
+		n, err := ReadNote(root, rel(root, p))
+		if err != nil {
+			d.Problems = append(d.Problems, err)
+		}
+		if n != nil {
+			out = append(out, n)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		d.Problems = append(d.Problems, err)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// SessionDate returns the date of a session note: its YYYY-MM-DD file name
+// prefix, or else its date or created field. It is zero when neither is
+// present.
+func SessionDate(n *Note) time.Time {
+	var t time.Time
+	if m := sessionDate.FindString(n.Stem()); m != "" {
+		t, _ = time.Parse("2006-01-02", m)
+	} else if v := firstField(n, "date", "created"); v != "" {
+		t, _ = time.Parse("2006-01-02", v[:min(len(v), 10)])
+	}
+	return t
+}
+
+func firstField(n *Note, keys ...string) string {
+	for _, k := range keys {
+		if v := n.Field(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
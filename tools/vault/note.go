@@ -0,0 +1,94 @@
+package vault
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/frontmatter"
+)
+
+// Note is a markdown file with optional YAML frontmatter.
+type Note struct {
+	// Path is relative to the vault root.
+	Path string
+	// Frontmatter holds the decoded YAML block, or nil when the file has none.
+	Frontmatter map[string]any
+	// Body is everything after the frontmatter block.
+	Body string
+	// BodyLine is the 1-based file line on which Body starts.
+	BodyLine int
+}
+
+// Stem returns the file name without directory or extension.
+func (n *Note) Stem() string {
+	base := filepath.Base(filepath.FromSlash(n.Path))
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Field returns a frontmatter value as a string. Dates decoded by YAML are
+// formatted back as YYYY-MM-DD; missing keys return "".
+func (n *Note) Field(key string) string {
+	return field(n.Frontmatter, key)
+}
+
+// Title returns the first level-one heading of the body, or the file stem.
+func (n *Note) Title() string {
+	for _, line := range strings.Split(n.Body, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(line[2:])
+		}
+	}
+	return n.Stem()
+}
+
+func field(m map[string]any, key string) string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return ""
+	}
+	switch t := v.(type) {
+	case string:
+		return t
+	case time.Time:
+		if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+			return t.Format("2006-01-02")
+		}
+		return t.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// ReadNote reads the markdown file at the vault-relative path rel.
+func ReadNote(root, rel string) (*Note, error) {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil, err
+	}
+	return ParseNote(rel, data)
+}
+
+// ParseNote splits data into frontmatter and body. A YAML error is returned
+// together with a Note carrying the body, so callers can still report on it.
+func ParseNote(rel string, data []byte) (*Note, error) {
+	n := &Note{Path: rel, BodyLine: 1}
+	yml, body, ok := frontmatter.Split(data)
+	if !ok {
+		n.Body = string(data)
+		return n, nil
+	}
+	n.Body = string(body)
+	n.BodyLine = bytes.Count(data[:len(data)-len(body)], []byte("\n")) + 1
+	fm := map[string]any{}
+	if err := yaml.Unmarshal(yml, &fm); err != nil {
+		return n, fmt.Errorf("%s: frontmatter: %w", rel, err)
+	}
+	n.Frontmatter = fm
+	return n, nil
+}
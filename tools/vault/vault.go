@@ -0,0 +1,212 @@
+// Package vault loads a PAL Second Brain vault from disk into typed values.
+//
+// A vault is a directory holding Domains/, inbox/, Ports/ and the .claude/
+// configuration layer. Each domain is a folder under Domains/ with an
+// INDEX.md (the domain's source of truth), a CONNECTIONS.yaml and the
+// numbered workspace folders 00_CONTEXT through 05_ARCHIVE. Load walks that
+// structure once and returns plain structs that audits and sync tools can
+// share instead of re-deriving the layout themselves.
+//
+// All paths stored on the returned values are relative to the vault root and
+// use forward slashes, the way Obsidian and the INDEX.md files spell them.
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/layout"
+)
+
+// DomainsDir is the default folder, relative to the vault root, that holds
+// domains. A pal.layout.yaml manifest may move it; see Vault.Layout.
+const DomainsDir = "Domains"
+
+// The standard domain files and folders (requirements 1.2.1 and 1.8.4).
+const (
+	IndexFile       = "INDEX.md"
+	ConnectionsFile = "CONNECTIONS.yaml"
+
+	ContextDir  = "00_CONTEXT"
+	ProjectsDir = "01_PROJECTS"
+	PagesDir    = "02_PAGES"
+	OutputDir   = "03_OUTPUT"
+	SessionsDir = "04_SESSIONS"
+	ArchiveDir  = "05_ARCHIVE"
+)
+
+// Folders lists the six required domain folders in their numbered order.
+var Folders = []string{ContextDir, ProjectsDir, PagesDir, OutputDir, SessionsDir, ArchiveDir}
+
+// Vault is a loaded PAL vault.
+type Vault struct {
+	// Root is the absolute path of the vault directory.
+	Root string
+	// Layout names the vault's canonical locations, from pal.layout.yaml
+	// or the defaults.
+	Layout  *layout.Layout
+	Domains []*Domain
+}
+
+// Load reads the vault rooted at root. A missing Domains/ folder is not an
+// error; the vault simply has no domains. Problems inside a single domain
+// (an unreadable INDEX.md, malformed YAML) are recorded on that domain's
+// Problems field so one broken domain does not hide the others.
+func Load(root string) (*Vault, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("vault: %s is not a directory", root)
+	}
+
+	l, err := layout.Load(abs)
+	if err != nil {
+		return nil, err
+	}
+	v := &Vault{Root: abs, Layout: l}
+	domains, ok := v.Find(layout.Domains)
+	if !ok {
+		return v, nil
+	}
+	entries, err := os.ReadDir(v.Abs(domains))
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		v.Domains = append(v.Domains, loadDomain(abs, domains, e.Name()))
+	}
+	sort.Slice(v.Domains, func(i, j int) bool { return v.Domains[i].Name < v.Domains[j].Name })
+	return v, nil
+}
+
+// Domain returns the domain whose folder name or INDEX.md name matches name,
+// ignoring case, or nil.
+func (v *Vault) Domain(name string) *Domain {
+	for _, d := range v.Domains {
+		if strings.EqualFold(d.Name, name) {
+			return d
+		}
+	}
+	for _, d := range v.Domains {
+		if d.Index != nil && strings.EqualFold(d.Index.Name, name) {
+			return d
+		}
+	}
+	return nil
+}
+
+// Find returns the on-disk path of a layout location such as
+// layout.MasterTasks, matched case-insensitively; see layout.Layout.Find.
+func (v *Vault) Find(key string) (string, bool) {
+	return v.Layout.Find(v.Root, key)
+}
+
+// Abs converts a vault-relative path to an absolute filesystem path.
+func (v *Vault) Abs(rel string) string {
+	return filepath.Join(v.Root, filepath.FromSlash(rel))
+}
+
+// FindRoot walks up from dir until it finds a directory containing a
+// pal.layout.yaml manifest or a Domains/ folder and returns it.
+func FindRoot(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for d := abs; ; {
+		if _, err := os.Stat(filepath.Join(d, layout.File)); err == nil {
+			return d, nil
+		}
+		if info, err := os.Stat(filepath.Join(d, DomainsDir)); err == nil && info.IsDir() {
+			return d, nil
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return "", fmt.Errorf("vault: no %s/ folder found above %s", DomainsDir, abs)
+		}
+		d = parent
+	}
+}
+
+// rel returns p relative to root with forward slashes.
+func rel(root, p string) string {
+	r, err := filepath.Rel(root, p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	return filepath.ToSlash(r)
+}
+
+// Files returns the vault-relative paths of every markdown file below the
+// given folder of d, at any depth, in lexical order. Hidden files and folders
+// are skipped. A missing folder yields no files.
+func (v *Vault) Files(d *Domain, folder string) ([]string, error) {
+	dir := v.Abs(d.Folder(folder))
+	var out []string
+	err := filepath.WalkDir(dir, func(p string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(e.Name(), ".") && p != dir {
+			if e.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !e.IsDir() && strings.EqualFold(filepath.Ext(p), ".md") {
+			out = append(out, rel(v.Root, p))
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return out, err
+}
+
+// Markdown returns the vault-relative paths of every markdown file in the
+// vault, in lexical order. Hidden folders such as .git, .obsidian and the
+// .claude configuration layer are skipped.
+func (v *Vault) Markdown() ([]string, error) {
+	return v.walk(func(p string) bool { return strings.EqualFold(filepath.Ext(p), ".md") })
+}
+
+// AllFiles returns the vault-relative path of every file in the vault,
+// attachments included, skipping hidden files and directories.
+func (v *Vault) AllFiles() ([]string, error) {
+	return v.walk(func(string) bool { return true })
+}
+
+func (v *Vault) walk(match func(p string) bool) ([]string, error) {
+	var out []string
+	err := filepath.WalkDir(v.Root, func(p string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != v.Root && strings.HasPrefix(e.Name(), ".") {
+			if e.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !e.IsDir() && match(p) {
+			out = append(out, rel(v.Root, p))
+		}
+		return nil
+	})
+	return out, err
+}
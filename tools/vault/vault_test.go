@@ -0,0 +1,186 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// tree writes files, keyed by vault-relative path, into a fresh vault root.
+func tree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for p, data := range files {
+		abs := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(abs, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestLoad(t *testing.T) {
+	root := tree(t, map[string]string{
+		"Domains/Work/INDEX.md": "---\nname: Work Life\nstatus: active\nupdated: 2026-10-01\n---\n\n# Work\n\n" +
+			"## Active Work\n\n| Project | Status | Last Updated |\n|---|---|---|\n| [[PROJECT_API]] | active | 2026-10-01 |\n\n## Notes\n",
+		"Domains/Work/CONNECTIONS.yaml":               "version: 1\nconnections:\n  - name: Docs\n    type: documentation\n    url: https://x.dev\n",
+		"Domains/Work/01_PROJECTS/PROJECT_API.md":     "---\nname: API\nstatus: active\n---\n",
+		"Domains/Work/01_PROJECTS/PROJECT_WEB.md":     "# Web\n",
+		"Domains/Work/01_PROJECTS/README.md":          "about\n",
+		"Domains/Work/01_PROJECTS/ASSETS/logo.png":    "",
+		"Domains/Work/02_PAGES/guide.md":              "---\ntype: guide\ncategory: howto\n---\n",
+		"Domains/Work/04_SESSIONS/2026-09-30-call.md": "# Call\n",
+		"Domains/Work/04_SESSIONS/old/retro.md":       "---\ndate: 2026-08-01\n---\n",
+		"Domains/Work/06_REQUIREMENTS/spec.md":        "",
+		"Domains/Broken/INDEX.md":                     "---\nname: [oops\n---\n",
+		"Domains/Broken/CONNECTIONS.yaml":             "apis: none\n",
+		"Domains/.hidden/INDEX.md":                    "",
+		"Domains/Broken/00_CONTEXT/.keep":             "",
+		"Domains/Broken/01_PROJECTS/PROJECT_X.md":     "---\nname: [x\n---\nbody\n",
+		"inbox/notes/loose.md":                        "",
+		".obsidian/workspace.md":                      "",
+	})
+	v, err := Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Domains) != 2 || v.Domains[0].Name != "Broken" || v.Domains[1].Name != "Work" {
+		t.Fatalf("domains: %+v", v.Domains)
+	}
+
+	w := v.Domain("work life")
+	if w == nil || w != v.Domain("WORK") {
+		t.Fatalf("Domain by index name and folder name: %v", w)
+	}
+	if len(w.Problems) > 0 {
+		t.Errorf("Work problems: %v", w.Problems)
+	}
+	if w.Path != "Domains/Work" || !w.Folders[ProjectsDir] || w.Folders[ContextDir] || !reflect.DeepEqual(w.Extra, []string{"06_REQUIREMENTS"}) {
+		t.Errorf("Work folders: %s %v %v", w.Path, w.Folders, w.Extra)
+	}
+	want := []ActiveWork{{Project: "[[PROJECT_API]]", Status: "active", LastUpdated: "2026-10-01", Line: 13}}
+	if w.Index == nil || w.Index.Name != "Work Life" || w.Index.Updated != "2026-10-01" ||
+		!w.Index.HasActiveWork || !reflect.DeepEqual(w.Index.ActiveWork, want) {
+		t.Errorf("Work index: %+v", w.Index)
+	}
+	if !w.HasConnections || len(w.Connections) != 1 || w.Connections[0].Name != "Docs" {
+		t.Errorf("Work connections: %v %+v", w.HasConnections, w.Connections)
+	}
+
+	tests := []struct {
+		name, path, status string
+		dir                bool
+	}{
+		{name: "ASSETS", path: "Domains/Work/01_PROJECTS/ASSETS", dir: true},
+		{name: "API", path: "Domains/Work/01_PROJECTS/PROJECT_API.md", status: "active"},
+		{name: "PROJECT_WEB", path: "Domains/Work/01_PROJECTS/PROJECT_WEB.md"},
+	}
+	if len(w.Projects) != len(tests) {
+		t.Fatalf("Work projects: %+v", w.Projects)
+	}
+	for i, tt := range tests {
+		p := w.Projects[i]
+		if p.Name != tt.name || p.Path != tt.path || p.Status != tt.status || p.Dir != tt.dir {
+			t.Errorf("project %d = %+v, want %+v", i, p, tt)
+		}
+	}
+	if len(w.Pages) != 1 || w.Pages[0].Type != "guide" || w.Pages[0].Category != "howto" {
+		t.Errorf("Work pages: %+v", w.Pages)
+	}
+	if len(w.Sessions) != 2 ||
+		!w.Sessions[0].Date.Equal(time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)) ||
+		!w.Sessions[1].Date.Equal(time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Work sessions: %+v", w.Sessions)
+	}
+
+	b := v.Domain("Broken")
+	if len(b.Problems) != 3 || b.Index == nil || !b.HasConnections || !b.Folders[ContextDir] {
+		t.Errorf("Broken: index %v, connections %v, problems %v", b.Index, b.HasConnections, b.Problems)
+	}
+	if len(b.Projects) != 1 || b.Projects[0].Name != "PROJECT_X" || b.Projects[0].Body != "body\n" {
+		t.Errorf("Broken projects: %+v", b.Projects)
+	}
+	if v.Domain("Missing") != nil {
+		t.Error("Domain found a missing domain")
+	}
+
+	md, err := v.Markdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range md {
+		if p == ".obsidian/workspace.md" || p == "Domains/.hidden/INDEX.md" {
+			t.Errorf("Markdown listed hidden file %s", p)
+		}
+	}
+	files, err := v.Files(w, SessionsDir)
+	if err != nil || !reflect.DeepEqual(files, []string{"Domains/Work/04_SESSIONS/2026-09-30-call.md", "Domains/Work/04_SESSIONS/old/retro.md"}) {
+		t.Errorf("Files(Work, %s) = %v, %v", SessionsDir, files, err)
+	}
+	if files, err := v.Files(w, ArchiveDir); err != nil || files != nil {
+		t.Errorf("Files of a missing folder = %v, %v", files, err)
+	}
+}
+
+func TestLoadWithoutDomains(t *testing.T) {
+	v, err := Load(tree(t, map[string]string{"inbox/a.md": ""}))
+	if err != nil || len(v.Domains) != 0 {
+		t.Errorf("Load = %+v, %v", v, err)
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Load of a missing directory did not fail")
+	}
+}
+
+func TestFindRoot(t *testing.T) {
+	root := tree(t, map[string]string{"Domains/Work/02_PAGES/a.md": ""})
+	got, err := FindRoot(filepath.Join(root, "Domains", "Work", "02_PAGES"))
+	if err != nil || got != root {
+		t.Errorf("FindRoot = %q, %v; want %q", got, err, root)
+	}
+	if _, err := FindRoot(t.TempDir()); err == nil {
+		t.Error("FindRoot outside a vault did not fail")
+	}
+}
+
+func TestParseNote(t *testing.T) {
+	tests := []struct {
+		name, in string
+		fields   map[string]string
+		body     string
+		line     int
+		title    string
+		err      bool
+	}{
+		{
+			name:   "frontmatter",
+			in:     "---\nname: API\ncreated: 2026-10-01\ntags: [a]\n---\n# Build the API\n",
+			fields: map[string]string{"name": "API", "created": "2026-10-01", "tags": "[a]", "missing": ""},
+			body:   "# Build the API\n",
+			line:   6,
+			title:  "Build the API",
+		},
+		{name: "no frontmatter", in: "text\n## Not a title\n", body: "text\n## Not a title\n", line: 1, title: "note"},
+		{name: "bad yaml", in: "---\nname: [x\n---\nbody\n", body: "body\n", line: 4, title: "note", err: true},
+	}
+	for _, tt := range tests {
+		n, err := ParseNote("Domains/Work/02_PAGES/note.md", []byte(tt.in))
+		if (err != nil) != tt.err || n == nil {
+			t.Errorf("%s: ParseNote = %v, %v", tt.name, n, err)
+			continue
+		}
+		if n.Body != tt.body || n.BodyLine != tt.line || n.Title() != tt.title || n.Stem() != "note" {
+			t.Errorf("%s: body %q at line %d, title %q", tt.name, n.Body, n.BodyLine, n.Title())
+		}
+		for k, want := range tt.fields {
+			if got := n.Field(k); got != want {
+				t.Errorf("%s: Field(%q) = %q, want %q", tt.name, k, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package wikilink
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+)
+
+// Resolver maps link targets to vault files with Obsidian's rules:
+//
+//   - matching ignores case, and a target without ".md" matches the note;
+//   - "./" and "../" targets are relative to the linking note's folder;
+//   - any other target matches a file whose vault path equals it or ends in
+//     "/"+target, so [[Note]] finds Note.md anywhere and [[Projects/Note]]
+//     narrows the search;
+//   - when several files match, the one in the linking note's folder wins,
+//     then the one with the shortest path. The result is flagged ambiguous so
+//     audits can ask for a longer link.
+type Resolver struct {
+	// byBase maps a lower-case base name (with extension) to its paths.
+	byBase map[string][]string
+}
+
+// NewResolver indexes the given vault-relative file paths. Include
+// attachments as well as notes so that embeds resolve.
+func NewResolver(paths []string) *Resolver {
+	r := &Resolver{byBase: map[string][]string{}}
+	for _, p := range paths {
+		p = path.Clean(p)
+		b := strings.ToLower(path.Base(p))
+		r.byBase[b] = append(r.byBase[b], p)
+	}
+	return r
+}
+
+// Result is the outcome of resolving one link.
+type Result struct {
+	// Path is the vault-relative file the link opens.
+	Path string
+	// Candidates lists every matching file when more than one matched;
+	// Path is the first of them.
+	Candidates []string
+}
+
+// Ambiguous reports whether the target matched more than one file.
+func (res Result) Ambiguous() bool { return len(res.Candidates) > 1 }
+
+// Lookup resolves l as written in the note at from. Same-note links resolve
+// to from itself. ok is false when no file matches.
+func (r *Resolver) Lookup(from string, l Link) (res Result, ok bool) {
+	if l.Target == "" {
+		return Result{Path: from}, true
+	}
+	cands := r.candidates(from, l.Target)
+	if len(cands) == 0 {
+		return Result{}, false
+	}
+	res.Path = cands[0]
+	if len(cands) > 1 {
+		res.Candidates = cands
+	}
+	return res, true
+}
+
+// Resolve returns the file target opens from the note at from. target may
+// carry an alias or heading ("Note#Heading|shown"). It satisfies
+// relations.Resolver.
+func (r *Resolver) Resolve(from, target string) (string, bool) {
+	res, ok := r.Lookup(from, Parse(target))
+	return res.Path, ok
+}
+
+func (r *Resolver) candidates(from, target string) []string {
+	target = strings.TrimPrefix(strings.ReplaceAll(target, `\`, "/"), "/")
+	dir := path.Dir(from)
+
+	names := []string{target}
+	if !strings.EqualFold(path.Ext(target), ".md") {
+		names = append(names, target+".md")
+	}
+
+	if strings.HasPrefix(target, "./") || strings.HasPrefix(target, "../") {
+		for _, n := range names {
+			if p, ok := r.exact(path.Join(dir, n)); ok {
+				return []string{p}
+			}
+		}
+		return nil
+	}
+
+	var out []string
+	for _, n := range names {
+		want := strings.ToLower(path.Clean(n))
+		for _, p := range r.byBase[path.Base(want)] {
+			lp := strings.ToLower(p)
+			if lp == want || strings.HasSuffix(lp, "/"+want) {
+				out = append(out, p)
+			}
+		}
+		if len(out) > 0 {
+			break // an exact file name beats an implied ".md"
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if ad, bd := path.Dir(a) == dir, path.Dir(b) == dir; ad != bd {
+			return ad
+		}
+		if na, nb := strings.Count(a, "/"), strings.Count(b, "/"); na != nb {
+			return na < nb
+		}
+		return a < b
+	})
+	return out
+}
+
+func (r *Resolver) exact(p string) (string, bool) {
+	for _, c := range r.byBase[strings.ToLower(path.Base(p))] {
+		if strings.EqualFold(c, p) {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// Linktext returns the shortest target that opens p from anywhere in the
+// vault, the way Obsidian writes new links: the bare note name when it is
+// unique, otherwise the full vault path. The ".md" extension is dropped.
+func (r *Resolver) Linktext(p string) string {
+	name := path.Base(p)
+	full := p
+	if strings.EqualFold(path.Ext(p), ".md") {
+		name = strings.TrimSuffix(name, path.Ext(name))
+		full = strings.TrimSuffix(full, path.Ext(full))
+	}
+	if cands := r.candidates("", name); len(cands) == 1 && cands[0] == p {
+		return name
+	}
+	return full
+}
+
+// HasHeading reports whether text contains heading, which may be nested
+// ("Parent#Child": each heading must follow the previous one). Headings
+// compare the way Obsidian compares them: case-insensitively and ignoring
+// the characters that cannot appear in a link.
+func HasHeading(text, heading string) bool {
+	parts := strings.Split(heading, "#")
+	next := 0
+	for _, h := range markdown.Headings(text) {
+		if next < len(parts) && normHeading(h.Text) == normHeading(parts[next]) {
+			next++
+		}
+	}
+	return next == len(parts)
+}
+
+var linkUnsafe = strings.NewReplacer("#", " ", "^", " ", "[", " ", "]", " ", "|", " ", ":", " ", `\`, " ")
+
+func normHeading(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(linkUnsafe.Replace(s)), " "))
+}
+
+var blockID = regexp.MustCompile(`(?:^|\s)\^([A-Za-z0-9-]+)\s*$`)
+
+// HasBlock reports whether text defines the block id, written as "^id" at
+// the end of a line or on a line of its own. Obsidian matches block ids
+// case-insensitively.
+func HasBlock(text, id string) bool {
+	for k := range BlockIDs(text) {
+		if strings.EqualFold(k, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// BlockIDs returns every block id defined in text, keyed by id with the
+// 1-based line that defines it.
+func BlockIDs(text string) map[string]int {
+	lines := markdown.Lines(text)
+	fenced := markdown.InFence(lines)
+	ids := map[string]int{}
+	for i, line := range lines {
+		if fenced[i] {
+			continue
+		}
+		if m := blockID.FindStringSubmatch(line); m != nil {
+			if _, seen := ids[m[1]]; !seen {
+				ids[m[1]] = i + 1
+			}
+		}
+	}
+	return ids
+}
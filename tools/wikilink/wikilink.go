@@ -0,0 +1,111 @@
+// Package wikilink parses Obsidian [[wikilinks]] and resolves them the way
+// Obsidian does, so every link-aware PAL tool agrees with what the user sees
+// when they click a link.
+//
+// Supported forms:
+//
+//	[[Note]]                 a note by name
+//	[[Folder/Note]]          a note by (partial) path
+//	[[Note|shown text]]      alias
+//	[[Note#Heading]]         heading, nested as [[Note#H1#H2]]
+//	[[Note#^block-id]]       block reference
+//	[[#Heading]]             heading in the same note
+//	![[image.png]]           embed
+package wikilink
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/superuser-pal/PAL_Second_Brain/tools/internal/markdown"
+)
+
+// Link is one parsed wikilink.
+type Link struct {
+	// Target is the note or file part, e.g. "Projects/Launch Plan". It is
+	// empty for same-note links like [[#Heading]].
+	Target string
+	// Heading is the part after '#' for heading links; nested headings keep
+	// their '#' separators ("H1#H2").
+	Heading string
+	// Block is the id after "#^" for block references.
+	Block string
+	Alias string
+	Embed bool
+
+	// Raw is the link as written, brackets included. Line and Col are
+	// 1-based; Col counts bytes.
+	Raw  string
+	Line int
+	Col  int
+}
+
+// Parse splits the text between the brackets of a wikilink.
+func Parse(inner string) Link {
+	inner = strings.ReplaceAll(inner, `\|`, "|") // escaped inside tables
+	var l Link
+	if i := strings.IndexByte(inner, '|'); i >= 0 {
+		l.Alias = strings.TrimSpace(inner[i+1:])
+		inner = inner[:i]
+	}
+	if i := strings.IndexByte(inner, '#'); i >= 0 {
+		frag := inner[i+1:]
+		inner = inner[:i]
+		if strings.HasPrefix(frag, "^") {
+			l.Block = strings.TrimSpace(frag[1:])
+		} else {
+			l.Heading = strings.TrimSpace(frag)
+		}
+	}
+	l.Target = strings.TrimSpace(inner)
+	return l
+}
+
+// String renders l in [[Target#Heading|Alias]] form.
+func (l Link) String() string {
+	var b strings.Builder
+	if l.Embed {
+		b.WriteByte('!')
+	}
+	b.WriteString("[[")
+	b.WriteString(l.Target)
+	switch {
+	case l.Block != "":
+		b.WriteString("#^" + l.Block)
+	case l.Heading != "":
+		b.WriteString("#" + l.Heading)
+	}
+	if l.Alias != "" {
+		b.WriteString("|" + l.Alias)
+	}
+	b.WriteString("]]")
+	return b.String()
+}
+
+var linkRe = regexp.MustCompile(`(!?)\[\[([^\[\]\n]+?)\]\]`)
+
+// Find returns every wikilink in text, skipping fenced code blocks and
+// inline code spans, where Obsidian does not render links either.
+func Find(text string) []Link {
+	lines := markdown.Lines(text)
+	fenced := markdown.InFence(lines)
+	var out []Link
+	for i, line := range lines {
+		if fenced[i] {
+			continue
+		}
+		code := markdown.CodeSpans(line)
+		for _, m := range linkRe.FindAllStringSubmatchIndex(line, -1) {
+			if markdown.InSpans(code, m[0]) {
+				continue
+			}
+			l := Parse(line[m[4]:m[5]])
+			l.Embed = m[3] > m[2]
+			l.Raw = line[m[0]:m[1]]
+			l.Line = i + 1
+			l.Col = m[0] + 1
+			out = append(out, l)
+		}
+	}
+	return out
+}
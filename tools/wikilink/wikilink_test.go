@@ -0,0 +1,132 @@
+package wikilink
+
+import (
+	"reflect"
+	"testing"
+)
+
+var vaultFiles = []string{
+	"Home.md",
+	"Domains/Work/INDEX.md",
+	"Domains/Work/01_PROJECTS/PROJECT_API.md",
+	"Domains/Studio/INDEX.md",
+	"Domains/Studio/01_PROJECTS/PROJECT_API.md",
+	"Domains/Studio/Notes/Brand Notes.md",
+	"Domains/Studio/Notes/logo.png",
+	"Domains/Studio/Notes/logo.png.md",
+	"Archive/Deep/Old/Home.md",
+}
+
+func TestCandidates(t *testing.T) {
+	r := NewResolver(vaultFiles)
+	tests := []struct {
+		from, target string
+		want         []string
+	}{
+		{"Home.md", "Brand Notes", []string{"Domains/Studio/Notes/Brand Notes.md"}},
+		{"Home.md", "brand notes.MD", []string{"Domains/Studio/Notes/Brand Notes.md"}},
+		{"Home.md", "Missing", nil},
+		// The shortest path wins, then the linking note's folder.
+		{"Domains/Work/INDEX.md", "Home", []string{"Home.md", "Archive/Deep/Old/Home.md"}},
+		{"Archive/Deep/Old/x.md", "Home", []string{"Archive/Deep/Old/Home.md", "Home.md"}},
+		{"Domains/Work/INDEX.md", "INDEX", []string{"Domains/Work/INDEX.md", "Domains/Studio/INDEX.md"}},
+		// A partial path narrows the search.
+		{"Home.md", "Work/01_PROJECTS/PROJECT_API", []string{"Domains/Work/01_PROJECTS/PROJECT_API.md"}},
+		{"Home.md", "Studio/INDEX", []string{"Domains/Studio/INDEX.md"}},
+		{"Home.md", "/Domains/Work/INDEX", []string{"Domains/Work/INDEX.md"}},
+		{"Home.md", `Domains\Work\INDEX`, []string{"Domains/Work/INDEX.md"}},
+		// Relative targets only look next to the linking note.
+		{"Domains/Studio/INDEX.md", "./Notes/Brand Notes", []string{"Domains/Studio/Notes/Brand Notes.md"}},
+		{"Domains/Studio/Notes/Brand Notes.md", "../INDEX", []string{"Domains/Studio/INDEX.md"}},
+		{"Domains/Work/INDEX.md", "./Notes/Brand Notes", nil},
+		// An exact file name beats an implied ".md".
+		{"Home.md", "logo.png", []string{"Domains/Studio/Notes/logo.png"}},
+	}
+	for _, tt := range tests {
+		got := r.candidates(tt.from, tt.target)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("candidates(%q, %q) = %q, want %q", tt.from, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	r := NewResolver(vaultFiles)
+	res, ok := r.Lookup("Home.md", Parse("INDEX#Active Work|work"))
+	if !ok || res.Path != "Domains/Studio/INDEX.md" || !res.Ambiguous() {
+		t.Errorf("Lookup(INDEX) = %+v, %v; want the Studio index, ambiguous", res, ok)
+	}
+	res, ok = r.Lookup("Home.md", Parse("#Heading"))
+	if !ok || res.Path != "Home.md" || res.Ambiguous() {
+		t.Errorf("same-note Lookup = %+v, %v", res, ok)
+	}
+	if _, ok := r.Resolve("Home.md", "Nowhere|alias"); ok {
+		t.Error("Resolve found a missing note")
+	}
+}
+
+func TestLinktext(t *testing.T) {
+	r := NewResolver(vaultFiles)
+	tests := map[string]string{
+		"Domains/Studio/Notes/Brand Notes.md":     "Brand Notes",
+		"Domains/Work/01_PROJECTS/PROJECT_API.md": "Domains/Work/01_PROJECTS/PROJECT_API",
+		"Home.md":                                   "Home",
+		"Archive/Deep/Old/Home.md":                  "Archive/Deep/Old/Home",
+		"Domains/Studio/Notes/logo.png":             "logo.png",
+		"Domains/Studio/01_PROJECTS/PROJECT_API.md": "Domains/Studio/01_PROJECTS/PROJECT_API",
+	}
+	for p, want := range tests {
+		if got := r.Linktext(p); got != want {
+			t.Errorf("Linktext(%q) = %q, want %q", p, got, want)
+		}
+	}
+}
+
+func TestParseAndString(t *testing.T) {
+	tests := []struct {
+		inner string
+		want  Link
+		out   string
+	}{
+		{"Note", Link{Target: "Note"}, "[[Note]]"},
+		{"Folder/Note|shown", Link{Target: "Folder/Note", Alias: "shown"}, "[[Folder/Note|shown]]"},
+		{"Note#H1#H2", Link{Target: "Note", Heading: "H1#H2"}, "[[Note#H1#H2]]"},
+		{"Note#^block-1", Link{Target: "Note", Block: "block-1"}, "[[Note#^block-1]]"},
+		{"#Heading", Link{Heading: "Heading"}, "[[#Heading]]"},
+		{`Note\|in a table`, Link{Target: "Note", Alias: "in a table"}, "[[Note|in a table]]"},
+	}
+	for _, tt := range tests {
+		got := Parse(tt.inner)
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.inner, got, tt.want)
+		}
+		if s := got.String(); s != tt.out {
+			t.Errorf("String() of %q = %q, want %q", tt.inner, s, tt.out)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	text := "See [[A]] and ![[b.png]].\n`[[not a link]]`\n```\n[[fenced]]\n```\nLast [[C|c]]\n"
+	var got []string
+	for _, l := range Find(text) {
+		got = append(got, l.Raw)
+	}
+	want := []string{"[[A]]", "![[b.png]]", "[[C|c]]"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Find = %q, want %q", got, want)
+	}
+}
+
+func TestHasHeadingAndBlock(t *testing.T) {
+	text := "# Top\n## Sub: part\ntext ^abc-1\n```\nline ^fenced\n```\n"
+	if !HasHeading(text, "top#sub part") {
+		t.Error("HasHeading missed a nested heading")
+	}
+	if HasHeading(text, "Sub part#Top") {
+		t.Error("HasHeading accepted headings out of order")
+	}
+	if !HasBlock(text, "ABC-1") || HasBlock(text, "fenced") {
+		t.Error("HasBlock")
+	}
+}